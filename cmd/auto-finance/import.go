@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"auto-finance/internal/importer"
+	"auto-finance/internal/service/idempotency"
+	"auto-finance/internal/service/message"
+	idempotencyStorage "auto-finance/internal/storage/idempotency"
+
+	"github.com/rs/zerolog"
+)
+
+// importTTL keeps import keys for good; an export can be re-imported years
+// later and must still be recognised.
+const importTTL = 100 * 365 * 24 * time.Hour
+
+// runImport backfills historical SMS from phone exports.
+func runImport(ctx context.Context, logger zerolog.Logger, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: auto-finance import [flags] file ...")
+		fmt.Fprintln(fs.Output(), "\nImports SMS Backup & Restore XML exports (.xml) or CSV files (.csv).")
+		fs.PrintDefaults()
+	}
+	var source configSource
+	source.registerFlags(fs)
+	senders := fs.String("senders", "", "comma separated sender IDs to import (default: every [sender_routes] sender, or all when no routes are configured)")
+	state := fs.String("state", filepath.Join("data", "import-state.json"), "file remembering imported messages so re-runs skip them")
+	format := fs.String("format", "", `input format "xml" or "csv" (default: from the file extension)`)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("no input files")
+	}
+
+	c, err := setup(ctx, logger, source)
+	if err != nil {
+		return err
+	}
+
+	var messages []importer.Message
+	for _, path := range fs.Args() {
+		m, err := readImportFile(path, *format, c.location)
+		if err != nil {
+			return err
+		}
+		logger.Info().Str("file", path).Int("messages", len(m)).Msg("Read export")
+		messages = append(messages, m...)
+	}
+
+	var senderFilter []string
+	if *senders != "" {
+		senderFilter = strings.Split(*senders, ",")
+	} else {
+		for sender := range senderRoutes(c.config) {
+			senderFilter = append(senderFilter, sender)
+		}
+	}
+
+	imp := importer.New(&importer.Config{
+		Logger: logger,
+		Service: idempotency.New(&idempotency.Config{
+			Logger:  logger,
+			Next:    c.baseService,
+			Storage: idempotencyStorage.NewFile(*state),
+			TTL:     importTTL,
+		}),
+		Senders: senderFilter,
+		OnFailure: func(ctx context.Context, msg message.Message, err error) {
+			if c.deadLetters == nil {
+				return
+			}
+			if dlErr := c.deadLetters.Capture(ctx, msg, err); dlErr != nil {
+				logger.Error().Err(dlErr).Str("message_id", msg.ID.String()).Msg("Failed to dead letter message")
+			}
+		},
+	})
+
+	report, err := imp.Run(ctx, messages)
+	logger.Info().
+		Int("total", report.Total).
+		Int("skipped", report.Skipped).
+		Int("imported", report.Imported).
+		Int("duplicates", report.Duplicates).
+		Int("failed", report.Failed).
+		Msg("Import finished")
+	if err != nil {
+		return err
+	}
+
+	if report.Failed > 0 {
+		return fmt.Errorf("%d of %d messages failed to import", report.Failed, report.Total-report.Skipped)
+	}
+
+	return nil
+}
+
+func readImportFile(path, format string, loc *time.Location) ([]importer.Message, error) {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var messages []importer.Message
+	switch format {
+	case "xml":
+		messages, err = importer.ReadBackupXML(f, loc)
+	case "csv":
+		messages, err = importer.ReadCSV(f, loc)
+	default:
+		return nil, fmt.Errorf("%s: unknown import format %q", path, format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return messages, nil
+}
@@ -4,32 +4,33 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"time"
+	_ "time/tzdata" // the Lambda runtime ships without a zoneinfo database
 
 	autofinance "auto-finance/internal/app/auto-finance"
-	appConfig "auto-finance/internal/config"
 	"auto-finance/internal/logger"
-	parameterstore "auto-finance/internal/parameter-store"
-	"auto-finance/internal/service/ebill"
-	"auto-finance/internal/service/finance"
-	"auto-finance/internal/service/message"
-	"auto-finance/internal/smsparser"
-	"auto-finance/internal/smsparser/banking/sampath"
-	"auto-finance/internal/smsparser/bill/leco"
-	ebillStorage "auto-finance/internal/storage/ebill"
-	financeStorage "auto-finance/internal/storage/finance"
-	"auto-finance/internal/utils/retry"
 
 	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/ssm"
-	"google.golang.org/api/option"
-	"google.golang.org/api/sheets/v4"
+	"github.com/rs/zerolog"
 )
 
 var version = "local"
 
+const usage = `usage: auto-finance [command]
+
+Commands:
+  lambda    run the AWS Lambda handler (default)
+  serve     serve POST /finance over plain HTTP
+  replay    re-run dead lettered messages through the message service
+  parse     dry-run SMS bodies through the parsers without storing anything
+  import    backfill SMS Backup & Restore XML or CSV exports
+  report    print reports such as balance discrepancies
+  subscriptions
+            detect recurring charges and alert on new, changed or missed ones
+  solar     summarize rooftop solar exports, income and payback from LECO bills
+
+Run "auto-finance <command> -h" for command flags.
+`
+
 func main() {
 	ctx := context.Background()
 
@@ -38,126 +39,74 @@ func main() {
 		panic(err)
 	}
 
-	logger.Info().Msg("Initializing Auto Finance Lambda function")
-	defer logger.Info().Msg("Auto Finance Lambda function initialization complete")
-
-	awsConfig, err := config.LoadDefaultConfig(ctx)
-	if err != nil {
-		panic(fmt.Errorf("failed to load AWS config: %w", err))
+	command := "lambda"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
 	}
 
-	// TODO after observing pricing can remove s3
-
-	// configStore := configStorage.New(&configStorage.Config{
-	// 	Client: s3.NewFromConfig(awsConfig),
-	// 	Bucket: os.Getenv("CONFIGURATION_BUCKET"),
-	// 	RetryConfig: &retry.AWSRetryConfig{
-	// 		MaxAttempts:    3,
-	// 		InitialBackoff: 1 * time.Second,
-	// 		MaxBackoff:     5 * time.Second,
-	// 	},
-	// })
-
-	// appConfig, err := appConfig.LoadConfig(configStore)
-	// if err != nil {
-	// 	logger.Err(err).Msg("Failed to load application config")
-	// 	os.Exit(1)
-	// }
-
-	params, err := loadParameters(ctx, awsConfig)
-	if err != nil {
-		logger.Err(err).Msg("Failed to load parameters from Parameter Store")
-		os.Exit(1)
+	switch command {
+	case "lambda":
+		runLambda(ctx, logger)
+	case "serve":
+		if err := runServe(ctx, logger, os.Args[2:]); err != nil {
+			logger.Err(err).Msg("Server failed")
+			os.Exit(1)
+		}
+	case "replay":
+		if err := runReplay(ctx, logger, os.Args[2:]); err != nil {
+			logger.Err(err).Msg("Replay failed")
+			os.Exit(1)
+		}
+	case "import":
+		if err := runImport(ctx, logger, os.Args[2:]); err != nil {
+			logger.Err(err).Msg("Import failed")
+			os.Exit(1)
+		}
+	case "report":
+		if err := runReport(ctx, logger, os.Args[2:], os.Stdout); err != nil {
+			logger.Err(err).Msg("Report failed")
+			os.Exit(1)
+		}
+	case "subscriptions":
+		if err := runSubscriptions(ctx, logger, os.Args[2:], os.Stdout); err != nil {
+			logger.Err(err).Msg("Subscription detection failed")
+			os.Exit(1)
+		}
+	case "solar":
+		if err := runSolar(ctx, logger, os.Args[2:], os.Stdout); err != nil {
+			logger.Err(err).Msg("Solar analysis failed")
+			os.Exit(1)
+		}
+	case "parse":
+		if err := runParse(os.Args[2:], os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", command, usage)
+		os.Exit(2)
 	}
+}
 
-	srv, err := sheets.NewService(ctx, option.WithScopes(sheets.SpreadsheetsScope), option.WithCredentialsJSON([]byte(params.sheetKeys)))
-	if err != nil {
-		logger.Err(err).Msg("Failed to create Sheets service")
-		os.Exit(1)
-	}
+func runLambda(ctx context.Context, logger zerolog.Logger) {
+	logger.Info().Msg("Initializing Auto Finance Lambda function")
+	defer logger.Info().Msg("Auto Finance Lambda function initialization complete")
 
-	appConfig, err := appConfig.LoadConfigFromTomlBody([]byte(params.appConfig))
+	c, err := setup(ctx, logger, configSource{})
 	if err != nil {
-		logger.Err(err).Msg("Failed to load application config")
+		logger.Err(err).Msg("Failed to initialize application")
 		os.Exit(1)
 	}
 
-	msgSvc := message.New(&message.Config{
-		Logger: logger,
-		Parsers: []smsparser.UniversalParser{
-			smsparser.NewGenericParserWrapper(leco.New()),
-			smsparser.NewGenericParserWrapper(sampath.New()),
-		},
-		LecoBillService: ebill.NewLECOBillService(&ebill.Config{
-			Logger: logger,
-			Storage: ebillStorage.New(&ebillStorage.Config{
-				Service:   srv,
-				SheetID:   appConfig.LecoSheetConfig.SheetID,
-				SheetName: appConfig.LecoSheetConfig.SheetName,
-				GoogleRetryConfig: &retry.GoogleRetryConfig{
-					MaxAttempts:    3,
-					InitialBackoff: 1 * time.Second,
-					MaxBackoff:     5 * time.Second,
-				},
-			}),
-		}),
-		SampathBankService: finance.NewSampathBillService(&finance.Config{
-			Logger: logger,
-			Storage: financeStorage.NewSampathStorage(&financeStorage.SampathConfig{
-				Service:   srv,
-				SheetID:   appConfig.FinanceSheetConfig.SheetID,
-				SheetName: appConfig.FinanceSheetConfig.SheetName,
-				GoogleRetryConfig: &retry.GoogleRetryConfig{
-					MaxAttempts:    3,
-					InitialBackoff: 1 * time.Second,
-					MaxBackoff:     5 * time.Second,
-				},
-			}),
-		}),
-	})
-
 	app := autofinance.New(&autofinance.Config{
-		Logger:         logger,
-		MessageService: msgSvc,
+		Logger:            logger,
+		MessageService:    c.messageService,
+		Location:          c.location,
+		RawMessageStorage: c.rawMessageStorage,
+		DeadLetters:       c.deadLetters,
 	})
 
 	lambda.Start(app.Handler)
 }
-
-type configParams struct {
-	sheetKeys string
-	appConfig string
-}
-
-func loadParameters(ctx context.Context, awsConfig aws.Config) (configParams, error) {
-	client := ssm.NewFromConfig(awsConfig)
-
-	// Use enhanced parameter store with retry capabilities
-	store := parameterstore.NewWithConfig(&parameterstore.Config{
-		Client: client,
-		RetryConfig: &retry.AWSRetryConfig{
-			MaxAttempts:    3,
-			InitialBackoff: 1 * time.Second,
-			MaxBackoff:     5 * time.Second,
-		},
-	})
-
-	sk := os.Getenv("SHEET_KEY")
-	ac := os.Getenv("APP_CONFIG")
-
-	// Get parameters from the store
-	sheetKey, err := store.GetParameter(ctx, sk)
-	if err != nil {
-		return configParams{}, fmt.Errorf("failed to get sheet key: %w", err)
-	}
-
-	appConfig, err := store.GetParameter(ctx, ac)
-	if err != nil {
-		return configParams{}, fmt.Errorf("failed to get app config: %w", err)
-	}
-
-	return configParams{
-		sheetKeys: sheetKey,
-		appConfig: appConfig,
-	}, nil
-}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	autofinance "auto-finance/internal/app/auto-finance"
+	appConfig "auto-finance/internal/config"
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/service/category"
+	"auto-finance/internal/smsparser"
+	"auto-finance/internal/smsparser/template"
+
+	"github.com/rs/zerolog"
+)
+
+// parseInput is one SMS read by the parse command.
+type parseInput struct {
+	Sender     string
+	Body       string
+	ReceivedAt time.Time
+}
+
+// parseOutput is the JSON representation of a dry run.
+type parseOutput struct {
+	Sender     string            `json:"sender,omitempty"`
+	Body       string            `json:"body"`
+	ReceivedAt time.Time         `json:"received_at"`
+	Matched    string            `json:"matched,omitempty"`
+	Result     interface{}       `json:"result,omitempty"`
+	Errors     map[string]string `json:"errors,omitempty"`
+}
+
+var errUnmatched = errors.New("one or more messages did not match any parser")
+
+// runParse runs SMS bodies through every parser and prints what each one
+// made of them. Nothing is stored.
+func runParse(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("parse", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: auto-finance parse [flags] [file ...]")
+		fmt.Fprintln(fs.Output(), "\nReads SMS bodies from the files, or stdin when none are given.")
+		fs.PrintDefaults()
+	}
+	body := fs.String("body", "", "parse this SMS body instead of reading input")
+	input := fs.String("input", "text", `input format: "text" (messages separated by blank lines) or "jsonl" (one request object per line)`)
+	format := fs.String("format", "json", `output format: "json" or "table"`)
+	receivedAt := fs.String("received-at", "", "received time for text input (RFC 3339, 2006-01-02 15:04:05 or epoch ms; default now)")
+	timezone := fs.String("timezone", "", "IANA zone for received times without an offset (default UTC)")
+	configPath := fs.String("config", "", "local TOML config whose parsers, [[templates]] and [categories] are used (default: every built-in parser)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	defaults := autofinance.Request{ReceivedAt: *receivedAt, Timezone: *timezone}
+
+	var messages []parseInput
+	var err error
+	switch {
+	case *body != "":
+		messages, err = readTextMessages(strings.NewReader(*body), defaults)
+	case fs.NArg() == 0:
+		messages, err = readParseInput(stdin, *input, defaults)
+	default:
+		for _, path := range fs.Args() {
+			f, openErr := os.Open(path)
+			if openErr != nil {
+				return openErr
+			}
+			m, readErr := readParseInput(f, *input, defaults)
+			f.Close()
+			if readErr != nil {
+				return fmt.Errorf("%s: %w", path, readErr)
+			}
+			messages = append(messages, m...)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	var enabled []string
+	var templates []template.Definition
+	var categorizer *category.Categorizer
+	if *configPath != "" {
+		data, readErr := os.ReadFile(*configPath)
+		if readErr != nil {
+			return readErr
+		}
+		cfg, cfgErr := appConfig.LoadConfigFromTomlBody(data)
+		if cfgErr != nil {
+			return fmt.Errorf("%s: %w", *configPath, cfgErr)
+		}
+		enabled, templates = cfg.Parsers, cfg.Templates
+		if categorizer, err = newCategorizer(zerolog.Nop(), cfg.Categories); err != nil {
+			return err
+		}
+	}
+
+	registry, err := newRegistry(enabled, templates, handlers{})
+	if err != nil {
+		return err
+	}
+	parsers := registry.Parsers()
+
+	outputs := make([]parseOutput, 0, len(messages))
+	unmatched := false
+	for _, msg := range messages {
+		eval := smsparser.Evaluate(parsers, msg.Body, msg.ReceivedAt)
+
+		out := parseOutput{Sender: msg.Sender, Body: msg.Body, ReceivedAt: msg.ReceivedAt}
+		if matched, ok := eval.Matched(); ok {
+			out.Matched = matched.Parser
+			out.Result = matched.Result
+			if txn, ok := matched.Result.(*finance.Transaction); ok && categorizer != nil {
+				if err := categorizer.Enrich(context.Background(), txn); err != nil {
+					return err
+				}
+			}
+		} else {
+			unmatched = true
+		}
+		for _, a := range eval.Attempts {
+			if a.Err == nil {
+				continue
+			}
+			if out.Errors == nil {
+				out.Errors = make(map[string]string)
+			}
+			out.Errors[a.Parser] = a.Err.Error()
+		}
+		outputs = append(outputs, out)
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		for _, out := range outputs {
+			if err := enc.Encode(out); err != nil {
+				return err
+			}
+		}
+	case "table":
+		if err := writeParseTable(stdout, outputs); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown output format %q", *format)
+	}
+
+	if unmatched {
+		return errUnmatched
+	}
+	return nil
+}
+
+func readParseInput(r io.Reader, format string, defaults autofinance.Request) ([]parseInput, error) {
+	switch format {
+	case "text":
+		return readTextMessages(r, defaults)
+	case "jsonl":
+		return readJSONLMessages(r, defaults)
+	default:
+		return nil, fmt.Errorf("unknown input format %q", format)
+	}
+}
+
+// readTextMessages splits r into messages on blank lines, so multi-line SMS
+// bodies can be pasted as they are.
+func readTextMessages(r io.Reader, defaults autofinance.Request) ([]parseInput, error) {
+	receivedAt, err := defaults.ReceivedTime(nil, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []parseInput
+	var lines []string
+	flush := func() {
+		if len(lines) > 0 {
+			messages = append(messages, parseInput{Body: strings.Join(lines, "\n"), ReceivedAt: receivedAt})
+			lines = nil
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		lines = append(lines, line)
+	}
+	flush()
+
+	return messages, scanner.Err()
+}
+
+// readJSONLMessages reads one API request object per line.
+func readJSONLMessages(r io.Reader, defaults autofinance.Request) ([]parseInput, error) {
+	var messages []parseInput
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var req autofinance.Request
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		if req.ReceivedAt == "" {
+			req.ReceivedAt = defaults.ReceivedAt
+		}
+		if req.Timezone == "" {
+			req.Timezone = defaults.Timezone
+		}
+
+		receivedAt, err := req.ReceivedTime(nil, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		messages = append(messages, parseInput{Sender: req.Sender, Body: req.Body, ReceivedAt: receivedAt})
+	}
+
+	return messages, scanner.Err()
+}
+
+func writeParseTable(w io.Writer, outputs []parseOutput) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tSENDER\tMATCHED\tRESULT")
+
+	for i, out := range outputs {
+		matched := out.Matched
+		result := ""
+		if matched == "" {
+			matched = "-"
+		} else {
+			data, err := json.Marshal(out.Result)
+			if err != nil {
+				return err
+			}
+			result = string(data)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", i+1, out.Sender, matched, result)
+
+		parsers := make([]string, 0, len(out.Errors))
+		for parser := range out.Errors {
+			parsers = append(parsers, parser)
+		}
+		sort.Strings(parsers)
+		for _, parser := range parsers {
+			fmt.Fprintf(tw, "\t\t%s\t%s\n", parser, out.Errors[parser])
+		}
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/rs/zerolog"
+)
+
+// runReplay passes every dead lettered message through the message service
+// again, typically after a parser or storage fix has been deployed.
+func runReplay(ctx context.Context, logger zerolog.Logger, args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	var source configSource
+	source.registerFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	c, err := setup(ctx, logger, source)
+	if err != nil {
+		return err
+	}
+
+	if c.deadLetters == nil {
+		return fmt.Errorf("no dead letter backend configured")
+	}
+
+	report, err := c.deadLetters.Replay(ctx, c.messageService)
+	logger.Info().
+		Int("total", report.Total).
+		Int("replayed", report.Replayed).
+		Int("duplicates", report.Duplicates).
+		Int("failed", report.Failed).
+		Msg("Replay finished")
+	if err != nil {
+		return err
+	}
+
+	if report.Failed > 0 {
+		return fmt.Errorf("%d of %d messages are still failing", report.Failed, report.Total)
+	}
+
+	return nil
+}
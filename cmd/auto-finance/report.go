@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"auto-finance/internal/models/finance"
+
+	"github.com/rs/zerolog"
+)
+
+// runReport prints a report built from the stored data.
+func runReport(ctx context.Context, logger zerolog.Logger, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: auto-finance report [flags] report")
+		fmt.Fprintln(fs.Output(), "\nReports:\n  discrepancies  balance discrepancies found by [reconcile]")
+		fs.PrintDefaults()
+	}
+	var source configSource
+	source.registerFlags(fs)
+	since := fs.String("since", "", "only include entries on or after this date (2006-01-02)")
+	account := fs.String("account", "", "only include this account or card")
+	format := fs.String("format", "table", `output format: "table" or "json"`)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected one report name")
+	}
+
+	c, err := setup(ctx, logger, source)
+	if err != nil {
+		return err
+	}
+
+	var from time.Time
+	if *since != "" {
+		from, err = time.ParseInLocation(time.DateOnly, *since, c.location)
+		if err != nil {
+			return fmt.Errorf("invalid -since: %w", err)
+		}
+	}
+
+	switch fs.Arg(0) {
+	case "discrepancies":
+		if c.discrepancies == nil {
+			return fmt.Errorf("no [reconcile] discrepancy_sheet configured")
+		}
+		all, err := c.discrepancies.List(ctx)
+		if err != nil {
+			return err
+		}
+
+		var selected []*finance.Discrepancy
+		for _, d := range all {
+			if d.OccurredAt.Before(from) || (*account != "" && d.Account != *account) {
+				continue
+			}
+			selected = append(selected, d)
+		}
+		return writeDiscrepancies(stdout, selected, *format, c.location)
+	default:
+		return fmt.Errorf("unknown report %q", fs.Arg(0))
+	}
+}
+
+func writeDiscrepancies(w io.Writer, discrepancies []*finance.Discrepancy, format string, location *time.Location) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if discrepancies == nil {
+			discrepancies = []*finance.Discrepancy{}
+		}
+		return enc.Encode(discrepancies)
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "TIME\tINSTITUTION\tACCOUNT\tEXPECTED\tREPORTED\tDIFFERENCE\tSINCE")
+		for _, d := range discrepancies {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				d.OccurredAt.In(location).Format(time.DateTime),
+				d.Institution,
+				d.Account,
+				d.Expected,
+				d.Reported,
+				d.Difference,
+				d.CheckpointAt.In(location).Format(time.DateTime),
+			)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	autofinance "auto-finance/internal/app/auto-finance"
+
+	"github.com/rs/zerolog"
+)
+
+const defaultServeAddr = ":8080"
+
+// runServe serves the Lambda handler over plain HTTP until interrupted.
+func runServe(ctx context.Context, logger zerolog.Logger, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	var source configSource
+	source.registerFlags(fs)
+	addr := fs.String("addr", "", "listen address (default: [server] addr, then "+defaultServeAddr+")")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	c, err := setup(ctx, logger, source)
+	if err != nil {
+		return err
+	}
+
+	listenAddr := *addr
+	if listenAddr == "" {
+		listenAddr = c.config.Server.Addr
+	}
+	if listenAddr == "" {
+		listenAddr = defaultServeAddr
+	}
+
+	apiKey := c.config.Server.APIKey
+	if v := os.Getenv("AUTO_FINANCE_API_KEY"); v != "" {
+		apiKey = v
+	}
+	if apiKey == "" {
+		logger.Warn().Msg("No API key configured, accepting unauthenticated requests")
+	}
+
+	app := autofinance.New(&autofinance.Config{
+		Logger:            logger,
+		MessageService:    c.messageService,
+		Location:          c.location,
+		RawMessageStorage: c.rawMessageStorage,
+		DeadLetters:       c.deadLetters,
+	})
+
+	server := &http.Server{
+		Addr:              listenAddr,
+		Handler:           app.HTTPHandler(apiKey),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		logger.Info().Str("addr", listenAddr).Msg("Listening")
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	logger.Info().Msg("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,899 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	appConfig "auto-finance/internal/config"
+	"auto-finance/internal/events"
+	"auto-finance/internal/models"
+	ebillModel "auto-finance/internal/models/ebill"
+	financeModel "auto-finance/internal/models/finance"
+	waterbillModel "auto-finance/internal/models/waterbill"
+	"auto-finance/internal/notify"
+	parameterstore "auto-finance/internal/parameter-store"
+	"auto-finance/internal/service/budget"
+	"auto-finance/internal/service/category"
+	"auto-finance/internal/service/deadletter"
+	"auto-finance/internal/service/ebill"
+	"auto-finance/internal/service/finance"
+	"auto-finance/internal/service/fx"
+	"auto-finance/internal/service/idempotency"
+	"auto-finance/internal/service/message"
+	"auto-finance/internal/service/reconcile"
+	"auto-finance/internal/service/tariff"
+	"auto-finance/internal/service/waterbill"
+	"auto-finance/internal/smsparser"
+	"auto-finance/internal/smsparser/banking/hnb"
+	"auto-finance/internal/smsparser/banking/sampath"
+	"auto-finance/internal/smsparser/bill/ceb"
+	"auto-finance/internal/smsparser/bill/leco"
+	"auto-finance/internal/smsparser/bill/nwsdb"
+	"auto-finance/internal/smsparser/template"
+	"auto-finance/internal/storage"
+	balanceStorage "auto-finance/internal/storage/balance"
+	budgetStorage "auto-finance/internal/storage/budget"
+	deadletterStorage "auto-finance/internal/storage/deadletter"
+	ebillStorage "auto-finance/internal/storage/ebill"
+	financeStorage "auto-finance/internal/storage/finance"
+	"auto-finance/internal/storage/gsheet"
+	idempotencyStorage "auto-finance/internal/storage/idempotency"
+	"auto-finance/internal/storage/rawmessage"
+	waterbillStorage "auto-finance/internal/storage/waterbill"
+	"auto-finance/internal/utils/retry"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/rs/zerolog"
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+// configSource selects where the TOML configuration and Google credentials
+// are read from: local files when configPath is set, Parameter Store
+// otherwise.
+type configSource struct {
+	configPath      string
+	credentialsPath string
+}
+
+func (s *configSource) registerFlags(fs *flag.FlagSet) {
+	fs.StringVar(&s.configPath, "config", "", "path to a local TOML config file (default: read from Parameter Store)")
+	fs.StringVar(&s.credentialsPath, "credentials", "", "path to the Google service account JSON key (required with -config)")
+}
+
+func (s configSource) load(ctx context.Context, awsConfig aws.Config) (configParams, error) {
+	if s.configPath == "" {
+		return loadParameters(ctx, awsConfig)
+	}
+
+	if s.credentialsPath == "" {
+		return configParams{}, fmt.Errorf("-credentials is required with -config")
+	}
+
+	appConfig, err := os.ReadFile(s.configPath)
+	if err != nil {
+		return configParams{}, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	sheetKey, err := os.ReadFile(s.credentialsPath)
+	if err != nil {
+		return configParams{}, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	return configParams{
+		sheetKeys: string(sheetKey),
+		appConfig: string(appConfig),
+	}, nil
+}
+
+// components holds everything the commands share once configuration has
+// been loaded.
+type components struct {
+	messageService message.Service
+	// baseService is messageService without duplicate detection.
+	baseService       message.Service
+	location          *time.Location
+	rawMessageStorage storage.MessageStorage[*models.Message]
+	// deadLetters is nil when no dead letter backend is configured.
+	deadLetters *deadletter.Service
+	// events is where services publish alerts for subscribers to deliver.
+	events       *events.Bus
+	transactions storage.TransactionLedger
+	bills        storage.BillLedger
+	// solarSummary is nil when no solar summary sheet is configured.
+	solarSummary storage.SolarSummaryStorage
+	// discrepancies is nil when no discrepancy sheet is configured.
+	discrepancies storage.DiscrepancyStorage
+	config        *appConfig.Config
+}
+
+func setup(ctx context.Context, logger zerolog.Logger, source configSource) (*components, error) {
+	awsConfig, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	// TODO after observing pricing can remove s3
+
+	// configStore := configStorage.New(&configStorage.Config{
+	// 	Client: s3.NewFromConfig(awsConfig),
+	// 	Bucket: os.Getenv("CONFIGURATION_BUCKET"),
+	// 	RetryConfig: &retry.AWSRetryConfig{
+	// 		MaxAttempts:    3,
+	// 		InitialBackoff: 1 * time.Second,
+	// 		MaxBackoff:     5 * time.Second,
+	// 	},
+	// })
+
+	// appConfig, err := appConfig.LoadConfig(configStore)
+	// if err != nil {
+	// 	logger.Err(err).Msg("Failed to load application config")
+	// 	os.Exit(1)
+	// }
+
+	params, err := source.load(ctx, awsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	srv, err := sheets.NewService(ctx, option.WithScopes(sheets.SpreadsheetsScope), option.WithCredentialsJSON([]byte(params.sheetKeys)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Sheets service: %w", err)
+	}
+
+	cfg, err := appConfig.LoadConfigFromTomlBody([]byte(params.appConfig))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load application config: %w", err)
+	}
+
+	location := time.UTC
+	if cfg.Timezone != "" {
+		location, err = time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load configured timezone: %w", err)
+		}
+	}
+
+	bus := events.NewBus()
+	if len(cfg.Notify.Rules) > 0 {
+		dispatcher, err := newNotifyDispatcher(logger, cfg.Notify)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure notifications: %w", err)
+		}
+		bus.Subscribe(dispatcher.Handle)
+	}
+
+	transactions := financeStorage.NewTransactionStorage(&financeStorage.TransactionConfig{
+		Service:       srv,
+		Sheets:        transactionSheets(cfg),
+		DeclinedSheet: declinedSheet(cfg),
+		Location:      location,
+		GoogleRetryConfig: &retry.GoogleRetryConfig{
+			MaxAttempts:    3,
+			InitialBackoff: 1 * time.Second,
+			MaxBackoff:     5 * time.Second,
+		},
+	})
+	discrepancies := newDiscrepancyStorage(cfg.Reconcile, srv, location)
+	bills := newBillStorage(cfg, srv)
+	waterBills := newWaterBillStorage(cfg.NWSDBSheetConfig, srv)
+
+	baseSvc, err := newMessageService(logger, cfg, location, bus, transactions, bills, waterBills, discrepancies)
+	if err != nil {
+		return nil, err
+	}
+
+	msgSvc := baseSvc
+	if cfg.Idempotency.Backend != "" {
+		store, err := newIdempotencyStorage(cfg.Idempotency, awsConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create idempotency storage: %w", err)
+		}
+
+		msgSvc = idempotency.New(&idempotency.Config{
+			Logger:  logger,
+			Next:    baseSvc,
+			Storage: store,
+			Window:  cfg.Idempotency.Window,
+			TTL:     cfg.Idempotency.TTL,
+		})
+	}
+
+	rawMessageStorage, err := newRawMessageStorage(cfg.RawMessages, srv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create raw message storage: %w", err)
+	}
+
+	c := &components{
+		messageService:    msgSvc,
+		baseService:       baseSvc,
+		location:          location,
+		rawMessageStorage: rawMessageStorage,
+		events:            bus,
+		transactions:      transactions,
+		bills:             bills,
+		solarSummary:      newSolarSummaryStorage(cfg.Solar, srv),
+		discrepancies:     discrepancies,
+		config:            cfg,
+	}
+
+	if cfg.DeadLetter.Backend != "" {
+		store, err := newDeadLetterStorage(cfg.DeadLetter, awsConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create dead letter storage: %w", err)
+		}
+		c.deadLetters = deadletter.New(&deadletter.Config{
+			Logger:  logger,
+			Storage: store,
+		})
+	}
+
+	return c, nil
+}
+
+// handlers are the services the parser plugins hand their results to.
+type handlers struct {
+	bill        smsparser.Handler[*ebillModel.ElectricityBill]
+	waterBill   smsparser.Handler[*waterbillModel.WaterBill]
+	transaction smsparser.Handler[*financeModel.Transaction]
+}
+
+// pluginFactory registers one parser package with a registry.
+type pluginFactory struct {
+	name     string
+	register func(r *smsparser.Registry, h handlers) error
+}
+
+// plugins lists every parser package by name, in the default order they are
+// tried when a sender has no route.
+var plugins = []pluginFactory{
+	{leco.Name, func(r *smsparser.Registry, h handlers) error { return leco.Register(r, h.bill) }},
+	{ceb.Name, func(r *smsparser.Registry, h handlers) error { return ceb.Register(r, h.bill) }},
+	{nwsdb.Name, func(r *smsparser.Registry, h handlers) error { return nwsdb.Register(r, h.waterBill) }},
+	{sampath.Name, func(r *smsparser.Registry, h handlers) error { return sampath.Register(r, h.transaction) }},
+	{hnb.Name, func(r *smsparser.Registry, h handlers) error { return hnb.Register(r, h.transaction) }},
+}
+
+// enabledParsers returns the configured parsers, or every parser when none
+// are configured. Bills have nowhere to go without their sheet, so the
+// parsers in unavailable, which maps a parser to the sheet section it lacks,
+// are then left out by default and rejected when listed.
+func enabledParsers(configured []string, unavailable map[string]string) ([]string, error) {
+	for _, name := range configured {
+		if section, ok := unavailable[strings.ToLower(strings.TrimSpace(name))]; ok {
+			return nil, fmt.Errorf("parser %s requires %s", name, section)
+		}
+	}
+	if len(configured) > 0 {
+		return configured, nil
+	}
+
+	var enabled []string
+	for _, p := range plugins {
+		if _, ok := unavailable[p.name]; !ok {
+			enabled = append(enabled, p.name)
+		}
+	}
+	return enabled, nil
+}
+
+// newRegistry registers the enabled parsers in the given order, or every
+// parser when enabled is empty, followed by the configured templates.
+func newRegistry(enabled []string, templates []template.Definition, h handlers) (*smsparser.Registry, error) {
+	if len(enabled) == 0 {
+		for _, p := range plugins {
+			enabled = append(enabled, p.name)
+		}
+	}
+
+	registry := smsparser.NewRegistry()
+	for _, name := range enabled {
+		i := slices.IndexFunc(plugins, func(p pluginFactory) bool {
+			return strings.EqualFold(p.name, strings.TrimSpace(name))
+		})
+		if i < 0 {
+			return nil, fmt.Errorf("unknown parser %q", name)
+		}
+		if err := plugins[i].register(registry, h); err != nil {
+			return nil, err
+		}
+	}
+	for _, def := range templates {
+		if err := template.Register(registry, def, h.transaction); err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// senderRoutes adds the template senders to the configured routes, keyed by
+// normalized sender so that a configured route wins over a template sender
+// spelled differently. Without configured routes every parser is tried,
+// templates included, so nothing is added.
+func senderRoutes(cfg *appConfig.Config) map[string]string {
+	if len(cfg.SenderRoutes) == 0 {
+		return nil
+	}
+
+	routes := make(map[string]string, len(cfg.SenderRoutes))
+	for sender, name := range cfg.SenderRoutes {
+		routes[message.NormalizeSender(sender)] = name
+	}
+	for _, def := range cfg.Templates {
+		sender := message.NormalizeSender(def.Sender)
+		if sender == "" {
+			continue
+		}
+		if _, ok := routes[sender]; !ok {
+			routes[sender] = def.Name
+		}
+	}
+	return routes
+}
+
+func newMessageService(logger zerolog.Logger, cfg *appConfig.Config, location *time.Location, publisher events.Publisher, transactions storage.TransactionLedger, bills storage.BillLedger, waterBills storage.MessageStorage[*waterbillModel.WaterBill], discrepancies storage.DiscrepancyStorage) (message.Service, error) {
+	var verifier *tariff.Verifier
+	if len(cfg.Tariff.Tariffs) > 0 {
+		var err error
+		if verifier, err = newTariffVerifier(cfg.Tariff); err != nil {
+			return nil, err
+		}
+	}
+
+	billService := ebill.NewBillService(&ebill.Config{
+		Logger:    logger,
+		Publisher: publisher,
+		Verifier:  verifier,
+		Storage:   bills,
+	})
+	sheets := transactionSheets(cfg)
+	for _, def := range cfg.Templates {
+		if _, ok := sheets[def.Institution]; !ok {
+			return nil, fmt.Errorf("template %s: no transaction_sheets entry for institution %q", def.Name, def.Institution)
+		}
+	}
+
+	var enrichers []finance.Enricher
+	if cfg.FX.Base != "" {
+		converter, err := newConverter(logger, cfg.FX, location)
+		if err != nil {
+			return nil, err
+		}
+		enrichers = append(enrichers, converter)
+	}
+
+	categorizer, err := newCategorizer(logger, cfg.Categories)
+	if err != nil {
+		return nil, err
+	}
+	if categorizer != nil {
+		enrichers = append(enrichers, categorizer)
+	}
+
+	var hooks []finance.Hook
+	if cfg.Budget.Backend != "" {
+		budgetService, err := newBudgetService(logger, cfg.Budget, location, publisher)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, budgetService)
+	}
+	if cfg.Reconcile.Backend != "" {
+		reconcileService, err := newReconcileService(logger, cfg.Reconcile, publisher, discrepancies)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, reconcileService)
+	}
+
+	transactionService := finance.NewTransactionService(&finance.Config{
+		Logger:         logger,
+		Enrichers:      enrichers,
+		Hooks:          hooks,
+		Publisher:      publisher,
+		ReversalWindow: cfg.ReversalWindow,
+		Storage:        transactions,
+	})
+
+	h := handlers{
+		bill:        billService.HandleBill,
+		transaction: transactionService.HandleTransaction,
+	}
+	if waterBills != nil {
+		h.waterBill = waterbill.NewBillService(&waterbill.Config{
+			Logger:    logger,
+			Publisher: publisher,
+			Storage:   waterBills,
+		}).HandleBill
+	}
+
+	unavailable := make(map[string]string)
+	if cfg.CEBSheetConfig.SheetID == "" {
+		unavailable[ceb.Name] = "ceb_sheet_config"
+	}
+	if waterBills == nil {
+		unavailable[nwsdb.Name] = "nwsdb_sheet_config"
+	}
+	enabled, err := enabledParsers(cfg.Parsers, unavailable)
+	if err != nil {
+		return nil, err
+	}
+	registry, err := newRegistry(enabled, cfg.Templates, h)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register parsers: %w", err)
+	}
+
+	routes, err := message.NewRoutes(senderRoutes(cfg), registry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build sender routes: %w", err)
+	}
+
+	return message.New(&message.Config{
+		Logger:   logger,
+		Registry: registry,
+		Routes:   routes,
+	}), nil
+}
+
+// newCategorizer builds the categorizer from the inline rules followed by
+// the rules file. It returns nil when no rules are configured.
+func newCategorizer(logger zerolog.Logger, c appConfig.CategoryConfig) (*category.Categorizer, error) {
+	rules := category.Rules{Cities: c.Cities, Rules: c.Rules}
+	if c.RulesFile != "" {
+		data, err := os.ReadFile(c.RulesFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read category rules: %w", err)
+		}
+		fileRules, err := category.ParseRules(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse category rules %s: %w", c.RulesFile, err)
+		}
+		rules.Rules = append(rules.Rules, fileRules.Rules...)
+		if len(rules.Cities) == 0 {
+			rules.Cities = fileRules.Cities
+		}
+	}
+	if len(rules.Rules) == 0 {
+		return nil, nil
+	}
+
+	categorizer, err := category.New(&category.Config{Logger: logger, Rules: rules})
+	if err != nil {
+		return nil, fmt.Errorf("invalid category rules: %w", err)
+	}
+	return categorizer, nil
+}
+
+func newBudgetService(logger zerolog.Logger, c appConfig.BudgetConfig, location *time.Location, publisher events.Publisher) (*budget.Service, error) {
+	var store storage.BudgetStorage
+	switch c.Backend {
+	case "memory":
+		store = budgetStorage.NewMemory()
+	case "file":
+		if c.Path == "" {
+			return nil, fmt.Errorf("budget file backend requires a path")
+		}
+		store = budgetStorage.NewFile(c.Path)
+	default:
+		return nil, fmt.Errorf("unknown budget backend %q", c.Backend)
+	}
+
+	budgets := make([]budget.Budget, 0, len(c.Limits))
+	for i, l := range c.Limits {
+		currency := l.Currency
+		if currency == "" {
+			currency = "LKR"
+		}
+		limit, err := models.ParseMoney(l.Limit, currency)
+		if err != nil {
+			return nil, fmt.Errorf("budget limit %d: %w", i+1, err)
+		}
+
+		name := l.Name
+		if name == "" {
+			name = strings.Trim(l.Category+" "+l.Account, " ")
+		}
+		if name == "" {
+			name = "Total"
+		}
+
+		budgets = append(budgets, budget.Budget{
+			Name:     name,
+			Category: l.Category,
+			Account:  l.Account,
+			Limit:    limit,
+		})
+	}
+
+	return budget.New(&budget.Config{
+		Logger:     logger,
+		Budgets:    budgets,
+		Storage:    store,
+		Publisher:  publisher,
+		Thresholds: c.Thresholds,
+		Location:   location,
+	}), nil
+}
+
+func newTariffVerifier(c appConfig.TariffConfig) (*tariff.Verifier, error) {
+	// LECO bills are only issued in rupees.
+	const currency = "LKR"
+	amount := func(s string) (models.Money, error) {
+		if s == "" {
+			return models.NewMoney(0, currency), nil
+		}
+		return models.ParseMoney(s, currency)
+	}
+
+	tolerance, err := amount(c.Tolerance)
+	if err != nil {
+		return nil, fmt.Errorf("tariff tolerance: %w", err)
+	}
+
+	tariffs := make([]tariff.Tariff, 0, len(c.Tariffs))
+	for i, tc := range c.Tariffs {
+		name := tc.Name
+		if name == "" {
+			name = fmt.Sprintf("%d", i+1)
+		}
+		t := tariff.Tariff{Name: name, AccountTypes: tc.AccountTypes, Scheme: tariff.Scheme(tc.Scheme)}
+
+		if tc.SSCL != "" {
+			sscl, ok := new(big.Rat).SetString(strings.TrimSpace(tc.SSCL))
+			if !ok {
+				return nil, fmt.Errorf("tariff %s: invalid sscl %q", name, tc.SSCL)
+			}
+			t.SSCL = sscl
+		}
+		if tc.ExportRate != "" {
+			rate, err := models.ParseMoney(tc.ExportRate, currency)
+			if err != nil {
+				return nil, fmt.Errorf("tariff %s: export rate: %w", name, err)
+			}
+			t.ExportRate = rate
+		}
+
+		for j, sc := range tc.Schedules {
+			schedule := tariff.Schedule{MaxUnits: sc.MaxUnits}
+			for k, bc := range sc.Blocks {
+				rate, err := amount(bc.Rate)
+				if err != nil {
+					return nil, fmt.Errorf("tariff %s: schedule %d block %d rate: %w", name, j+1, k+1, err)
+				}
+				fixed, err := amount(bc.FixedCharge)
+				if err != nil {
+					return nil, fmt.Errorf("tariff %s: schedule %d block %d fixed charge: %w", name, j+1, k+1, err)
+				}
+				schedule.Blocks = append(schedule.Blocks, tariff.Block{UpTo: bc.UpTo, Rate: rate, FixedCharge: fixed})
+			}
+			t.Schedules = append(t.Schedules, schedule)
+		}
+
+		tariffs = append(tariffs, t)
+	}
+
+	return tariff.New(&tariff.Config{Tariffs: tariffs, Tolerance: tolerance})
+}
+
+func newNotifyDispatcher(logger zerolog.Logger, c appConfig.NotifyConfig) (*notify.Dispatcher, error) {
+	notifiers := make(map[string]notify.Notifier, len(c.Notifiers))
+	for name, n := range c.Notifiers {
+		switch n.Type {
+		case "webhook":
+			if n.URL == "" {
+				return nil, fmt.Errorf("notifier %s: url is required", name)
+			}
+			notifiers[name] = notify.NewWebhook(&notify.WebhookConfig{URL: n.URL, Headers: n.Headers})
+		case "telegram":
+			if n.Token == "" || n.ChatID == "" {
+				return nil, fmt.Errorf("notifier %s: token and chat_id are required", name)
+			}
+			notifiers[name] = notify.NewTelegram(&notify.TelegramConfig{Token: n.Token, ChatID: n.ChatID, BaseURL: n.BaseURL})
+		case "email":
+			if n.Addr == "" || n.From == "" || len(n.To) == 0 {
+				return nil, fmt.Errorf("notifier %s: addr, from and to are required", name)
+			}
+			notifiers[name] = notify.NewEmail(&notify.EmailConfig{
+				Addr:     n.Addr,
+				From:     n.From,
+				To:       n.To,
+				Username: n.Username,
+				Password: n.Password,
+			})
+		default:
+			return nil, fmt.Errorf("notifier %s: unknown type %q", name, n.Type)
+		}
+	}
+
+	rules := make([]notify.Rule, 0, len(c.Rules))
+	for i, r := range c.Rules {
+		rule := notify.Rule{
+			Event:       events.Type(r.Event),
+			Notifiers:   r.Notifiers,
+			Institution: r.Institution,
+			Account:     r.Account,
+			Channel:     r.Channel,
+			Direction:   r.Direction,
+			Status:      r.Status,
+		}
+		if r.MinAmount != "" {
+			currency := r.Currency
+			if currency == "" {
+				currency = "LKR"
+			}
+			min, err := models.ParseMoney(r.MinAmount, currency)
+			if err != nil {
+				return nil, fmt.Errorf("notify rule %d: %w", i+1, err)
+			}
+			rule.MinAmount = min
+		}
+		rules = append(rules, rule)
+	}
+
+	return notify.New(&notify.Config{Logger: logger, Notifiers: notifiers, Rules: rules})
+}
+
+func newConverter(logger zerolog.Logger, c appConfig.FXConfig, location *time.Location) (*fx.Converter, error) {
+	var provider fx.Provider
+	switch c.Provider {
+	case "static":
+		static, err := fx.NewStatic(c.Base, c.Rates)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fx rates: %w", err)
+		}
+		provider = static
+	case "csv":
+		if c.Path == "" {
+			return nil, fmt.Errorf("fx csv provider requires a path")
+		}
+		rates, err := fx.NewCSV(c.Base, c.Path)
+		if err != nil {
+			return nil, err
+		}
+		provider = rates
+	case "http":
+		if c.URL == "" {
+			return nil, fmt.Errorf("fx http provider requires a url")
+		}
+		provider = fx.NewHTTP(&fx.HTTPConfig{URL: c.URL})
+	default:
+		return nil, fmt.Errorf("unknown fx provider %q", c.Provider)
+	}
+
+	return fx.New(&fx.Config{Logger: logger, Base: c.Base, Provider: provider, Location: location})
+}
+
+func newReconcileService(logger zerolog.Logger, c appConfig.ReconcileConfig, publisher events.Publisher, discrepancies storage.DiscrepancyStorage) (*reconcile.Service, error) {
+	var store storage.BalanceStorage
+	switch c.Backend {
+	case "memory":
+		store = balanceStorage.NewMemory()
+	case "file":
+		if c.Path == "" {
+			return nil, fmt.Errorf("reconcile file backend requires a path")
+		}
+		store = balanceStorage.NewFile(c.Path)
+	default:
+		return nil, fmt.Errorf("unknown reconcile backend %q", c.Backend)
+	}
+
+	return reconcile.New(&reconcile.Config{
+		Logger:        logger,
+		Balances:      store,
+		Discrepancies: discrepancies,
+		Publisher:     publisher,
+	}), nil
+}
+
+// newDiscrepancyStorage returns nil when no discrepancy sheet is configured.
+func newDiscrepancyStorage(c appConfig.ReconcileConfig, srv *sheets.Service, location *time.Location) storage.DiscrepancyStorage {
+	if c.DiscrepancySheet.SheetID == "" {
+		return nil
+	}
+	return financeStorage.NewDiscrepancyStorage(&financeStorage.DiscrepancyConfig{
+		Service: srv,
+		Sheet: financeStorage.Sheet{
+			SheetID:   c.DiscrepancySheet.SheetID,
+			SheetName: c.DiscrepancySheet.SheetName,
+		},
+		Location: location,
+	})
+}
+
+// newBillStorage routes LECO bills to the LECO sheet and, when one is
+// configured, CEB bills to the CEB sheet.
+func newBillStorage(cfg *appConfig.Config, srv *sheets.Service) *ebillStorage.Router {
+	sheets := map[string]appConfig.SheetConfig{ebillModel.ProviderLECO: cfg.LecoSheetConfig}
+	if cfg.CEBSheetConfig.SheetID != "" {
+		sheets[ebillModel.ProviderCEB] = cfg.CEBSheetConfig
+	}
+
+	ledgers := make(map[string]storage.BillLedger, len(sheets))
+	for provider, sheet := range sheets {
+		ledgers[provider] = ebillStorage.New(&ebillStorage.Config{
+			Service:   srv,
+			SheetID:   sheet.SheetID,
+			SheetName: sheet.SheetName,
+			Provider:  provider,
+			GoogleRetryConfig: &retry.GoogleRetryConfig{
+				MaxAttempts:    3,
+				InitialBackoff: 1 * time.Second,
+				MaxBackoff:     5 * time.Second,
+			},
+		})
+	}
+	return ebillStorage.NewRouter(ledgers)
+}
+
+// newWaterBillStorage returns nil when no NWSDB sheet is configured.
+func newWaterBillStorage(c appConfig.SheetConfig, srv *sheets.Service) storage.MessageStorage[*waterbillModel.WaterBill] {
+	if c.SheetID == "" {
+		return nil
+	}
+	return waterbillStorage.New(&waterbillStorage.Config{
+		Service:   srv,
+		SheetID:   c.SheetID,
+		SheetName: c.SheetName,
+		GoogleRetryConfig: &retry.GoogleRetryConfig{
+			MaxAttempts:    3,
+			InitialBackoff: 1 * time.Second,
+			MaxBackoff:     5 * time.Second,
+		},
+	})
+}
+
+func newSolarSummaryStorage(c appConfig.SolarConfig, srv *sheets.Service) storage.SolarSummaryStorage {
+	if c.SummarySheet.SheetID == "" {
+		return nil
+	}
+	return ebillStorage.NewSolarStorage(&ebillStorage.SolarConfig{
+		Service:   srv,
+		SheetID:   c.SummarySheet.SheetID,
+		SheetName: c.SummarySheet.SheetName,
+	})
+}
+
+// declinedSheet returns the sheet for declined transactions, nil when they
+// stay in the institution sheets.
+func declinedSheet(cfg *appConfig.Config) *financeStorage.Sheet {
+	if cfg.DeclinedSheetConfig.SheetID == "" {
+		return nil
+	}
+	return &financeStorage.Sheet{
+		SheetID:   cfg.DeclinedSheetConfig.SheetID,
+		SheetName: cfg.DeclinedSheetConfig.SheetName,
+	}
+}
+
+func transactionSheets(cfg *appConfig.Config) map[string]financeStorage.Sheet {
+	sheets := make(map[string]financeStorage.Sheet)
+	for institution, sheet := range cfg.TransactionSheetConfigs() {
+		sheets[institution] = financeStorage.Sheet{SheetID: sheet.SheetID, SheetName: sheet.SheetName}
+	}
+	return sheets
+}
+
+func newIdempotencyStorage(c appConfig.IdempotencyConfig, awsConfig aws.Config) (storage.IdempotencyStorage, error) {
+	switch c.Backend {
+	case "memory":
+		return idempotencyStorage.NewMemory(), nil
+	case "file":
+		if c.Path == "" {
+			return nil, fmt.Errorf("idempotency file backend requires a path")
+		}
+		return idempotencyStorage.NewFile(c.Path), nil
+	case "dynamodb":
+		if c.Table == "" {
+			return nil, fmt.Errorf("idempotency dynamodb backend requires a table")
+		}
+		return idempotencyStorage.NewDynamoDB(&idempotencyStorage.DynamoDBConfig{
+			Client: dynamodb.NewFromConfig(awsConfig),
+			Table:  c.Table,
+			RetryConfig: &retry.AWSRetryConfig{
+				MaxAttempts:    3,
+				InitialBackoff: 1 * time.Second,
+				MaxBackoff:     5 * time.Second,
+			},
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown idempotency backend %q", c.Backend)
+	}
+}
+
+func newRawMessageStorage(c appConfig.RawMessageConfig, srv *sheets.Service) (storage.MessageStorage[*models.Message], error) {
+	switch c.Backend {
+	case "":
+		return nil, nil
+	case "sheet":
+		return gsheet.NewEnhanced(&gsheet.EnhancedConfig{
+			Service:   srv,
+			SheetID:   c.SheetID,
+			SheetName: c.SheetName,
+			GoogleRetryConfig: retry.GoogleRetryConfig{
+				MaxAttempts:    3,
+				InitialBackoff: 1 * time.Second,
+				MaxBackoff:     5 * time.Second,
+			},
+		}), nil
+	case "file":
+		if c.Path == "" {
+			return nil, fmt.Errorf("raw message file backend requires a path")
+		}
+		return rawmessage.NewFile(c.Path), nil
+	default:
+		return nil, fmt.Errorf("unknown raw message backend %q", c.Backend)
+	}
+}
+
+func newDeadLetterStorage(c appConfig.DeadLetterConfig, awsConfig aws.Config) (storage.DeadLetterStorage, error) {
+	switch c.Backend {
+	case "file":
+		if c.Path == "" {
+			return nil, fmt.Errorf("dead letter file backend requires a path")
+		}
+		return deadletterStorage.NewFile(c.Path), nil
+	case "sqs":
+		if c.QueueURL == "" {
+			return nil, fmt.Errorf("dead letter sqs backend requires a queue_url")
+		}
+		return deadletterStorage.NewSQS(&deadletterStorage.SQSConfig{
+			Client:   sqs.NewFromConfig(awsConfig),
+			QueueURL: c.QueueURL,
+			RetryConfig: &retry.AWSRetryConfig{
+				MaxAttempts:    3,
+				InitialBackoff: 1 * time.Second,
+				MaxBackoff:     5 * time.Second,
+			},
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown dead letter backend %q", c.Backend)
+	}
+}
+
+type configParams struct {
+	sheetKeys string
+	appConfig string
+}
+
+func loadParameters(ctx context.Context, awsConfig aws.Config) (configParams, error) {
+	client := ssm.NewFromConfig(awsConfig)
+
+	// Use enhanced parameter store with retry capabilities
+	store := parameterstore.NewWithConfig(&parameterstore.Config{
+		Client: client,
+		RetryConfig: &retry.AWSRetryConfig{
+			MaxAttempts:    3,
+			InitialBackoff: 1 * time.Second,
+			MaxBackoff:     5 * time.Second,
+		},
+	})
+
+	sk := os.Getenv("SHEET_KEY")
+	ac := os.Getenv("APP_CONFIG")
+
+	// Get parameters from the store
+	sheetKey, err := store.GetParameter(ctx, sk)
+	if err != nil {
+		return configParams{}, fmt.Errorf("failed to get sheet key: %w", err)
+	}
+
+	appConfig, err := store.GetParameter(ctx, ac)
+	if err != nil {
+		return configParams{}, fmt.Errorf("failed to get app config: %w", err)
+	}
+
+	return configParams{
+		sheetKeys: sheetKey,
+		appConfig: appConfig,
+	}, nil
+}
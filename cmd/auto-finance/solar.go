@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	appConfig "auto-finance/internal/config"
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/ebill"
+	"auto-finance/internal/service/solar"
+
+	"github.com/rs/zerolog"
+)
+
+// runSolar analyses the saved electricity bills of accounts with rooftop solar,
+// writes the summary sheet when one is configured and prints the summary.
+func runSolar(ctx context.Context, logger zerolog.Logger, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("solar", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: auto-finance solar [flags]")
+		fmt.Fprintln(fs.Output(), "\nSummarizes solar exports, generation income and payback from the electricity bill sheets.")
+		fs.PrintDefaults()
+	}
+	var source configSource
+	source.registerFlags(fs)
+	format := fs.String("format", "table", `output format: "table" or "json"`)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	// Run replaces the summary sheet, so a bad format must fail first.
+	if *format != "table" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	c, err := setup(ctx, logger, source)
+	if err != nil {
+		return err
+	}
+
+	svcConfig, err := solarConfig(c.config.Solar)
+	if err != nil {
+		return err
+	}
+	svcConfig.Logger = logger
+	svcConfig.Bills = c.bills
+	svcConfig.Summary = c.solarSummary
+
+	months, err := solar.New(svcConfig).Run(ctx)
+	if months == nil && err != nil {
+		return err
+	}
+	logger.Info().Int("months", len(months)).Msg("Solar summary updated")
+
+	if writeErr := writeSolar(stdout, months, *format); writeErr != nil {
+		return writeErr
+	}
+	return err
+}
+
+func solarConfig(c appConfig.SolarConfig) (*solar.Config, error) {
+	svcConfig := &solar.Config{
+		Accounts:      c.Accounts,
+		CapacityKWp:   c.CapacityKWp,
+		DailyYield:    c.DailyYield,
+		DropThreshold: c.DropThreshold,
+		DropWindow:    c.DropWindow,
+	}
+	if c.SystemCost != "" {
+		cost, err := models.ParseMoney(c.SystemCost, "LKR")
+		if err != nil {
+			return nil, fmt.Errorf("solar system cost: %w", err)
+		}
+		svcConfig.SystemCost = cost
+	}
+	if c.InstalledOn != "" {
+		installedOn, err := time.Parse(time.DateOnly, c.InstalledOn)
+		if err != nil {
+			return nil, fmt.Errorf("solar installed_on: %w", err)
+		}
+		svcConfig.InstalledOn = installedOn
+	}
+	return svcConfig, nil
+}
+
+func writeSolar(w io.Writer, months []*ebill.SolarMonth, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if months == nil {
+			months = []*ebill.SolarMonth{}
+		}
+		return enc.Encode(months)
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "ACCOUNT\tREAD ON\tIMPORT\tEXPORT\tSELF USE\tEXPORT %\tINCOME\tIMPORT COST\tYTD INCOME\tPAYBACK\tDROP")
+		for _, m := range months {
+			selfUse, exportRatio := "-", "-"
+			if m.EstimatedGeneration > 0 {
+				selfUse = fmt.Sprintf("%d", m.SelfConsumption)
+				exportRatio = fmt.Sprintf("%.1f%%", m.ExportRatio*100)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\t%.1f%%\t%t\n",
+				m.AccountNumber,
+				m.ReadOn.Format(time.DateOnly),
+				m.ImportUnits,
+				m.ExportUnits,
+				selfUse,
+				exportRatio,
+				m.GenerationIncome,
+				m.ImportCost,
+				m.YearToDateIncome,
+				m.PaybackProgress*100,
+				m.ExportDrop,
+			)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/service/subscription"
+	"auto-finance/internal/storage"
+	subscriptionStorage "auto-finance/internal/storage/subscription"
+
+	"github.com/rs/zerolog"
+)
+
+// runSubscriptions examines the stored transactions for recurring charges,
+// updates the subscription list, sends alerts for new, changed and missed
+// subscriptions and prints the list. It is meant to run daily.
+func runSubscriptions(ctx context.Context, logger zerolog.Logger, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("subscriptions", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: auto-finance subscriptions [flags]")
+		fmt.Fprintln(fs.Output(), "\nDetects recurring charges in the transaction sheets and alerts on changes.")
+		fs.PrintDefaults()
+	}
+	var source configSource
+	source.registerFlags(fs)
+	format := fs.String("format", "table", `output format: "table" or "json"`)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	// Run saves the list and sends alerts, so a bad format must fail first.
+	if *format != "table" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	c, err := setup(ctx, logger, source)
+	if err != nil {
+		return err
+	}
+
+	cfg := c.config.Subscriptions
+	var store storage.SubscriptionStorage
+	switch cfg.Backend {
+	case "memory":
+		store = subscriptionStorage.NewMemory()
+	case "file":
+		if cfg.Path == "" {
+			return fmt.Errorf("subscriptions file backend requires a path")
+		}
+		store = subscriptionStorage.NewFile(cfg.Path)
+	case "":
+		return fmt.Errorf("no [subscriptions] backend configured")
+	default:
+		return fmt.Errorf("unknown subscriptions backend %q", cfg.Backend)
+	}
+
+	svc := subscription.New(&subscription.Config{
+		Logger:       logger,
+		Transactions: c.transactions,
+		Institutions: slices.Sorted(maps.Keys(transactionSheets(c.config))),
+		Storage:      store,
+		Publisher:    c.events,
+		Lookback:     cfg.Lookback,
+	})
+
+	subscriptions, alerts, err := svc.Run(ctx, time.Now())
+	if subscriptions == nil && err != nil {
+		return err
+	}
+	logger.Info().Int("subscriptions", len(subscriptions)).Int("alerts", len(alerts)).Msg("Subscriptions updated")
+
+	if writeErr := writeSubscriptions(stdout, subscriptions, *format, c.location); writeErr != nil {
+		return writeErr
+	}
+	return err
+}
+
+func writeSubscriptions(w io.Writer, subscriptions []*finance.Subscription, format string, location *time.Location) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if subscriptions == nil {
+			subscriptions = []*finance.Subscription{}
+		}
+		return enc.Encode(subscriptions)
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "MERCHANT\tACCOUNT\tCADENCE\tAMOUNT\tCHARGES\tLAST\tNEXT\tMISSED")
+		for _, s := range subscriptions {
+			fmt.Fprintf(tw, "%s\t%s %s\t%s\t%s\t%d\t%s\t%s\t%t\n",
+				s.Merchant,
+				s.Institution,
+				s.Account,
+				s.Cadence,
+				s.Amount,
+				s.Charges,
+				s.LastChargedAt.In(location).Format(time.DateOnly),
+				s.NextExpectedAt.In(location).Format(time.DateOnly),
+				s.Missed,
+			)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
@@ -3,10 +3,15 @@ package autofinance
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"time"
 
+	"auto-finance/internal/models"
 	"auto-finance/internal/service/message"
+	"auto-finance/internal/storage"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 )
 
@@ -15,16 +20,40 @@ const testSender = "TEST_SENDER"
 type Config struct {
 	Logger         zerolog.Logger
 	MessageService message.Service
+	// Location is used for requests that do not specify a timezone.
+	// Defaults to UTC.
+	Location *time.Location
+	// RawMessageStorage receives every inbound SMS and its outcome. Optional.
+	RawMessageStorage storage.MessageStorage[*models.Message]
+	// DeadLetters keeps messages that failed so they can be replayed. Optional.
+	DeadLetters DeadLetterCapturer
 }
+
+// DeadLetterCapturer stores a message that could not be processed.
+type DeadLetterCapturer interface {
+	Capture(ctx context.Context, msg message.Message, cause error) error
+}
+
 type App struct {
-	logger         zerolog.Logger
-	messageService message.Service
+	logger            zerolog.Logger
+	messageService    message.Service
+	location          *time.Location
+	rawMessageStorage storage.MessageStorage[*models.Message]
+	deadLetters       DeadLetterCapturer
 }
 
 func New(config *Config) *App {
+	location := config.Location
+	if location == nil {
+		location = time.UTC
+	}
+
 	return &App{
-		logger:         config.Logger,
-		messageService: config.MessageService,
+		logger:            config.Logger,
+		messageService:    config.MessageService,
+		location:          location,
+		rawMessageStorage: config.RawMessageStorage,
+		deadLetters:       config.DeadLetters,
 	}
 }
 
@@ -35,35 +64,98 @@ func (app *App) Handler(ctx context.Context, event events.APIGatewayProxyRequest
 	var req Request
 	if err := json.Unmarshal([]byte(event.Body), &req); err != nil {
 		app.logger.Error().Err(err).Msg("Failed to unmarshal request")
-		return events.APIGatewayProxyResponse{
-			StatusCode: 400,
-			Body:       "Bad Request",
-		}, nil
+		return respond(400, Response{Status: StatusError, Message: "Bad Request"}), nil
 	}
 
 	app.logger.Info().Ctx(ctx).Any("request", req).Msg("Request received")
 
 	if req.Test || req.Sender == testSender {
 		app.logger.Info().Msg("Test mode is enabled, skipping sheet write")
-		return events.APIGatewayProxyResponse{
-			StatusCode: 200,
-			Body:       "Test mode, no action taken",
-		}, nil
+		return respond(200, Response{Status: StatusTest, Message: "Test mode, no action taken"}), nil
+	}
+
+	receivedAt, err := req.ReceivedTime(app.location, time.Now())
+	if err != nil {
+		app.logger.Error().Err(err).Msg("Failed to resolve received time")
+		return respond(400, Response{Status: StatusError, Message: "Bad Request"}), nil
+	}
+
+	msg := message.Message{
+		ID:         uuid.New(),
+		Sender:     req.Sender,
+		Body:       req.Body,
+		ReceivedAt: receivedAt,
+		ExternalID: req.MessageID,
+	}
+	id := msg.ID.String()
+
+	result, err := app.messageService.PassMessage(ctx, msg)
+	if err != nil {
+		switch {
+		case errors.Is(err, message.ErrDuplicate):
+			app.logger.Info().Err(err).Str("message_id", id).Msg("Duplicate message acknowledged")
+			app.recordRawMessage(ctx, msg, models.OutcomeDuplicate, result, nil)
+			return respond(200, Response{Status: StatusDuplicate, MessageID: id, Message: "Duplicate message, no action taken"}), nil
+		case errors.Is(err, message.ErrUnknownSender):
+			app.logger.Warn().Err(err).Str("message_id", id).Msg("Rejected message from unknown sender")
+			app.recordRawMessage(ctx, msg, models.OutcomeRejected, result, err)
+			return respond(400, Response{Status: StatusError, MessageID: id, Message: "Unknown Sender"}), nil
+		}
+
+		app.logger.Error().Err(err).Str("message_id", id).Msg("Failed to pass message")
+		app.recordRawMessage(ctx, msg, models.OutcomeFailed, result, err)
+
+		if app.deadLetters != nil {
+			if dlErr := app.deadLetters.Capture(ctx, msg, err); dlErr != nil {
+				app.logger.Error().Err(dlErr).Str("message_id", id).Msg("Failed to dead letter message")
+			} else {
+				return respond(202, Response{Status: StatusDeadLettered, MessageID: id, Message: "Message queued for replay"}), nil
+			}
+		}
+
+		return respond(500, Response{Status: StatusError, MessageID: id, Message: "Internal Server Error"}), nil
 	}
 
-	if err := app.messageService.PassMessage(ctx, message.Message{
-		Sender: req.Sender,
-		Body:   req.Body,
-	}); err != nil {
-		app.logger.Error().Err(err).Msg("Failed to pass message")
-		return events.APIGatewayProxyResponse{
-			StatusCode: 500,
-			Body:       "Internal Server Error",
-		}, nil
+	app.recordRawMessage(ctx, msg, models.OutcomeProcessed, result, nil)
+
+	return respond(200, Response{Status: StatusProcessed, MessageID: id}), nil
+}
+
+// recordRawMessage appends the audit log entry for msg once its outcome is
+// known, so each message has a single row. The audit log must never stop a
+// message from being processed, so failures are only logged.
+func (app *App) recordRawMessage(ctx context.Context, msg message.Message, outcome string, result message.Result, err error) {
+	if app.rawMessageStorage == nil {
+		return
+	}
+
+	entry := &models.Message{
+		ID:      msg.ID,
+		From:    msg.Sender,
+		Message: msg.Body,
+		Time:    msg.ReceivedAt,
+		Outcome: outcome,
+		Parser:  result.Parser,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	if saveErr := app.rawMessageStorage.Save(ctx, entry); saveErr != nil {
+		app.logger.Error().Err(saveErr).Str("message_id", msg.ID.String()).Str("outcome", outcome).Msg("Failed to record raw message")
+	}
+}
+
+func respond(statusCode int, resp Response) events.APIGatewayProxyResponse {
+	body, err := json.Marshal(resp)
+	if err != nil {
+		// Response only holds strings, so this cannot happen in practice.
+		body = []byte(`{"status":"error"}`)
 	}
 
 	return events.APIGatewayProxyResponse{
-		StatusCode: 200,
-		Body:       "Hello from Auto Finance!",
-	}, nil
+		StatusCode: statusCode,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       string(body),
+	}
 }
@@ -0,0 +1,143 @@
+package autofinance_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	autofinance "auto-finance/internal/app/auto-finance"
+	"auto-finance/internal/models"
+	"auto-finance/internal/service/message"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubMessageService struct {
+	result message.Result
+	err    error
+	got    []message.Message
+}
+
+func (s *stubMessageService) PassMessage(_ context.Context, msg message.Message) (message.Result, error) {
+	s.got = append(s.got, msg)
+	return s.result, s.err
+}
+
+type memoryRawStorage struct {
+	saved []*models.Message
+}
+
+func (m *memoryRawStorage) Save(_ context.Context, msg *models.Message) error {
+	m.saved = append(m.saved, msg)
+	return nil
+}
+
+func TestHandler_RecordsRawMessage(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceErr  error
+		wantCode    int
+		wantStatus  string
+		wantOutcome string
+	}{
+		{name: "processed", wantCode: 200, wantStatus: autofinance.StatusProcessed, wantOutcome: models.OutcomeProcessed},
+		{name: "duplicate", serviceErr: message.ErrDuplicate, wantCode: 200, wantStatus: autofinance.StatusDuplicate, wantOutcome: models.OutcomeDuplicate},
+		{name: "unknown sender", serviceErr: message.ErrUnknownSender, wantCode: 400, wantStatus: autofinance.StatusError, wantOutcome: models.OutcomeRejected},
+		{name: "failure", serviceErr: errors.New("boom"), wantCode: 500, wantStatus: autofinance.StatusError, wantOutcome: models.OutcomeFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &stubMessageService{result: message.Result{Parser: "Sampath Bank Parser"}, err: tt.serviceErr}
+			raw := &memoryRawStorage{}
+			app := autofinance.New(&autofinance.Config{
+				Logger:            zerolog.Nop(),
+				MessageService:    svc,
+				RawMessageStorage: raw,
+			})
+
+			resp, err := app.Handler(context.Background(), events.APIGatewayProxyRequest{
+				Body: `{"sender":"SAMPATH","body":"sms body","received_at":"2025-11-07 18:42:10","timezone":"Asia/Colombo"}`,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+
+			var body autofinance.Response
+			require.NoError(t, json.Unmarshal([]byte(resp.Body), &body))
+			assert.Equal(t, tt.wantStatus, body.Status)
+
+			require.Len(t, svc.got, 1)
+			assert.Equal(t, svc.got[0].ID.String(), body.MessageID)
+			assert.Equal(t, "2025-11-07T18:42:10+05:30", svc.got[0].ReceivedAt.Format("2006-01-02T15:04:05Z07:00"))
+
+			require.Len(t, raw.saved, 1)
+			assert.Equal(t, tt.wantOutcome, raw.saved[0].Outcome)
+			assert.Equal(t, body.MessageID, raw.saved[0].ID.String())
+			assert.Equal(t, "sms body", raw.saved[0].Message)
+			assert.Equal(t, tt.serviceErr != nil && tt.wantOutcome != models.OutcomeDuplicate, raw.saved[0].Error != "")
+		})
+	}
+}
+
+func TestHandler_RejectsInvalidReceivedAt(t *testing.T) {
+	svc := &stubMessageService{}
+	app := autofinance.New(&autofinance.Config{Logger: zerolog.Nop(), MessageService: svc})
+
+	resp, err := app.Handler(context.Background(), events.APIGatewayProxyRequest{
+		Body: `{"sender":"SAMPATH","body":"x","received_at":"yesterday"}`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Empty(t, svc.got)
+}
+
+type recordingDeadLetters struct {
+	captured []message.Message
+	err      error
+}
+
+func (r *recordingDeadLetters) Capture(_ context.Context, msg message.Message, _ error) error {
+	r.captured = append(r.captured, msg)
+	return r.err
+}
+
+func TestHandler_DeadLettersFailures(t *testing.T) {
+	tests := []struct {
+		name       string
+		captureErr error
+		wantCode   int
+		wantStatus string
+	}{
+		{name: "captured", wantCode: 202, wantStatus: autofinance.StatusDeadLettered},
+		{name: "capture failed", captureErr: errors.New("disk full"), wantCode: 500, wantStatus: autofinance.StatusError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &stubMessageService{err: errors.New("boom")}
+			dl := &recordingDeadLetters{err: tt.captureErr}
+			app := autofinance.New(&autofinance.Config{
+				Logger:         zerolog.Nop(),
+				MessageService: svc,
+				DeadLetters:    dl,
+			})
+
+			resp, err := app.Handler(context.Background(), events.APIGatewayProxyRequest{
+				Body: `{"sender":"HNB","body":"sms body"}`,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+
+			var body autofinance.Response
+			require.NoError(t, json.Unmarshal([]byte(resp.Body), &body))
+			assert.Equal(t, tt.wantStatus, body.Status)
+
+			require.Len(t, dl.captured, 1)
+			assert.Equal(t, svc.got[0], dl.captured[0])
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package autofinance
+
+import (
+	"crypto/subtle"
+	"io"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// APIKeyHeader carries the API key, matching what API Gateway expects.
+const APIKeyHeader = "X-API-Key"
+
+// maxBodyBytes bounds request bodies; an SMS payload is a few hundred bytes.
+const maxBodyBytes = 64 << 10
+
+// HTTPHandler exposes Handler as POST /finance on a plain net/http server.
+// Requests must carry apiKey in the X-API-Key header unless apiKey is empty.
+func (app *App) HTTPHandler(apiKey string) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /finance", func(w http.ResponseWriter, r *http.Request) {
+		if apiKey != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get(APIKeyHeader)), []byte(apiKey)) != 1 {
+			writeResponse(w, respond(http.StatusUnauthorized, Response{Status: StatusError, Message: "Unauthorized"}))
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+		if err != nil {
+			writeResponse(w, respond(http.StatusRequestEntityTooLarge, Response{Status: StatusError, Message: "Request Too Large"}))
+			return
+		}
+
+		// Handler logs the event, so the API key is left out.
+		headers := make(map[string]string, len(r.Header))
+		for name := range r.Header {
+			if name == http.CanonicalHeaderKey(APIKeyHeader) {
+				continue
+			}
+			headers[name] = r.Header.Get(name)
+		}
+
+		resp, err := app.Handler(r.Context(), events.APIGatewayProxyRequest{
+			HTTPMethod: r.Method,
+			Path:       r.URL.Path,
+			Headers:    headers,
+			Body:       string(body),
+		})
+		if err != nil {
+			app.logger.Error().Err(err).Msg("Handler failed")
+			writeResponse(w, respond(http.StatusInternalServerError, Response{Status: StatusError, Message: "Internal Server Error"}))
+			return
+		}
+
+		writeResponse(w, resp)
+	})
+
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	return mux
+}
+
+func writeResponse(w http.ResponseWriter, resp events.APIGatewayProxyResponse) {
+	for name, value := range resp.Headers {
+		w.Header().Set(name, value)
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.WriteString(w, resp.Body)
+}
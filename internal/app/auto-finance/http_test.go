@@ -0,0 +1,73 @@
+package autofinance_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	autofinance "auto-finance/internal/app/auto-finance"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		apiKey   string
+		wantCode int
+		wantPass bool
+	}{
+		{name: "valid key", method: http.MethodPost, path: "/finance", apiKey: "secret", wantCode: 200, wantPass: true},
+		{name: "missing key", method: http.MethodPost, path: "/finance", wantCode: 401},
+		{name: "wrong key", method: http.MethodPost, path: "/finance", apiKey: "nope", wantCode: 401},
+		{name: "wrong method", method: http.MethodGet, path: "/finance", apiKey: "secret", wantCode: 405},
+		{name: "unknown path", method: http.MethodPost, path: "/other", apiKey: "secret", wantCode: 404},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &stubMessageService{}
+			app := autofinance.New(&autofinance.Config{Logger: zerolog.Nop(), MessageService: svc})
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(`{"sender":"SAMPATH","body":"sms body"}`))
+			if tt.apiKey != "" {
+				req.Header.Set(autofinance.APIKeyHeader, tt.apiKey)
+			}
+			rec := httptest.NewRecorder()
+
+			app.HTTPHandler("secret").ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			if tt.wantPass {
+				require.Len(t, svc.got, 1)
+				assert.Equal(t, "sms body", svc.got[0].Body)
+				assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+				assert.Contains(t, rec.Body.String(), `"status":"processed"`)
+			} else {
+				assert.Empty(t, svc.got)
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_DoesNotLogAPIKey(t *testing.T) {
+	var logs bytes.Buffer
+	svc := &stubMessageService{}
+	app := autofinance.New(&autofinance.Config{Logger: zerolog.New(&logs).Level(zerolog.DebugLevel), MessageService: svc})
+
+	req := httptest.NewRequest(http.MethodPost, "/finance", strings.NewReader(`{"sender":"SAMPATH","body":"sms body"}`))
+	req.Header.Set(autofinance.APIKeyHeader, "secret")
+	rec := httptest.NewRecorder()
+
+	app.HTTPHandler("secret").ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, logs.String(), "Handler started")
+	assert.NotContains(t, logs.String(), "secret")
+}
@@ -1,7 +1,74 @@
 package autofinance
 
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
 type Request struct {
 	Sender string `json:"sender"`
 	Body   string `json:"body"`
 	Test   bool   `json:"test"`
+	// ReceivedAt is the time the phone received the SMS, either RFC 3339,
+	// "2006-01-02 15:04:05" or Unix epoch milliseconds.
+	ReceivedAt string `json:"received_at,omitempty"`
+	// Timezone is an IANA zone name (e.g. "Asia/Colombo") used to interpret
+	// ReceivedAt when it has no offset.
+	Timezone string `json:"timezone,omitempty"`
+	// MessageID is an optional client generated ID. Requests repeating an
+	// ID that was already processed are acknowledged without any action.
+	MessageID string `json:"message_id,omitempty"`
+}
+
+// Response is the JSON body returned by the handler.
+type Response struct {
+	Status string `json:"status"`
+	// MessageID is the audit log ID assigned to the raw SMS.
+	MessageID string `json:"message_id,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+const (
+	StatusProcessed = "processed"
+	StatusDuplicate = "duplicate"
+	StatusTest      = "test"
+	StatusError     = "error"
+	// StatusDeadLettered means the message failed but was kept for replay.
+	StatusDeadLettered = "dead_lettered"
+)
+
+// ReceivedTime resolves ReceivedAt and Timezone into a time in the requested
+// location, falling back to now when no timestamp was supplied.
+func (r Request) ReceivedTime(defaultLocation *time.Location, now time.Time) (time.Time, error) {
+	loc := defaultLocation
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	if r.Timezone != "" {
+		l, err := time.LoadLocation(r.Timezone)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid timezone %q: %w", r.Timezone, err)
+		}
+		loc = l
+	}
+
+	if r.ReceivedAt == "" {
+		return now.In(loc), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, r.ReceivedAt); err == nil {
+		return t.In(loc), nil
+	}
+
+	if t, err := time.ParseInLocation(time.DateTime, r.ReceivedAt, loc); err == nil {
+		return t, nil
+	}
+
+	if ms, err := strconv.ParseInt(r.ReceivedAt, 10, 64); err == nil {
+		return time.UnixMilli(ms).In(loc), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid received_at %q", r.ReceivedAt)
 }
@@ -2,15 +2,59 @@ package config
 
 import (
 	"context"
+	"time"
 
+	"auto-finance/internal/service/category"
+	"auto-finance/internal/smsparser/template"
 	"auto-finance/internal/storage"
 
 	"github.com/BurntSushi/toml"
 )
 
 type Config struct {
-	LecoSheetConfig    SheetConfig `toml:"leco_sheet_config"`
+	LecoSheetConfig SheetConfig `toml:"leco_sheet_config"`
+	// CEBSheetConfig receives CEB bills. The ceb parser is only enabled when
+	// it is set.
+	CEBSheetConfig SheetConfig `toml:"ceb_sheet_config"`
+	// NWSDBSheetConfig receives NWSDB water bills. The nwsdb parser is only
+	// enabled when it is set.
+	NWSDBSheetConfig   SheetConfig `toml:"nwsdb_sheet_config"`
 	FinanceSheetConfig SheetConfig `toml:"finance_sheet_config"`
+	HNBSheetConfig     SheetConfig `toml:"hnb_sheet_config"`
+	// TransactionSheets maps an institution ("Sampath", "HNB") to the sheet
+	// its transactions are appended to. finance_sheet_config and
+	// hnb_sheet_config are used for institutions missing here.
+	TransactionSheets map[string]SheetConfig `toml:"transaction_sheets"`
+	// DeclinedSheetConfig, when set, receives declined card transactions
+	// from every institution instead of the transaction sheets.
+	DeclinedSheetConfig SheetConfig `toml:"declined_sheet_config"`
+	// ReversalWindow is how long before a card reversal its authorization
+	// may have occurred, 7 days when zero.
+	ReversalWindow time.Duration `toml:"reversal_window"`
+	// Parsers lists the enabled parsers ("leco", "ceb", "nwsdb", "sampath",
+	// "hnb") in the order they are tried for senders without a route. Empty
+	// enables all.
+	Parsers []string `toml:"parsers"`
+	// Templates are regex parsers for simple bank formats. They are registered
+	// after the built-in parsers.
+	Templates []template.Definition `toml:"templates"`
+	// SenderRoutes maps SMS sender IDs (e.g. "SAMPATH") to parser names
+	// ("sampath", "leco", "ceb", "nwsdb", "hnb").
+	SenderRoutes map[string]string `toml:"sender_routes"`
+	// Timezone is the IANA zone used for messages that arrive without one.
+	Timezone      string             `toml:"timezone"`
+	Idempotency   IdempotencyConfig  `toml:"idempotency"`
+	RawMessages   RawMessageConfig   `toml:"raw_messages"`
+	DeadLetter    DeadLetterConfig   `toml:"dead_letter"`
+	Server        ServerConfig       `toml:"server"`
+	Categories    CategoryConfig     `toml:"categories"`
+	Budget        BudgetConfig       `toml:"budget"`
+	Notify        NotifyConfig       `toml:"notify"`
+	Reconcile     ReconcileConfig    `toml:"reconcile"`
+	FX            FXConfig           `toml:"fx"`
+	Subscriptions SubscriptionConfig `toml:"subscriptions"`
+	Tariff        TariffConfig       `toml:"tariff"`
+	Solar         SolarConfig        `toml:"solar"`
 }
 
 type SheetConfig struct {
@@ -18,6 +62,249 @@ type SheetConfig struct {
 	SheetName string `toml:"sheet_name"`
 }
 
+// TransactionSheetConfigs returns the transaction sheet per institution,
+// falling back to the legacy per-bank sections.
+func (c *Config) TransactionSheetConfigs() map[string]SheetConfig {
+	sheets := make(map[string]SheetConfig, len(c.TransactionSheets)+2)
+	if c.FinanceSheetConfig.SheetID != "" {
+		sheets["Sampath"] = c.FinanceSheetConfig
+	}
+	if c.HNBSheetConfig.SheetID != "" {
+		sheets["HNB"] = c.HNBSheetConfig
+	}
+	for institution, sheet := range c.TransactionSheets {
+		sheets[institution] = sheet
+	}
+	return sheets
+}
+
+// IdempotencyConfig selects where processed message keys are remembered.
+type IdempotencyConfig struct {
+	// Backend is "memory", "file" or "dynamodb". Empty disables duplicate
+	// detection.
+	Backend string `toml:"backend"`
+	// Path is the JSON file used by the file backend.
+	Path string `toml:"path"`
+	// Table is the DynamoDB table used by the dynamodb backend.
+	Table string `toml:"table"`
+	// Window is the received time bucket used for content hashing.
+	Window time.Duration `toml:"window"`
+	// TTL is how long client supplied message IDs are remembered.
+	TTL time.Duration `toml:"ttl"`
+}
+
+// RawMessageConfig selects where every inbound SMS is recorded.
+type RawMessageConfig struct {
+	// Backend is "sheet" or "file". Empty disables the audit log.
+	Backend   string `toml:"backend"`
+	SheetID   string `toml:"sheet_id"`
+	SheetName string `toml:"sheet_name"`
+	// Path is the JSON Lines file used by the file backend.
+	Path string `toml:"path"`
+}
+
+// DeadLetterConfig selects where failed messages are kept for replay.
+type DeadLetterConfig struct {
+	// Backend is "file" or "sqs". Empty disables dead lettering.
+	Backend string `toml:"backend"`
+	// Path is the directory used by the file backend.
+	Path string `toml:"path"`
+	// QueueURL is the SQS queue used by the sqs backend.
+	QueueURL string `toml:"queue_url"`
+}
+
+// CategoryConfig configures merchant normalization and categorization. It is
+// disabled when neither RulesFile nor Rules is set.
+type CategoryConfig struct {
+	// RulesFile is a TOML rules file whose rules are tried after Rules.
+	RulesFile string `toml:"rules_file"`
+	// Cities replaces the built-in list of trailing location tokens.
+	Cities []string        `toml:"cities"`
+	Rules  []category.Rule `toml:"rules"`
+}
+
+// BudgetConfig configures month-to-date budget tracking.
+type BudgetConfig struct {
+	// Backend is "memory" or "file" and keeps the running totals. Empty
+	// disables budget tracking.
+	Backend string `toml:"backend"`
+	// Path is the JSON file used by the file backend.
+	Path string `toml:"path"`
+	// Thresholds are the percentages of a limit that raise an alert,
+	// 80 and 100 when empty.
+	Thresholds []int         `toml:"thresholds"`
+	Limits     []BudgetLimit `toml:"limits"`
+}
+
+// BudgetLimit is a monthly limit for a category, a card or account, or both.
+type BudgetLimit struct {
+	// Name defaults to the category and account.
+	Name     string `toml:"name"`
+	Category string `toml:"category"`
+	Account  string `toml:"account"`
+	// Limit is a decimal amount such as "25000.00".
+	Limit string `toml:"limit"`
+	// Currency defaults to LKR.
+	Currency string `toml:"currency"`
+}
+
+// ReconcileConfig configures balance reconciliation.
+type ReconcileConfig struct {
+	// Backend is "memory" or "file" and keeps the expected balance of each
+	// account. Empty disables reconciliation.
+	Backend string `toml:"backend"`
+	// Path is the JSON file used by the file backend.
+	Path string `toml:"path"`
+	// DiscrepancySheet is the sheet tab discrepancies are appended to. When
+	// empty they are only logged and published.
+	DiscrepancySheet SheetConfig `toml:"discrepancy_sheet"`
+}
+
+// FXConfig configures conversion of transactions into a base currency.
+type FXConfig struct {
+	// Base is the home currency, e.g. "LKR". Empty disables conversion.
+	Base string `toml:"base"`
+	// Provider is "static" (Rates), "csv" (Path) or "http" (URL).
+	Provider string `toml:"provider"`
+	// Rates maps a currency to the base currency units one unit is worth.
+	Rates map[string]string `toml:"rates"`
+	// Path is a CSV file of date,currency,rate rows.
+	Path string `toml:"path"`
+	// URL is a rates API template with {from}, {to} and {date} placeholders.
+	URL string `toml:"url"`
+}
+
+// SubscriptionConfig configures the "subscriptions" command.
+type SubscriptionConfig struct {
+	// Backend is "memory" or "file" and keeps the subscription list.
+	Backend string `toml:"backend"`
+	// Path is the JSON file used by the file backend.
+	Path string `toml:"path"`
+	// Lookback is how much history is examined, 400 days when zero.
+	Lookback time.Duration `toml:"lookback"`
+}
+
+// TariffConfig configures verification of electricity bills against the tariff.
+// It is disabled when there are no tariffs. Amounts are decimal LKR.
+type TariffConfig struct {
+	// Tolerance is the largest difference not reported, e.g. "1.00".
+	Tolerance string       `toml:"tolerance"`
+	Tariffs   []TariffRate `toml:"tariffs"`
+}
+
+// TariffRate prices one kind of account.
+type TariffRate struct {
+	Name string `toml:"name"`
+	// AccountTypes are matched against the account type on the bill. The
+	// first tariff without any applies to all other accounts.
+	AccountTypes []string `toml:"account_types"`
+	// SSCL is the levy as a percentage of the monthly bill, e.g. "2.5641".
+	SSCL string `toml:"sscl"`
+	// Scheme is "net_metering", "net_accounting" or empty.
+	Scheme string `toml:"scheme"`
+	// ExportRate is paid per exported unit under net accounting.
+	ExportRate string           `toml:"export_rate"`
+	Schedules  []TariffSchedule `toml:"schedules"`
+}
+
+// TariffSchedule is a set of blocks used up to MaxUnits of consumption,
+// or for any consumption when MaxUnits is zero.
+type TariffSchedule struct {
+	MaxUnits int           `toml:"max_units"`
+	Blocks   []TariffBlock `toml:"blocks"`
+}
+
+// TariffBlock charges Rate per unit up to UpTo units (no limit when zero)
+// and FixedCharge when consumption ends in the block.
+type TariffBlock struct {
+	UpTo        int    `toml:"up_to"`
+	Rate        string `toml:"rate"`
+	FixedCharge string `toml:"fixed_charge"`
+}
+
+// SolarConfig configures the "solar" command.
+type SolarConfig struct {
+	// Accounts are the electricity account numbers to analyse. Empty analyses
+	// every account that exported.
+	Accounts []string `toml:"accounts"`
+	// CapacityKWp is the system size, needed to estimate generation and
+	// self-consumption.
+	CapacityKWp float64 `toml:"capacity_kwp"`
+	// DailyYield is units per kWp per day, 4 when zero.
+	DailyYield float64 `toml:"daily_yield"`
+	// SystemCost is a decimal LKR amount such as "1500000".
+	SystemCost string `toml:"system_cost"`
+	// InstalledOn is a date such as "2024-06-15" from which generation
+	// income counts towards payback.
+	InstalledOn string `toml:"installed_on"`
+	// DropThreshold is the fall in daily exports, e.g. 0.5 for half, that
+	// flags a possible inverter fault. DropWindow is how many earlier
+	// months it is compared with, 3 when zero.
+	DropThreshold float64 `toml:"drop_threshold"`
+	DropWindow    int     `toml:"drop_window"`
+	// SummarySheet is the tab the summary is written to, replacing its
+	// contents.
+	SummarySheet SheetConfig `toml:"summary_sheet"`
+}
+
+// NotifyConfig configures outbound notifications. It is disabled when there
+// are no rules.
+type NotifyConfig struct {
+	// Notifiers are named notifier instances referenced by rules.
+	Notifiers map[string]NotifierConfig `toml:"notifiers"`
+	Rules     []NotifyRule              `toml:"rules"`
+}
+
+// NotifierConfig configures one notifier. Type selects which fields apply.
+type NotifierConfig struct {
+	// Type is "webhook", "telegram" or "email".
+	Type string `toml:"type"`
+
+	// Webhook.
+	URL     string            `toml:"url"`
+	Headers map[string]string `toml:"headers"`
+
+	// Telegram.
+	Token   string `toml:"token"`
+	ChatID  string `toml:"chat_id"`
+	BaseURL string `toml:"base_url"`
+
+	// Email.
+	Addr     string   `toml:"addr"`
+	From     string   `toml:"from"`
+	To       []string `toml:"to"`
+	Username string   `toml:"username"`
+	Password string   `toml:"password"`
+}
+
+// NotifyRule sends events of one type, optionally filtered, to notifiers.
+type NotifyRule struct {
+	// Event is an event type such as "transaction.recorded" or
+	// "bill.mismatch".
+	Event     string   `toml:"event"`
+	Notifiers []string `toml:"notifiers"`
+
+	Institution string `toml:"institution"`
+	Account     string `toml:"account"`
+	Channel     string `toml:"channel"`
+	Direction   string `toml:"direction"`
+	Status      string `toml:"status"`
+	// MinAmount is a decimal amount such as "10000.00" that transactions, and
+	// bills by total payable, must reach.
+	MinAmount string `toml:"min_amount"`
+	// Currency of MinAmount, LKR when empty.
+	Currency string `toml:"currency"`
+}
+
+// ServerConfig configures the "serve" command.
+type ServerConfig struct {
+	// Addr is the listen address, e.g. ":8080".
+	Addr string `toml:"addr"`
+	// APIKey is required in the X-API-Key header when set. The
+	// AUTO_FINANCE_API_KEY environment variable takes precedence.
+	APIKey string `toml:"api_key"`
+}
+
 func LoadConfig(storage storage.ConfigStorage) (*Config, error) {
 	var config Config
 
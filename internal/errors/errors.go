@@ -50,6 +50,28 @@ func NewRetryableError(err error, errorType ErrorType, retryAfter time.Duration,
 	}
 }
 
+// TypedError attaches an ErrorType to an error that should not be retried
+type TypedError struct {
+	Err  error
+	Type ErrorType
+}
+
+func (e *TypedError) Error() string {
+	return fmt.Sprintf("%s error: %v", e.Type, e.Err)
+}
+
+func (e *TypedError) Unwrap() error {
+	return e.Err
+}
+
+// NewTypedError creates a new non-retryable error of the given type
+func NewTypedError(err error, errorType ErrorType) *TypedError {
+	return &TypedError{
+		Err:  err,
+		Type: errorType,
+	}
+}
+
 // IsRetryable checks if an error is retryable
 func IsRetryable(err error) bool {
 	var retryableErr *RetryableError
@@ -71,6 +93,10 @@ func ErrorTypeOf(err error) ErrorType {
 	if errors.As(err, &retryableErr) {
 		return retryableErr.Type
 	}
+	var typedErr *TypedError
+	if errors.As(err, &typedErr) {
+		return typedErr.Type
+	}
 	return ErrorTypeInternal
 }
 
@@ -2,6 +2,7 @@ package errors
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -90,12 +91,28 @@ func TestErrorTypeOf(t *testing.T) {
 		assert.Equal(t, ErrorTypeInternal, errorType)
 	})
 
+	t.Run("with typed error", func(t *testing.T) {
+		typedErr := fmt.Errorf("wrapped: %w", NewTypedError(errors.New("bad sms"), ErrorTypeParser))
+
+		errorType := ErrorTypeOf(typedErr)
+		assert.Equal(t, ErrorTypeParser, errorType)
+		assert.False(t, IsRetryable(typedErr))
+	})
+
 	t.Run("with nil error", func(t *testing.T) {
 		errorType := ErrorTypeOf(nil)
 		assert.Equal(t, ErrorTypeInternal, errorType)
 	})
 }
 
+func TestTypedError_Unwrap(t *testing.T) {
+	originalErr := errors.New("original error")
+	typedErr := NewTypedError(originalErr, ErrorTypeValidation)
+
+	assert.Equal(t, "validation error: original error", typedErr.Error())
+	assert.ErrorIs(t, typedErr, originalErr)
+}
+
 func TestRetryWithBackoff(t *testing.T) {
 	t.Run("success on first attempt", func(t *testing.T) {
 		attempts := 0
@@ -0,0 +1,118 @@
+// Package events carries domain events, such as a budget threshold being
+// crossed, from the services that raise them to whoever subscribes.
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"auto-finance/internal/models"
+)
+
+// Type identifies what happened.
+type Type string
+
+const (
+	// TypeTransactionRecorded is raised after a bank transaction is saved.
+	// Data is a *finance.Transaction.
+	TypeTransactionRecorded Type = "transaction.recorded"
+	// TypeBillRecorded is raised after a utility bill is saved. Data is a
+	// *ebill.ElectricityBill or a *waterbill.WaterBill.
+	TypeBillRecorded Type = "bill.recorded"
+	// TypeBillMismatch is raised when a saved bill's charges differ from the
+	// tariff. Data is a *tariff.Verification.
+	TypeBillMismatch Type = "bill.mismatch"
+	// TypeBudgetThreshold is raised when month-to-date spending crosses a
+	// budget threshold. Data is a budget.Alert.
+	TypeBudgetThreshold Type = "budget.threshold"
+	// TypeBalanceDiscrepancy is raised when a reported balance does not match
+	// the expected balance. Data is a *finance.Discrepancy.
+	TypeBalanceDiscrepancy Type = "balance.discrepancy"
+	// TypeSubscriptionAlert is raised when a subscription appears, changes
+	// price or misses a charge. Data is a subscription.Alert.
+	TypeSubscriptionAlert Type = "subscription.alert"
+)
+
+// Event is something that happened while processing a message.
+type Event struct {
+	Type Type
+	Time time.Time
+	Data interface{}
+}
+
+// Field names of the values event data can be filtered on.
+const (
+	FieldInstitution = "institution"
+	FieldAccount     = "account"
+	FieldChannel     = "channel"
+	FieldDirection   = "direction"
+	FieldStatus      = "status"
+)
+
+// Describer is implemented by event data so that subscribers can show and
+// filter events without knowing every data type.
+type Describer interface {
+	// Summary returns a short subject and a one line text for people.
+	Summary() (subject, text string)
+	// Fields returns the values the data can be filtered on.
+	Fields() Fields
+}
+
+// Fields are the filterable values of event data. Filters on values the data
+// does not have are ignored.
+type Fields struct {
+	// Values are keyed by field name, such as FieldAccount.
+	Values map[string]string
+	// Amounts are the data's amount and any conversion of it. A minimum
+	// amount is compared with the one in its currency. Empty when the data
+	// has no amount.
+	Amounts []models.Money
+}
+
+// Publisher delivers events.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// Handler receives published events.
+type Handler func(ctx context.Context, event Event) error
+
+// Bus is an in-process Publisher that hands every event to each subscriber
+// in turn.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers h for every event published after the call.
+func (b *Bus) Subscribe(h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, h)
+}
+
+// Publish calls every subscriber, even when an earlier one fails, and
+// returns their errors joined.
+func (b *Bus) Publish(ctx context.Context, event Event) error {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	b.mu.RLock()
+	handlers := append([]Handler(nil), b.handlers...)
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, h := range handlers {
+		if err := h(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
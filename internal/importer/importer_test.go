@@ -0,0 +1,100 @@
+package importer_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"auto-finance/internal/importer"
+	"auto-finance/internal/service/idempotency"
+	"auto-finance/internal/service/message"
+	idempotencyStorage "auto-finance/internal/storage/idempotency"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const backupXML = `<?xml version='1.0' encoding='UTF-8' standalone='yes' ?>
+<smses count="4">
+  <sms protocol="0" address="HNB" date="1699366930000" type="1" body="second &amp; later" read="1" />
+  <sms protocol="0" address="+94771234567" date="1699360000000" type="1" body="hi" read="1" />
+  <sms protocol="0" address="HNB" date="1699360930000" type="1" body="first&#10;line two" read="1" />
+  <sms protocol="0" address="HNB" date="1699360990000" type="2" body="sent by me" read="1" />
+  <mms date="1699360000000" />
+</smses>`
+
+func TestReadBackupXML(t *testing.T) {
+	colombo, err := time.LoadLocation("Asia/Colombo")
+	require.NoError(t, err)
+
+	messages, err := importer.ReadBackupXML(strings.NewReader(backupXML), colombo)
+	require.NoError(t, err)
+	require.Len(t, messages, 3)
+
+	assert.Equal(t, "HNB", messages[0].Sender)
+	assert.Equal(t, "second & later", messages[0].Body)
+	assert.Equal(t, "first\nline two", messages[2].Body)
+	assert.Equal(t, "2023-11-07 18:12:10", messages[2].ReceivedAt.Format(time.DateTime))
+}
+
+func TestReadCSV(t *testing.T) {
+	input := "Sender,Date,Body\n" +
+		"SAMPATH,1699361730000,\"Cr Crd, LKR 1.00\"\n" +
+		"HNB,2023-11-07 12:55:30,hello\n" +
+		"LECO,2023-11-07T12:55:30Z,bill\n"
+
+	messages, err := importer.ReadCSV(strings.NewReader(input), time.UTC)
+	require.NoError(t, err)
+	require.Len(t, messages, 3)
+
+	assert.Equal(t, "Cr Crd, LKR 1.00", messages[0].Body)
+	for _, m := range messages {
+		assert.Equal(t, "2023-11-07 12:55:30", m.ReceivedAt.UTC().Format(time.DateTime), m.Sender)
+	}
+
+	_, err = importer.ReadCSV(strings.NewReader("a,b\n1,2\n"), time.UTC)
+	assert.Error(t, err)
+}
+
+type recordingService struct {
+	got []message.Message
+}
+
+func (r *recordingService) PassMessage(_ context.Context, msg message.Message) (message.Result, error) {
+	r.got = append(r.got, msg)
+	return message.Result{}, nil
+}
+
+func TestImporter_RunIsRepeatable(t *testing.T) {
+	messages, err := importer.ReadBackupXML(strings.NewReader(backupXML), time.UTC)
+	require.NoError(t, err)
+
+	statePath := filepath.Join(t.TempDir(), "import-state.json")
+	next := &recordingService{}
+
+	run := func() importer.Report {
+		imp := importer.New(&importer.Config{
+			Logger: zerolog.Nop(),
+			Service: idempotency.New(&idempotency.Config{
+				Logger:  zerolog.Nop(),
+				Next:    next,
+				Storage: idempotencyStorage.NewFile(statePath),
+			}),
+			Senders: []string{"hnb"},
+		})
+		report, err := imp.Run(context.Background(), messages)
+		require.NoError(t, err)
+		return report
+	}
+
+	assert.Equal(t, importer.Report{Total: 3, Skipped: 1, Imported: 2}, run())
+	require.Len(t, next.got, 2)
+	assert.Equal(t, "first\nline two", next.got[0].Body)
+	assert.Equal(t, "second & later", next.got[1].Body)
+
+	assert.Equal(t, importer.Report{Total: 3, Skipped: 1, Duplicates: 2}, run())
+	assert.Len(t, next.got, 2)
+}
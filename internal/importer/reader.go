@@ -0,0 +1,146 @@
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Message is one SMS read from an export.
+type Message struct {
+	Sender     string
+	Body       string
+	ReceivedAt time.Time
+}
+
+// smsTypeInbox is the SMS Backup & Restore "type" of received messages.
+// Sent messages, drafts and the rest are skipped.
+const smsTypeInbox = "1"
+
+// ReadBackupXML reads the received SMS from an Android "SMS Backup & Restore"
+// XML export. The "date" attribute (Unix milliseconds) becomes ReceivedAt in
+// loc. MMS entries are ignored.
+func ReadBackupXML(r io.Reader, loc *time.Location) ([]Message, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	var messages []Message
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return messages, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup XML: %w", err)
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "sms" {
+			continue
+		}
+
+		attrs := make(map[string]string, len(start.Attr))
+		for _, a := range start.Attr {
+			attrs[a.Name.Local] = a.Value
+		}
+
+		if t, ok := attrs["type"]; ok && t != smsTypeInbox {
+			continue
+		}
+
+		ms, err := strconv.ParseInt(attrs["date"], 10, 64)
+		if err != nil {
+			line, _ := dec.InputPos()
+			return nil, fmt.Errorf("line %d: invalid sms date %q", line, attrs["date"])
+		}
+
+		messages = append(messages, Message{
+			Sender:     attrs["address"],
+			Body:       attrs["body"],
+			ReceivedAt: time.UnixMilli(ms).In(loc),
+		})
+	}
+}
+
+// ReadCSV reads messages from a CSV file with a header row. The sender column
+// may be named address, sender or from; the time column date, received_at or
+// time; the body column body or message. Times are Unix milliseconds, RFC 3339
+// or "2006-01-02 15:04:05" in loc.
+func ReadCSV(r io.Reader, loc *time.Location) ([]Message, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	sender, date, body := -1, -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "address", "sender", "from":
+			sender = i
+		case "date", "received_at", "time":
+			date = i
+		case "body", "message":
+			body = i
+		}
+	}
+	if sender < 0 || date < 0 || body < 0 {
+		return nil, fmt.Errorf("CSV header needs sender, date and body columns, got %v", header)
+	}
+
+	var messages []Message
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return messages, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		line, _ := cr.FieldPos(0)
+		if len(record) <= max(sender, date, body) {
+			return nil, fmt.Errorf("line %d: expected at least %d fields", line, max(sender, date, body)+1)
+		}
+
+		receivedAt, err := parseTime(record[date], loc)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		messages = append(messages, Message{
+			Sender:     record[sender],
+			Body:       record[body],
+			ReceivedAt: receivedAt,
+		})
+	}
+}
+
+func parseTime(raw string, loc *time.Location) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+
+	if ms, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.UnixMilli(ms).In(loc), nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.In(loc), nil
+	}
+	if t, err := time.ParseInLocation(time.DateTime, raw, loc); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date %q", raw)
+}
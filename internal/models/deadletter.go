@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeadLetter is a message that could not be parsed or stored, kept so it can
+// be replayed once the cause is fixed.
+type DeadLetter struct {
+	// MessageID is the raw message audit log ID and identifies the entry.
+	MessageID  uuid.UUID `json:"message_id"`
+	Sender     string    `json:"sender"`
+	Body       string    `json:"body"`
+	ReceivedAt time.Time `json:"received_at"`
+	ExternalID string    `json:"external_id,omitempty"`
+	ErrorType  string    `json:"error_type"`
+	Error      string    `json:"error"`
+	Attempts   int       `json:"attempts"`
+	FailedAt   time.Time `json:"failed_at"`
+}
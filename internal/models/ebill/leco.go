@@ -1,27 +1,76 @@
 package ebill
 
-import "time"
+import (
+	"fmt"
+	"time"
+
+	"auto-finance/internal/events"
+	"auto-finance/internal/models"
+)
+
+// Providers of electricity bills.
+const (
+	ProviderLECO = "LECO"
+	ProviderCEB  = "CEB"
+)
 
 type ElectricityBill struct {
-	AccountNumber      string    `json:"accountNumber"`
-	AccountType        string    `json:"accountType"`
-	AccountName        string    `json:"accountName"`
-	ReadOn             time.Time `json:"readOn"`
-	ImportPrevious     int       `json:"importPrevious"`
-	ImportCurrent      int       `json:"importCurrent"`
-	ImportUnits        int       `json:"importUnits"`
-	ExportPrevious     int       `json:"exportPrevious"`
-	ExportCurrent      int       `json:"exportCurrent"`
-	ExportUnits        int       `json:"exportUnits"`
-	NetUnits           int       `json:"netUnits"`
-	NetUnitsType       string    `json:"netUnitsType"`
-	MonthlyBill        float64   `json:"monthlyBill"`
-	OtherCharges       float64   `json:"otherCharges"`
-	SSCL               float64   `json:"sscl"`
-	OpeningBalance     float64   `json:"openingBalance"`
-	OpeningBalanceDate time.Time `json:"openingBalanceDate"`
-	TotalPayable       float64   `json:"totalPayable"`
-	LastPaymentAmount  float64   `json:"lastPaymentAmount"`
-	LastPaymentDate    time.Time `json:"lastPaymentDate"`
-	LastGenPayment     float64   `json:"lastGenPayment"`
+	// Provider is the utility that issued the bill, ProviderLECO or
+	// ProviderCEB.
+	Provider           string       `json:"provider"`
+	AccountNumber      string       `json:"accountNumber"`
+	AccountType        string       `json:"accountType"`
+	AccountName        string       `json:"accountName"`
+	ReadOn             time.Time    `json:"readOn"`
+	ImportPrevious     int          `json:"importPrevious"`
+	ImportCurrent      int          `json:"importCurrent"`
+	ImportUnits        int          `json:"importUnits"`
+	ExportPrevious     int          `json:"exportPrevious"`
+	ExportCurrent      int          `json:"exportCurrent"`
+	ExportUnits        int          `json:"exportUnits"`
+	NetUnits           int          `json:"netUnits"`
+	NetUnitsType       string       `json:"netUnitsType"`
+	MonthlyBill        models.Money `json:"monthlyBill"`
+	OtherCharges       models.Money `json:"otherCharges"`
+	SSCL               models.Money `json:"sscl"`
+	OpeningBalance     models.Money `json:"openingBalance"`
+	OpeningBalanceDate time.Time    `json:"openingBalanceDate"`
+	TotalPayable       models.Money `json:"totalPayable"`
+	LastPaymentAmount  models.Money `json:"lastPaymentAmount"`
+	LastPaymentDate    time.Time    `json:"lastPaymentDate"`
+	LastGenPayment     models.Money `json:"lastGenPayment"`
+	DueDate            time.Time    `json:"dueDate"`
+	Adjustments        models.Money `json:"adjustments"`
+	// GenerationUnits and GenerationAmount are this month's solar export
+	// units and the amount credited for them.
+	GenerationUnits  int          `json:"generationUnits"`
+	GenerationAmount models.Money `json:"generationAmount"`
+	// Extras keeps "key: value" lines the parser does not know, keyed as
+	// printed, so new bill fields are not lost.
+	Extras map[string]string `json:"extras,omitempty"`
+}
+
+// ProviderName is the utility that issued the bill. Bills saved before
+// providers were recorded are LECO's.
+func (b *ElectricityBill) ProviderName() string {
+	if b.Provider == "" {
+		return ProviderLECO
+	}
+	return b.Provider
+}
+
+// Summary implements events.Describer.
+func (b *ElectricityBill) Summary() (subject, text string) {
+	subject = fmt.Sprintf("%s bill %s", b.ProviderName(), b.TotalPayable)
+	text = fmt.Sprintf("New %s bill for %s (%s): %d units, total payable %s",
+		b.ProviderName(), b.AccountName, b.AccountNumber, b.ImportUnits, b.TotalPayable)
+	if !b.DueDate.IsZero() {
+		text += fmt.Sprintf(", due %s", b.DueDate.Format(time.DateOnly))
+	}
+	return subject, text
+}
+
+// Fields implements events.Describer. Bills are filtered by total payable.
+func (b *ElectricityBill) Fields() events.Fields {
+	return events.Fields{Amounts: []models.Money{b.TotalPayable}}
 }
@@ -0,0 +1,39 @@
+package ebill
+
+import (
+	"time"
+
+	"auto-finance/internal/models"
+)
+
+// SolarMonth summarizes one bill of an account with rooftop solar.
+type SolarMonth struct {
+	AccountNumber string    `json:"accountNumber"`
+	ReadOn        time.Time `json:"readOn"`
+	// Days is the length of the billing period.
+	Days        int `json:"days"`
+	ImportUnits int `json:"importUnits"`
+	ExportUnits int `json:"exportUnits"`
+	// EstimatedGeneration is the expected output of the system over the
+	// period, 0 when the system size is not configured. SelfConsumption
+	// and the ratios depend on it.
+	EstimatedGeneration  int     `json:"estimatedGeneration"`
+	SelfConsumption      int     `json:"selfConsumption"`
+	SelfConsumptionRatio float64 `json:"selfConsumptionRatio"`
+	ExportRatio          float64 `json:"exportRatio"`
+
+	GenerationIncome models.Money `json:"generationIncome"`
+	ImportCost       models.Money `json:"importCost"`
+	// Net is GenerationIncome less ImportCost.
+	Net models.Money `json:"net"`
+	// YearToDate totals run from the first bill read in the calendar year.
+	YearToDateIncome     models.Money `json:"yearToDateIncome"`
+	YearToDateImportCost models.Money `json:"yearToDateImportCost"`
+	// CumulativeIncome is the generation income since installation and
+	// PaybackProgress its fraction of the system cost, 0 when unknown.
+	CumulativeIncome models.Money `json:"cumulativeIncome"`
+	PaybackProgress  float64      `json:"paybackProgress"`
+	// ExportDrop flags a sharp fall in exports against earlier months,
+	// which can mean an inverter fault.
+	ExportDrop bool `json:"exportDrop"`
+}
@@ -0,0 +1,51 @@
+package finance
+
+import (
+	"fmt"
+	"time"
+
+	"auto-finance/internal/events"
+	"auto-finance/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Balance is the expected balance of an account: the balance last reported
+// by an alert, moved on by the transactions recorded since.
+type Balance struct {
+	Expected models.Money `json:"expected"`
+	// At is when the last applied transaction occurred.
+	At time.Time `json:"at"`
+	// CheckpointAt is when an alert last reported the balance.
+	CheckpointAt time.Time `json:"checkpoint_at"`
+}
+
+// Discrepancy is a reported balance that does not match the expected
+// balance, typically because an SMS was missed or a fee was not alerted.
+type Discrepancy struct {
+	Institution string       `json:"institution"`
+	Account     string       `json:"account"`
+	Expected    models.Money `json:"expected"`
+	Reported    models.Money `json:"reported"`
+	// Difference is Reported minus Expected; negative means money left the
+	// account without an alert.
+	Difference      models.Money `json:"difference"`
+	CheckpointAt    time.Time    `json:"checkpoint_at"`
+	OccurredAt      time.Time    `json:"occurred_at"`
+	SourceMessageID uuid.UUID    `json:"source_message_id,omitzero"`
+}
+
+// Summary implements events.Describer.
+func (d *Discrepancy) Summary() (subject, text string) {
+	return fmt.Sprintf("Balance mismatch on %s %s", d.Institution, d.Account),
+		fmt.Sprintf("%s %s reported %s but %s was expected (difference %s). A message may have been missed since %s.",
+			d.Institution, d.Account, d.Reported, d.Expected, d.Difference, d.CheckpointAt.Format(time.DateTime))
+}
+
+// Fields implements events.Describer.
+func (d *Discrepancy) Fields() events.Fields {
+	return events.Fields{Values: map[string]string{
+		events.FieldInstitution: d.Institution,
+		events.FieldAccount:     d.Account,
+	}}
+}
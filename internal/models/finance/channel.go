@@ -0,0 +1,11 @@
+package finance
+
+// TransactionType is the channel a transaction went through.
+type TransactionType string
+
+const (
+	TransactionTypeCard   TransactionType = "Card"
+	TransactionTypeOnline TransactionType = "Online"
+	TransactionTypeATM    TransactionType = "ATM"
+	TransactionTypeCEFT   TransactionType = "CEFT"
+)
@@ -0,0 +1,34 @@
+package finance
+
+import (
+	"time"
+
+	"auto-finance/internal/models"
+)
+
+// Cadence is how often a recurring payment is charged.
+type Cadence string
+
+const (
+	CadenceMonthly Cadence = "monthly"
+	CadenceAnnual  Cadence = "annual"
+)
+
+// Subscription is a recurring charge detected from stored transactions.
+type Subscription struct {
+	// Key identifies the subscription across detection runs.
+	Key         string  `json:"key"`
+	Institution string  `json:"institution"`
+	Account     string  `json:"account"`
+	Merchant    string  `json:"merchant"`
+	Cadence     Cadence `json:"cadence"`
+	// Amount is the latest charge in its original currency.
+	Amount         models.Money `json:"amount"`
+	Charges        int          `json:"charges"`
+	FirstChargedAt time.Time    `json:"first_charged_at"`
+	LastChargedAt  time.Time    `json:"last_charged_at"`
+	NextExpectedAt time.Time    `json:"next_expected_at"`
+	// Missed is set once an alert has been raised for a charge that did not
+	// arrive, and cleared by the next charge.
+	Missed bool `json:"missed,omitempty"`
+}
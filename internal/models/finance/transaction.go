@@ -0,0 +1,160 @@
+package finance
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"auto-finance/internal/events"
+	"auto-finance/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Institutions known to the bank parsers.
+const (
+	InstitutionSampath = "Sampath"
+	InstitutionHNB     = "HNB"
+)
+
+// Direction is whether money left or entered the account.
+type Direction string
+
+const (
+	DirectionDebit  Direction = "debit"
+	DirectionCredit Direction = "credit"
+)
+
+// Transaction statuses. Authorization, reversal and decline come from card
+// alerts; account alerts are plain debits and credits.
+const (
+	StatusAuthorized = "authorized"
+	StatusReversed   = "reversed"
+	StatusDeclined   = "decline"
+	StatusDebit      = "debit"
+	StatusCredit     = "credit"
+)
+
+// Extension keys set by the bank parsers.
+const (
+	// ExtensionReference is the bank's transfer reference.
+	ExtensionReference = "reference"
+	// ExtensionReversalOf is the source message ID of the authorization a
+	// reversal cancels, set by the transaction service.
+	ExtensionReversalOf = "reversal_of"
+)
+
+// Transaction is a bank-agnostic account or card movement parsed from an SMS.
+type Transaction struct {
+	Institution string `json:"institution"`
+	// Account is the masked account or card number as printed in the alert,
+	// usually the last digits.
+	Account      string          `json:"account"`
+	Direction    Direction       `json:"direction"`
+	Channel      TransactionType `json:"channel"`
+	Amount       models.Money    `json:"amount"`
+	Counterparty string          `json:"counterparty"`
+	// BalanceAfter is the balance or available credit limit quoted in the
+	// alert, zero when absent.
+	BalanceAfter models.Money `json:"balance_after,omitzero"`
+	Status       string       `json:"status,omitempty"`
+	// SourceMessageID is the raw message audit log ID, set by the message
+	// service.
+	SourceMessageID uuid.UUID `json:"source_message_id,omitzero"`
+	OccurredAt      time.Time `json:"occurred_at"`
+	// Merchant and Category are the normalized counterparty and its spending
+	// category, filled in by the categorizer before the transaction is saved.
+	Merchant string `json:"merchant,omitempty"`
+	Category string `json:"category,omitempty"`
+	// Converted is Amount in the configured base currency and FXRate the
+	// number of base currency units per unit of Amount's currency that was
+	// used, both set by the FX converter.
+	Converted models.Money `json:"converted,omitzero"`
+	FXRate    string       `json:"fx_rate,omitempty"`
+	// Extensions carries bank specific details that have no common field.
+	Extensions map[string]string `json:"extensions,omitempty"`
+}
+
+// SetExtension records a bank specific detail, ignoring empty values.
+func (t *Transaction) SetExtension(key, value string) {
+	if value == "" {
+		return
+	}
+	if t.Extensions == nil {
+		t.Extensions = make(map[string]string)
+	}
+	t.Extensions[key] = value
+}
+
+// Validate checks the fields every bank parser must fill in.
+func (t *Transaction) Validate() error {
+	switch {
+	case t.Institution == "":
+		return errors.New("institution is required")
+	case t.Account == "":
+		return errors.New("account is required")
+	case t.Direction != DirectionDebit && t.Direction != DirectionCredit:
+		return fmt.Errorf("invalid direction %q", t.Direction)
+	case t.Amount.Currency == "":
+		return errors.New("amount currency is required")
+	case t.Amount.Minor < 0:
+		return fmt.Errorf("negative amount %s", t.Amount)
+	case t.OccurredAt.IsZero():
+		return errors.New("occurred at is required")
+	}
+	return nil
+}
+
+// SetSourceMessageID links the transaction to the raw message it came from.
+func (t *Transaction) SetSourceMessageID(id uuid.UUID) {
+	t.SourceMessageID = id
+}
+
+// Summary implements events.Describer.
+func (t *Transaction) Summary() (subject, text string) {
+	action := "Spent"
+	switch {
+	case t.Status == StatusDeclined:
+		action = "Declined"
+	case t.Status == StatusReversed:
+		action = "Reversed"
+	case t.Direction == DirectionCredit:
+		action = "Received"
+	}
+	counterparty := t.Merchant
+	if counterparty == "" {
+		counterparty = t.Counterparty
+	}
+
+	subject = fmt.Sprintf("%s %s", action, t.Amount)
+	text = subject
+	if counterparty != "" {
+		text += " at " + counterparty
+	}
+	text += fmt.Sprintf(" (%s %s %s)", t.Institution, t.Channel, t.Account)
+	if !t.BalanceAfter.IsZero() {
+		text += fmt.Sprintf(". Available %s", t.BalanceAfter)
+	}
+	if t.Category != "" {
+		text += fmt.Sprintf(". Category: %s", t.Category)
+	}
+	return subject, text
+}
+
+// Fields implements events.Describer.
+func (t *Transaction) Fields() events.Fields {
+	fields := events.Fields{
+		Values: map[string]string{
+			events.FieldInstitution: t.Institution,
+			events.FieldAccount:     t.Account,
+			events.FieldChannel:     string(t.Channel),
+			events.FieldDirection:   string(t.Direction),
+			events.FieldStatus:      t.Status,
+		},
+		Amounts: []models.Money{t.Amount},
+	}
+	if !t.Converted.IsZero() {
+		fields.Amounts = append(fields.Amounts, t.Converted)
+	}
+	return fields
+}
@@ -6,9 +6,20 @@ import (
 	"github.com/google/uuid"
 )
 
+// Outcome values recorded against a raw message.
+const (
+	OutcomeProcessed = "processed"
+	OutcomeDuplicate = "duplicate"
+	OutcomeRejected  = "rejected"
+	OutcomeFailed    = "failed"
+)
+
 type Message struct {
 	ID      uuid.UUID
 	From    string    `json:"from"`
 	Message string    `json:"message"`
 	Time    time.Time `json:"time"`
+	Outcome string    `json:"outcome,omitempty"`
+	Parser  string    `json:"parser,omitempty"`
+	Error   string    `json:"error,omitempty"`
 }
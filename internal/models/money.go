@@ -0,0 +1,255 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidMoney     = errors.New("invalid money amount")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+)
+
+// minorDigits lists ISO 4217 currencies whose minor unit is not 1/100.
+var minorDigits = map[string]int{
+	"BHD": 3,
+	"IQD": 3,
+	"JOD": 3,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"LYD": 3,
+	"OMR": 3,
+	"TND": 3,
+	"VND": 0,
+}
+
+// MinorDigits returns the number of decimal places used by currency.
+func MinorDigits(currency string) int {
+	if d, ok := minorDigits[strings.ToUpper(currency)]; ok {
+		return d
+	}
+	return 2
+}
+
+// Money is a fixed-point amount in the minor unit of an ISO 4217 currency,
+// e.g. {Minor: 125050, Currency: "LKR"} is LKR 1,250.50.
+type Money struct {
+	Minor    int64
+	Currency string
+}
+
+// NewMoney creates an amount from minor units
+func NewMoney(minor int64, currency string) Money {
+	return Money{Minor: minor, Currency: strings.ToUpper(currency)}
+}
+
+// RoundMoney rounds minor, an amount in minor units, half away from zero.
+func RoundMoney(minor *big.Rat, currency string) Money {
+	num, denom := minor.Num(), minor.Denom()
+	quo, rem := new(big.Int).QuoRem(num, denom, new(big.Int))
+	// |rem| * 2 >= denom rounds away from zero.
+	if new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(denom) >= 0 {
+		if num.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+
+	return NewMoney(quo.Int64(), currency)
+}
+
+// ParseMoney parses a decimal amount as printed in SMS alerts, e.g.
+// "1,250.50", ".00" or "-12.5". More decimal places than the currency
+// supports are accepted only when they are zeros.
+func ParseMoney(amount, currency string) (Money, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	raw := strings.ReplaceAll(strings.TrimSpace(amount), ",", "")
+
+	negative := false
+	switch {
+	case strings.HasPrefix(raw, "-"):
+		negative = true
+		raw = raw[1:]
+	case strings.HasPrefix(raw, "+"):
+		raw = raw[1:]
+	}
+
+	whole, frac, _ := strings.Cut(raw, ".")
+	if whole == "" && frac == "" {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, amount)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	if !isDigits(whole) || !isDigits(frac) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, amount)
+	}
+
+	digits := MinorDigits(currency)
+	if len(frac) > digits {
+		if strings.Trim(frac[digits:], "0") != "" {
+			return Money{}, fmt.Errorf("%w: %q has more than %d decimal places", ErrInvalidMoney, amount, digits)
+		}
+		frac = frac[:digits]
+	}
+	frac += strings.Repeat("0", digits-len(frac))
+
+	minor, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, amount)
+	}
+	if negative {
+		minor = -minor
+	}
+
+	return Money{Minor: minor, Currency: currency}, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// IsZero reports whether m is the zero value, i.e. no amount was set
+func (m Money) IsZero() bool {
+	return m.Minor == 0 && m.Currency == ""
+}
+
+// Decimal formats the amount without grouping or currency, e.g. "-1250.50".
+// This is what the sheets receive so that they are parsed as numbers.
+func (m Money) Decimal() string {
+	digits := MinorDigits(m.Currency)
+
+	minor := m.Minor
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+
+	s := strconv.FormatInt(minor, 10)
+	if digits == 0 {
+		return sign + s
+	}
+	if len(s) <= digits {
+		s = strings.Repeat("0", digits-len(s)+1) + s
+	}
+
+	return sign + s[:len(s)-digits] + "." + s[len(s)-digits:]
+}
+
+// String formats the amount for people, e.g. "LKR 1,250.50"
+func (m Money) String() string {
+	dec := m.Decimal()
+
+	sign := ""
+	if strings.HasPrefix(dec, "-") {
+		sign = "-"
+		dec = dec[1:]
+	}
+
+	whole, frac, hasFrac := strings.Cut(dec, ".")
+	var b strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	if hasFrac {
+		b.WriteByte('.')
+		b.WriteString(frac)
+	}
+
+	if m.Currency == "" {
+		return sign + b.String()
+	}
+	return m.Currency + " " + sign + b.String()
+}
+
+// Add returns m + o. Both amounts must be in the same currency.
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	return Money{Minor: m.Minor + o.Minor, Currency: m.currencyWith(o)}, nil
+}
+
+// Sub returns m - o. Both amounts must be in the same currency.
+func (m Money) Sub(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	return Money{Minor: m.Minor - o.Minor, Currency: m.currencyWith(o)}, nil
+}
+
+// Neg returns -m
+func (m Money) Neg() Money {
+	return Money{Minor: -m.Minor, Currency: m.Currency}
+}
+
+// Cmp returns -1, 0 or 1 when m is less than, equal to or greater than o.
+func (m Money) Cmp(o Money) (int, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.Minor < o.Minor:
+		return -1, nil
+	case m.Minor > o.Minor:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// sameCurrency allows the zero value to combine with any currency so that
+// sums can start from Money{}.
+func (m Money) sameCurrency(o Money) error {
+	if m.Currency != "" && o.Currency != "" && m.Currency != o.Currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	return nil
+}
+
+func (m Money) currencyWith(o Money) string {
+	if m.Currency != "" {
+		return m.Currency
+	}
+	return o.Currency
+}
+
+type moneyJSON struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// MarshalJSON encodes m as {"amount":"1250.50","currency":"LKR"}. The amount
+// is a string so that no consumer reads it back through a float.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: m.Decimal(), Currency: m.Currency})
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var v moneyJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	parsed, err := ParseMoney(v.Amount, v.Currency)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
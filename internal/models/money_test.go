@@ -0,0 +1,99 @@
+package models_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"auto-finance/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		want     models.Money
+		wantErr  bool
+	}{
+		{amount: "1,250.50", currency: "lkr", want: models.NewMoney(125050, "LKR")},
+		{amount: ".00", currency: "LKR", want: models.NewMoney(0, "LKR")},
+		{amount: "12.5", currency: "USD", want: models.NewMoney(1250, "USD")},
+		{amount: "-1,000", currency: "LKR", want: models.NewMoney(-100000, "LKR")},
+		{amount: "1000.000", currency: "LKR", want: models.NewMoney(100000, "LKR")},
+		{amount: "1500", currency: "JPY", want: models.NewMoney(1500, "JPY")},
+		{amount: "1.234", currency: "KWD", want: models.NewMoney(1234, "KWD")},
+		{amount: "1.005", currency: "LKR", wantErr: true},
+		{amount: "", currency: "LKR", wantErr: true},
+		{amount: "1.2.3", currency: "LKR", wantErr: true},
+		{amount: "abc", currency: "LKR", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.amount+" "+tt.currency, func(t *testing.T) {
+			got, err := models.ParseMoney(tt.amount, tt.currency)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, models.ErrInvalidMoney)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMoney_Format(t *testing.T) {
+	assert.Equal(t, "1250.50", models.NewMoney(125050, "LKR").Decimal())
+	assert.Equal(t, "-0.05", models.NewMoney(-5, "LKR").Decimal())
+	assert.Equal(t, "1500", models.NewMoney(1500, "JPY").Decimal())
+	assert.Equal(t, "LKR 1,234,567.89", models.NewMoney(123456789, "LKR").String())
+	assert.Equal(t, "USD -999.00", models.NewMoney(-99900, "USD").String())
+}
+
+func TestMoney_Arithmetic(t *testing.T) {
+	// 0.1 + 0.2 is the classic float64 artefact.
+	sum, err := models.Money{}.Add(models.NewMoney(10, "LKR"))
+	require.NoError(t, err)
+	sum, err = sum.Add(models.NewMoney(20, "LKR"))
+	require.NoError(t, err)
+	assert.Equal(t, "0.30", sum.Decimal())
+
+	diff, err := sum.Sub(models.NewMoney(50, "LKR"))
+	require.NoError(t, err)
+	assert.Equal(t, models.NewMoney(-20, "LKR"), diff)
+	assert.Equal(t, models.NewMoney(20, "LKR"), diff.Neg())
+
+	cmp, err := sum.Cmp(diff)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cmp)
+
+	_, err = sum.Add(models.NewMoney(1, "USD"))
+	assert.ErrorIs(t, err, models.ErrCurrencyMismatch)
+}
+
+func TestRoundMoney(t *testing.T) {
+	tests := map[string]int64{
+		"5/2":      3,
+		"-5/2":     -3,
+		"249/100":  2,
+		"-251/100": -3,
+		"7":        7,
+	}
+	for in, want := range tests {
+		minor, ok := new(big.Rat).SetString(in)
+		require.True(t, ok, in)
+		assert.Equal(t, models.NewMoney(want, "LKR"), models.RoundMoney(minor, "lkr"), in)
+	}
+}
+
+func TestMoney_JSON(t *testing.T) {
+	data, err := json.Marshal(models.NewMoney(245000, "LKR"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount":"2450.00","currency":"LKR"}`, string(data))
+
+	var m models.Money
+	require.NoError(t, json.Unmarshal(data, &m))
+	assert.Equal(t, models.NewMoney(245000, "LKR"), m)
+}
@@ -0,0 +1,50 @@
+package waterbill
+
+import (
+	"fmt"
+	"time"
+
+	"auto-finance/internal/events"
+	"auto-finance/internal/models"
+)
+
+// ProviderNWSDB is the National Water Supply & Drainage Board.
+const ProviderNWSDB = "NWSDB"
+
+// WaterBill is a water bill. Meter readings and units are in cubic metres.
+type WaterBill struct {
+	Provider          string       `json:"provider"`
+	AccountNumber     string       `json:"accountNumber"`
+	AccountName       string       `json:"accountName"`
+	Category          string       `json:"category"`
+	ReadOn            time.Time    `json:"readOn"`
+	PreviousReading   int          `json:"previousReading"`
+	CurrentReading    int          `json:"currentReading"`
+	Units             int          `json:"units"`
+	Charges           models.Money `json:"charges"`
+	ServiceCharge     models.Money `json:"serviceCharge"`
+	Arrears           models.Money `json:"arrears"`
+	TotalPayable      models.Money `json:"totalPayable"`
+	LastPaymentAmount models.Money `json:"lastPaymentAmount"`
+	LastPaymentDate   time.Time    `json:"lastPaymentDate"`
+	DueDate           time.Time    `json:"dueDate"`
+	// Extras keeps "key: value" lines the parser does not know, keyed as
+	// printed, so new bill fields are not lost.
+	Extras map[string]string `json:"extras,omitempty"`
+}
+
+// Summary implements events.Describer.
+func (b *WaterBill) Summary() (subject, text string) {
+	subject = fmt.Sprintf("%s water bill %s", b.Provider, b.TotalPayable)
+	text = fmt.Sprintf("New %s water bill for %s: %d m3, total payable %s",
+		b.Provider, b.AccountNumber, b.Units, b.TotalPayable)
+	if !b.DueDate.IsZero() {
+		text += fmt.Sprintf(", due %s", b.DueDate.Format(time.DateOnly))
+	}
+	return subject, text
+}
+
+// Fields implements events.Describer. Bills are filtered by total payable.
+func (b *WaterBill) Fields() events.Fields {
+	return events.Fields{Amounts: []models.Money{b.TotalPayable}}
+}
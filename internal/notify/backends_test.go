@@ -0,0 +1,182 @@
+package notify_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"auto-finance/internal/events"
+	"auto-finance/internal/notify"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testMessage = notify.Message{
+	Subject: "Declined LKR 1,200.00",
+	Text:    "Declined LKR 1,200.00 at MASKED CAFE",
+	Event:   events.Event{Type: events.TypeTransactionRecorded},
+}
+
+func TestWebhook_Notify(t *testing.T) {
+	t.Parallel()
+
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+	}))
+	defer srv.Close()
+
+	w := notify.NewWebhook(&notify.WebhookConfig{URL: srv.URL, Headers: map[string]string{"Authorization": "Bearer secret"}})
+	require.NoError(t, w.Notify(context.Background(), testMessage))
+	assert.Equal(t, string(events.TypeTransactionRecorded), got["event"])
+	assert.Equal(t, testMessage.Text, got["text"])
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "nope", http.StatusBadGateway)
+	}))
+	defer failing.Close()
+
+	w = notify.NewWebhook(&notify.WebhookConfig{URL: failing.URL})
+	assert.ErrorContains(t, w.Notify(context.Background(), testMessage), "502")
+}
+
+func TestTelegram_Notify(t *testing.T) {
+	t.Parallel()
+
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/botTOKEN/sendMessage" {
+			http.Error(w, `{"ok":false,"description":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		if got["chat_id"] == "bad" {
+			_, _ = w.Write([]byte(`{"ok":false,"description":"Bad Request: chat not found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	tg := notify.NewTelegram(&notify.TelegramConfig{Token: "TOKEN", ChatID: "42", BaseURL: srv.URL})
+	require.NoError(t, tg.Notify(context.Background(), testMessage))
+	assert.Equal(t, map[string]string{"chat_id": "42", "text": testMessage.Text}, got)
+
+	tg = notify.NewTelegram(&notify.TelegramConfig{Token: "TOKEN", ChatID: "bad", BaseURL: srv.URL})
+	assert.ErrorContains(t, tg.Notify(context.Background(), testMessage), "chat not found")
+
+	tg = notify.NewTelegram(&notify.TelegramConfig{Token: "WRONG", ChatID: "42", BaseURL: srv.URL})
+	err := tg.Notify(context.Background(), testMessage)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "WRONG", "token is redacted")
+}
+
+func TestEmail_Notify(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	data := make(chan string, 1)
+	go serveSMTP(t, ln, data)
+
+	e := notify.NewEmail(&notify.EmailConfig{
+		Addr: ln.Addr().String(),
+		From: "alerts@example.com",
+		To:   []string{"me@example.com"},
+	})
+	require.NoError(t, e.Notify(context.Background(), testMessage))
+
+	mail := <-data
+	assert.Contains(t, mail, "Subject: "+testMessage.Subject)
+	assert.Contains(t, mail, "To: me@example.com")
+	assert.Contains(t, mail, testMessage.Text)
+}
+
+func TestEmail_Notify_Timeout(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	// The server accepts the connection but never greets.
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(io.Discard, conn)
+	}()
+
+	e := notify.NewEmail(&notify.EmailConfig{
+		Addr: ln.Addr().String(),
+		From: "alerts@example.com",
+		To:   []string{"me@example.com"},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	assert.Error(t, e.Notify(ctx, testMessage))
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+// serveSMTP accepts one connection and speaks just enough SMTP for
+// net/smtp.SendMail, sending the message data to out.
+func serveSMTP(t *testing.T, ln net.Listener, out chan<- string) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(s string) { _, _ = conn.Write([]byte(s + "\r\n")) }
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "DATA"):
+			reply("354 go ahead")
+			var b strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				b.WriteString(l)
+			}
+			out <- b.String()
+			reply("250 ok")
+		case strings.HasPrefix(cmd, "QUIT"):
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
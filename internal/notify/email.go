@@ -0,0 +1,121 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// headerReplacer keeps message text from starting new mail headers.
+var headerReplacer = strings.NewReplacer("\r", " ", "\n", " ")
+
+// emailTimeout bounds a send when the context has no deadline, matching the
+// HTTP notifiers.
+const emailTimeout = 10 * time.Second
+
+type EmailConfig struct {
+	// Addr is the SMTP server as host:port.
+	Addr string
+	From string
+	To   []string
+	// Username and Password enable PLAIN authentication, which net/smtp only
+	// allows over TLS or to localhost.
+	Username string
+	Password string
+}
+
+// Email sends messages as plain text mail over SMTP.
+type Email struct {
+	addr string
+	from string
+	to   []string
+	auth smtp.Auth
+}
+
+func NewEmail(c *EmailConfig) *Email {
+	e := &Email{addr: c.Addr, from: c.From, to: c.To}
+	if c.Username != "" {
+		host, _, _ := net.SplitHostPort(c.Addr)
+		e.auth = smtp.PlainAuth("", c.Username, c.Password, host)
+	}
+	return e
+}
+
+func (e *Email) Notify(ctx context.Context, msg Message) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", e.from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(e.to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", headerReplacer.Replace(msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(msg.Text)
+	b.WriteString("\r\n")
+
+	return e.send(ctx, []byte(b.String()))
+}
+
+// send does what smtp.SendMail does over a connection that gives up at the
+// context's deadline, or after emailTimeout, and when the context is
+// cancelled.
+func (e *Email) send(ctx context.Context, data []byte) error {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(emailTimeout)
+	}
+
+	dialer := net.Dialer{Deadline: deadline}
+	conn, err := dialer.DialContext(ctx, "tcp", e.addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", e.addr, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	host, _, _ := net.SplitHostPort(e.addr)
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if e.auth != nil {
+		if err := c.Auth(e.auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(e.from); err != nil {
+		return err
+	}
+	for _, to := range e.to {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
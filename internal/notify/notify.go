@@ -0,0 +1,170 @@
+// Package notify delivers events to people through pluggable notifiers
+// (webhook, Telegram, email) according to configurable rules.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"auto-finance/internal/events"
+	"auto-finance/internal/models"
+
+	"github.com/rs/zerolog"
+)
+
+// Message is a formatted notification.
+type Message struct {
+	Subject string
+	Text    string
+	Event   events.Event
+}
+
+// Notifier delivers a message over one channel.
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// Rule selects the events a set of notifiers is told about. Empty filters
+// match everything.
+type Rule struct {
+	Event     events.Type
+	Notifiers []string
+
+	// Filters on the event data's fields, see events.Fields. A filter is
+	// ignored for data without the field. Account also matches the account
+	// number of bill mismatches, and Status the kind of subscription alerts
+	// ("new", "changed" or "missed").
+	Institution string
+	Account     string
+	Channel     string
+	Direction   string
+	Status      string
+	// MinAmount only matches transactions, and bills by total payable, of at
+	// least this amount in the same currency. Transactions in another
+	// currency are compared by their converted amount.
+	MinAmount models.Money
+}
+
+type Config struct {
+	Logger    zerolog.Logger
+	Notifiers map[string]Notifier
+	Rules     []Rule
+}
+
+// Dispatcher sends each event to the notifiers of every matching rule.
+type Dispatcher struct {
+	logger    zerolog.Logger
+	notifiers map[string]Notifier
+	rules     []Rule
+}
+
+// New checks that every rule refers to a known notifier.
+func New(c *Config) (*Dispatcher, error) {
+	for i, r := range c.Rules {
+		if r.Event == "" {
+			return nil, fmt.Errorf("notify rule %d: event is required", i+1)
+		}
+		if len(r.Notifiers) == 0 {
+			return nil, fmt.Errorf("notify rule %d: at least one notifier is required", i+1)
+		}
+		for _, name := range r.Notifiers {
+			if _, ok := c.Notifiers[name]; !ok {
+				return nil, fmt.Errorf("notify rule %d: unknown notifier %q", i+1, name)
+			}
+		}
+	}
+
+	return &Dispatcher{logger: c.Logger, notifiers: c.Notifiers, rules: c.Rules}, nil
+}
+
+// Handle is an events.Handler. A notifier is used at most once per event,
+// however many rules select it.
+func (d *Dispatcher) Handle(ctx context.Context, event events.Event) error {
+	var names []string
+	seen := make(map[string]bool)
+	for _, r := range d.rules {
+		if !r.matches(event) {
+			continue
+		}
+		for _, name := range r.Notifiers {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	msg := Format(event)
+
+	var errs []error
+	for _, name := range names {
+		if err := d.notifiers[name].Notify(ctx, msg); err != nil {
+			d.logger.Error().Err(err).Str("notifier", name).Str("event", string(event.Type)).Msg("Failed to send notification")
+			errs = append(errs, fmt.Errorf("notifier %s: %w", name, err))
+			continue
+		}
+		d.logger.Info().Str("notifier", name).Str("event", string(event.Type)).Msg("Notification sent")
+	}
+	return errors.Join(errs...)
+}
+
+func (r Rule) matches(event events.Event) bool {
+	if r.Event != event.Type {
+		return false
+	}
+
+	data, ok := event.Data.(events.Describer)
+	if !ok {
+		return true
+	}
+	fields := data.Fields()
+	for name, want := range map[string]string{
+		events.FieldInstitution: r.Institution,
+		events.FieldAccount:     r.Account,
+		events.FieldChannel:     r.Channel,
+		events.FieldDirection:   r.Direction,
+		events.FieldStatus:      r.Status,
+	} {
+		if got, ok := fields.Values[name]; ok && !matchField(want, got) {
+			return false
+		}
+	}
+	return len(fields.Amounts) == 0 || atLeast(fields.Amounts, r.MinAmount)
+}
+
+func matchField(want, got string) bool {
+	return want == "" || strings.EqualFold(want, got)
+}
+
+// atLeast reports whether the amount in min's currency, such as the converted
+// amount of a foreign currency spend, is at least min.
+func atLeast(amounts []models.Money, min models.Money) bool {
+	if min.IsZero() {
+		return true
+	}
+	for _, amount := range amounts {
+		if cmp, err := amount.Cmp(min); err == nil && cmp >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Format renders an event as a short human readable message.
+func Format(event events.Event) Message {
+	msg := Message{Event: event}
+
+	if data, ok := event.Data.(events.Describer); ok {
+		msg.Subject, msg.Text = data.Summary()
+		return msg
+	}
+
+	msg.Subject = string(event.Type)
+	msg.Text = fmt.Sprintf("%s: %v", event.Type, event.Data)
+	return msg
+}
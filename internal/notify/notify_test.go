@@ -0,0 +1,214 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"auto-finance/internal/events"
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/ebill"
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/models/waterbill"
+	"auto-finance/internal/notify"
+	"auto-finance/internal/service/tariff"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	messages []notify.Message
+	err      error
+}
+
+func (r *recorder) Notify(_ context.Context, msg notify.Message) error {
+	r.messages = append(r.messages, msg)
+	return r.err
+}
+
+func lkr(rupees int64) models.Money {
+	return models.NewMoney(rupees*100, "LKR")
+}
+
+func TestDispatcher_Handle(t *testing.T) {
+	t.Parallel()
+
+	card := &finance.Transaction{
+		Institution:  finance.InstitutionSampath,
+		Account:      "1234",
+		Channel:      finance.TransactionTypeCard,
+		Direction:    finance.DirectionDebit,
+		Status:       finance.StatusAuthorized,
+		Amount:       lkr(25000),
+		Counterparty: "MASKED SUPER",
+	}
+	small := *card
+	small.Amount = lkr(500)
+	declined := small
+	declined.Status = finance.StatusDeclined
+	online := *card
+	online.Channel = finance.TransactionTypeOnline
+	foreign := *card
+	foreign.Amount = models.NewMoney(4000, "USD")
+	foreign.Converted = lkr(12000)
+	smallForeign := foreign
+	smallForeign.Converted = lkr(3000)
+	unconverted := foreign
+	unconverted.Converted = models.Money{}
+	bill := &ebill.ElectricityBill{AccountName: "MASKED", TotalPayable: lkr(8000)}
+
+	rules := []notify.Rule{
+		{Event: events.TypeTransactionRecorded, Channel: "card", MinAmount: lkr(10000), Notifiers: []string{"chat"}},
+		{Event: events.TypeTransactionRecorded, Status: finance.StatusDeclined, Notifiers: []string{"chat", "mail"}},
+		{Event: events.TypeBillRecorded, Notifiers: []string{"mail", "mail"}},
+	}
+
+	tests := []struct {
+		name     string
+		event    events.Event
+		wantChat int
+		wantMail int
+	}{
+		{name: "large card spend", event: events.Event{Type: events.TypeTransactionRecorded, Data: card}, wantChat: 1},
+		{name: "small card spend", event: events.Event{Type: events.TypeTransactionRecorded, Data: &small}},
+		{name: "large foreign card spend", event: events.Event{Type: events.TypeTransactionRecorded, Data: &foreign}, wantChat: 1},
+		{name: "small foreign card spend", event: events.Event{Type: events.TypeTransactionRecorded, Data: &smallForeign}},
+		{name: "unconverted foreign card spend", event: events.Event{Type: events.TypeTransactionRecorded, Data: &unconverted}},
+		{name: "large online spend", event: events.Event{Type: events.TypeTransactionRecorded, Data: &online}},
+		{name: "decline", event: events.Event{Type: events.TypeTransactionRecorded, Data: &declined}, wantChat: 1, wantMail: 1},
+		{name: "bill sent once", event: events.Event{Type: events.TypeBillRecorded, Data: bill}, wantMail: 1},
+		{name: "unmatched event", event: events.Event{Type: events.TypeBudgetThreshold}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			chat, mail := &recorder{}, &recorder{}
+			d, err := notify.New(&notify.Config{
+				Logger:    zerolog.Nop(),
+				Notifiers: map[string]notify.Notifier{"chat": chat, "mail": mail},
+				Rules:     rules,
+			})
+			require.NoError(t, err)
+
+			require.NoError(t, d.Handle(context.Background(), tt.event))
+			assert.Len(t, chat.messages, tt.wantChat)
+			assert.Len(t, mail.messages, tt.wantMail)
+		})
+	}
+}
+
+func TestDispatcher_Handle_MissingFields(t *testing.T) {
+	t.Parallel()
+
+	chat := &recorder{}
+	d, err := notify.New(&notify.Config{
+		Logger:    zerolog.Nop(),
+		Notifiers: map[string]notify.Notifier{"chat": chat},
+		Rules: []notify.Rule{
+			{Event: events.TypeBillRecorded, Account: "1234", Channel: "card", Notifiers: []string{"chat"}},
+			{Event: events.TypeBillMismatch, Account: "1234", Notifiers: []string{"chat"}},
+		},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, d.Handle(ctx, events.Event{Type: events.TypeBillRecorded, Data: &ebill.ElectricityBill{AccountNumber: "0123456789"}}))
+	assert.Len(t, chat.messages, 1, "bills have no account or channel to filter on")
+
+	mismatch := &tariff.Verification{Bill: &ebill.ElectricityBill{AccountNumber: "0123456789"}}
+	require.NoError(t, d.Handle(ctx, events.Event{Type: events.TypeBillMismatch, Data: mismatch}))
+	assert.Len(t, chat.messages, 1, "mismatches are filtered by account")
+}
+
+func TestDispatcher_HandleErrors(t *testing.T) {
+	t.Parallel()
+
+	failing := &recorder{err: errors.New("down")}
+	working := &recorder{}
+	d, err := notify.New(&notify.Config{
+		Logger:    zerolog.Nop(),
+		Notifiers: map[string]notify.Notifier{"failing": failing, "working": working},
+		Rules:     []notify.Rule{{Event: events.TypeBillRecorded, Notifiers: []string{"failing", "working"}}},
+	})
+	require.NoError(t, err)
+
+	err = d.Handle(context.Background(), events.Event{Type: events.TypeBillRecorded, Data: &ebill.ElectricityBill{}})
+	assert.ErrorContains(t, err, "notifier failing: down")
+	assert.Len(t, working.messages, 1, "later notifiers still run")
+}
+
+func TestNew_InvalidRules(t *testing.T) {
+	t.Parallel()
+
+	notifiers := map[string]notify.Notifier{"chat": &recorder{}}
+	for name, rule := range map[string]notify.Rule{
+		"missing event":     {Notifiers: []string{"chat"}},
+		"missing notifiers": {Event: events.TypeBillRecorded},
+		"unknown notifier":  {Event: events.TypeBillRecorded, Notifiers: []string{"pager"}},
+	} {
+		_, err := notify.New(&notify.Config{Notifiers: notifiers, Rules: []notify.Rule{rule}})
+		assert.Error(t, err, name)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	msg := notify.Format(events.Event{Type: events.TypeTransactionRecorded, Data: &finance.Transaction{
+		Institution:  finance.InstitutionHNB,
+		Account:      "5678",
+		Channel:      finance.TransactionTypeCard,
+		Direction:    finance.DirectionDebit,
+		Status:       finance.StatusDeclined,
+		Amount:       lkr(1200),
+		Counterparty: "MASKED CAFE",
+	}})
+	assert.Contains(t, msg.Subject, "Declined")
+	assert.Contains(t, msg.Text, "MASKED CAFE")
+	assert.Contains(t, msg.Text, "5678")
+
+	msg = notify.Format(events.Event{Type: events.TypeBillRecorded, Data: &ebill.ElectricityBill{
+		AccountName:   "MASKED",
+		AccountNumber: "0123456789",
+		ImportUnits:   150,
+		TotalPayable:  lkr(8000),
+	}})
+	assert.Contains(t, msg.Subject, "LECO")
+	assert.Contains(t, msg.Text, "150 units")
+	assert.Contains(t, msg.Text, lkr(8000).String())
+
+	msg = notify.Format(events.Event{Type: events.TypeBillRecorded, Data: &waterbill.WaterBill{
+		Provider:      waterbill.ProviderNWSDB,
+		AccountNumber: "10/06/026/1234/09",
+		Units:         22,
+		TotalPayable:  lkr(2450),
+		DueDate:       time.Date(2025, time.October, 25, 0, 0, 0, 0, time.UTC),
+	}})
+	assert.Contains(t, msg.Subject, "NWSDB water bill")
+	assert.Contains(t, msg.Text, "22 m3")
+	assert.Contains(t, msg.Text, "due 2025-10-25")
+
+	msg = notify.Format(events.Event{Type: events.TypeBalanceDiscrepancy, Data: &finance.Discrepancy{
+		Institution: finance.InstitutionSampath,
+		Account:     "1234",
+		Expected:    lkr(46000),
+		Reported:    lkr(45500),
+		Difference:  lkr(-500),
+	}})
+	assert.Contains(t, msg.Subject, "1234")
+	assert.Contains(t, msg.Text, lkr(-500).String())
+
+	msg = notify.Format(events.Event{Type: events.TypeBillMismatch, Data: &tariff.Verification{
+		Bill:       &ebill.ElectricityBill{AccountName: "MASKED", AccountNumber: "0123456789"},
+		Tariff:     "domestic",
+		Mismatches: []tariff.Mismatch{{Field: "monthly_bill", Parsed: lkr(4100), Expected: lkr(3990)}},
+	}})
+	assert.Contains(t, msg.Subject, "0123456789")
+	assert.Contains(t, msg.Text, "monthly_bill billed "+lkr(4100).String()+", expected "+lkr(3990).String())
+}
@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const telegramBaseURL = "https://api.telegram.org"
+
+type TelegramConfig struct {
+	Token  string
+	ChatID string
+	// BaseURL overrides the Bot API endpoint.
+	BaseURL string
+	Client  *http.Client
+}
+
+// Telegram sends messages to a chat through the Telegram Bot API.
+type Telegram struct {
+	token   string
+	chatID  string
+	baseURL string
+	client  *http.Client
+}
+
+func NewTelegram(c *TelegramConfig) *Telegram {
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = telegramBaseURL
+	}
+	client := c.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Telegram{
+		token:   c.Token,
+		chatID:  c.ChatID,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  client,
+	}
+}
+
+type telegramResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+func (t *Telegram) Notify(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(map[string]string{
+		"chat_id": t.chatID,
+		"text":    msg.Text,
+	})
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/bot%s/sendMessage", t.baseURL, t.token)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var resp telegramResponse
+	if err := do(t.client, req, &resp); err != nil {
+		// The URL contains the bot token; keep it out of logs.
+		return errors.New(strings.ReplaceAll(err.Error(), t.token, "<token>"))
+	}
+	if !resp.OK {
+		return fmt.Errorf("telegram: %s", resp.Description)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"auto-finance/internal/events"
+)
+
+type WebhookConfig struct {
+	URL     string
+	Headers map[string]string
+	Client  *http.Client
+}
+
+// Webhook POSTs each message as JSON.
+type Webhook struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func NewWebhook(c *WebhookConfig) *Webhook {
+	client := c.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Webhook{url: c.URL, headers: c.Headers, client: client}
+}
+
+type webhookPayload struct {
+	Event   events.Type `json:"event"`
+	Time    time.Time   `json:"time,omitzero"`
+	Subject string      `json:"subject"`
+	Text    string      `json:"text"`
+	Data    any         `json:"data,omitempty"`
+}
+
+func (w *Webhook) Notify(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(webhookPayload{
+		Event:   msg.Event.Type,
+		Time:    msg.Event.Time,
+		Subject: msg.Subject,
+		Text:    msg.Text,
+		Data:    msg.Event.Data,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.headers {
+		req.Header.Set(k, v)
+	}
+
+	return do(w.client, req, nil)
+}
+
+// do sends req and decodes a JSON response into out when it is not nil.
+// Non-2xx responses are errors.
+func do(client *http.Client, req *http.Request, out any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,200 @@
+// Package budget keeps month-to-date spending per budget and raises an event
+// when a threshold of the budget limit is crossed.
+package budget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"auto-finance/internal/events"
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/storage"
+
+	"github.com/rs/zerolog"
+)
+
+// DefaultThresholds are the percentages of a limit that raise an alert.
+var DefaultThresholds = []int{80, 100}
+
+// Budget is a monthly spending limit for a category, an account, or both.
+// Empty Category or Account match every transaction.
+type Budget struct {
+	Name     string
+	Category string
+	Account  string
+	Limit    models.Money
+}
+
+// Alert is the data of an events.TypeBudgetThreshold event.
+type Alert struct {
+	Budget string `json:"budget"`
+	// Month is the budget month as "2006-01".
+	Month       string               `json:"month"`
+	Threshold   int                  `json:"threshold"`
+	Spent       models.Money         `json:"spent"`
+	Limit       models.Money         `json:"limit"`
+	Transaction *finance.Transaction `json:"transaction"`
+}
+
+// Summary implements events.Describer.
+func (a Alert) Summary() (subject, text string) {
+	return fmt.Sprintf("Budget %s at %d%%", a.Budget, a.Threshold),
+		fmt.Sprintf("Budget %s reached %d%% for %s: spent %s of %s", a.Budget, a.Threshold, a.Month, a.Spent, a.Limit)
+}
+
+// Fields implements events.Describer. Budget alerts are not filtered.
+func (a Alert) Fields() events.Fields {
+	return events.Fields{}
+}
+
+type Config struct {
+	Logger    zerolog.Logger
+	Budgets   []Budget
+	Storage   storage.BudgetStorage
+	Publisher events.Publisher
+	// Thresholds are percentages of the limit, DefaultThresholds when empty.
+	Thresholds []int
+	// Location decides which month a transaction falls in, UTC when nil.
+	Location *time.Location
+}
+
+// Service updates budget totals after a transaction is saved. It implements
+// finance.Hook.
+type Service struct {
+	logger     zerolog.Logger
+	budgets    []Budget
+	storage    storage.BudgetStorage
+	publisher  events.Publisher
+	thresholds []int
+	location   *time.Location
+}
+
+func New(c *Config) *Service {
+	thresholds := c.Thresholds
+	if len(thresholds) == 0 {
+		thresholds = DefaultThresholds
+	}
+
+	location := c.Location
+	if location == nil {
+		location = time.UTC
+	}
+
+	return &Service{
+		logger:     c.Logger,
+		budgets:    c.Budgets,
+		storage:    c.Storage,
+		publisher:  c.Publisher,
+		thresholds: thresholds,
+		location:   location,
+	}
+}
+
+// AfterSave adds a spend to the totals of every matching budget. Declined
+// transactions are ignored, reversals are subtracted and other credits do
+// not count as spending. Foreign currency spending counts once converted.
+func (s *Service) AfterSave(ctx context.Context, txn *finance.Transaction) error {
+	month := txn.OccurredAt.In(s.location).Format("2006-01")
+
+	var errs []error
+	for _, b := range s.budgets {
+		if !b.matches(txn) {
+			continue
+		}
+		amount, ok := spend(txn, b.Limit.Currency)
+		if !ok {
+			continue
+		}
+		if amount.Currency != b.Limit.Currency {
+			s.logger.Debug().Str("budget", b.Name).Str("currency", amount.Currency).Msg("Skipping transaction in another currency")
+			continue
+		}
+
+		if err := s.add(ctx, b, month, amount, txn); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to update budgets: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func (s *Service) add(ctx context.Context, b Budget, month string, amount models.Money, txn *finance.Transaction) error {
+	before, after, err := s.storage.Add(ctx, month+"|"+b.Name, amount)
+	if err != nil {
+		return fmt.Errorf("budget %s: %w", b.Name, err)
+	}
+
+	for _, threshold := range s.thresholds {
+		if !crossed(before, after, b.Limit, threshold) {
+			continue
+		}
+
+		alert := Alert{
+			Budget:      b.Name,
+			Month:       month,
+			Threshold:   threshold,
+			Spent:       after,
+			Limit:       b.Limit,
+			Transaction: txn,
+		}
+		s.logger.Warn().
+			Str("budget", b.Name).
+			Str("month", month).
+			Int("threshold", threshold).
+			Str("spent", after.String()).
+			Str("limit", b.Limit.String()).
+			Msg("Budget threshold crossed")
+
+		if s.publisher == nil {
+			continue
+		}
+		if err := s.publisher.Publish(ctx, events.Event{Type: events.TypeBudgetThreshold, Data: alert}); err != nil {
+			return fmt.Errorf("budget %s: failed to publish alert: %w", b.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (b Budget) matches(txn *finance.Transaction) bool {
+	if b.Category != "" && !strings.EqualFold(b.Category, txn.Category) {
+		return false
+	}
+	if b.Account != "" && b.Account != txn.Account {
+		return false
+	}
+	return true
+}
+
+// spend returns the amount a transaction adds to spending, using the FX
+// converted amount when it is in the wanted currency and the original is not.
+func spend(txn *finance.Transaction, currency string) (models.Money, bool) {
+	amount := txn.Amount
+	if amount.Currency != currency && txn.Converted.Currency == currency {
+		amount = txn.Converted
+	}
+
+	switch {
+	case txn.Status == finance.StatusDeclined:
+		return models.Money{}, false
+	case txn.Status == finance.StatusReversed:
+		return amount.Neg(), true
+	case txn.Direction == finance.DirectionDebit:
+		return amount, true
+	}
+	return models.Money{}, false
+}
+
+// crossed reports whether the total moved from below to at or above
+// threshold percent of limit.
+func crossed(before, after, limit models.Money, threshold int) bool {
+	mark := limit.Minor * int64(threshold)
+	return before.Minor*100 < mark && after.Minor*100 >= mark
+}
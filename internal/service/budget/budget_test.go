@@ -0,0 +1,95 @@
+package budget_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"auto-finance/internal/events"
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/service/budget"
+	budgetStorage "auto-finance/internal/storage/budget"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lkr(rupees int64) models.Money {
+	return models.NewMoney(rupees*100, "LKR")
+}
+
+func TestService_AfterSave(t *testing.T) {
+	colombo := time.FixedZone("+0530", 5*60*60+30*60)
+
+	var alerts []budget.Alert
+	bus := events.NewBus()
+	bus.Subscribe(func(_ context.Context, e events.Event) error {
+		require.Equal(t, events.TypeBudgetThreshold, e.Type)
+		alerts = append(alerts, e.Data.(budget.Alert))
+		return nil
+	})
+
+	svc := budget.New(&budget.Config{
+		Logger: zerolog.Nop(),
+		Budgets: []budget.Budget{
+			{Name: "Dining", Category: "Dining", Limit: lkr(10000)},
+			{Name: "Card 1234", Account: "1234", Limit: lkr(50000)},
+		},
+		Storage:   budgetStorage.NewMemory(),
+		Publisher: bus,
+		Location:  colombo,
+	})
+
+	nov := time.Date(2025, time.November, 7, 18, 0, 0, 0, colombo)
+	txn := func(amount models.Money, status string, direction finance.Direction, at time.Time) *finance.Transaction {
+		return &finance.Transaction{
+			Account:    "1234",
+			Category:   "Dining",
+			Amount:     amount,
+			Status:     status,
+			Direction:  direction,
+			OccurredAt: at,
+		}
+	}
+	ctx := context.Background()
+
+	require.NoError(t, svc.AfterSave(ctx, txn(lkr(7000), finance.StatusAuthorized, finance.DirectionDebit, nov)))
+	assert.Empty(t, alerts)
+
+	// Declines and ordinary credits do not count.
+	require.NoError(t, svc.AfterSave(ctx, txn(lkr(9000), finance.StatusDeclined, finance.DirectionDebit, nov)))
+	require.NoError(t, svc.AfterSave(ctx, txn(lkr(9000), finance.StatusCredit, finance.DirectionCredit, nov)))
+	assert.Empty(t, alerts)
+
+	// 7,000 + 1,500 crosses 80% of the dining budget only.
+	require.NoError(t, svc.AfterSave(ctx, txn(lkr(1500), finance.StatusAuthorized, finance.DirectionDebit, nov)))
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "Dining", alerts[0].Budget)
+	assert.Equal(t, "2025-11", alerts[0].Month)
+	assert.Equal(t, 80, alerts[0].Threshold)
+	assert.Equal(t, lkr(8500), alerts[0].Spent)
+
+	// A reversal brings the total back below 80%; crossing again alerts again.
+	require.NoError(t, svc.AfterSave(ctx, txn(lkr(1500), finance.StatusReversed, finance.DirectionCredit, nov)))
+	require.NoError(t, svc.AfterSave(ctx, txn(lkr(4000), finance.StatusAuthorized, finance.DirectionDebit, nov)))
+	require.Len(t, alerts, 3)
+	assert.Equal(t, []int{80, 100}, []int{alerts[1].Threshold, alerts[2].Threshold})
+
+	// A new month starts from zero, by the budget's time zone.
+	dec := time.Date(2025, time.November, 30, 20, 0, 0, 0, time.UTC) // 01:30 on 1 Dec in Colombo
+	require.NoError(t, svc.AfterSave(ctx, txn(lkr(9000), finance.StatusAuthorized, finance.DirectionDebit, dec)))
+	require.Len(t, alerts, 4)
+	assert.Equal(t, "2025-12", alerts[3].Month)
+
+	// Other currencies only count once converted.
+	require.NoError(t, svc.AfterSave(ctx, txn(models.NewMoney(100000000, "USD"), finance.StatusAuthorized, finance.DirectionDebit, nov)))
+	assert.Len(t, alerts, 4)
+
+	usd := txn(models.NewMoney(500, "USD"), finance.StatusAuthorized, finance.DirectionDebit, dec)
+	usd.Converted = lkr(1500)
+	require.NoError(t, svc.AfterSave(ctx, usd))
+	require.Len(t, alerts, 5)
+	assert.Equal(t, lkr(10500), alerts[4].Spent)
+}
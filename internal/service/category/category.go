@@ -0,0 +1,230 @@
+// Package category normalizes merchant names and assigns spending
+// categories to transactions from configurable rules.
+package category
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"auto-finance/internal/models/finance"
+
+	"github.com/BurntSushi/toml"
+	"github.com/rs/zerolog"
+)
+
+// Uncategorized is assigned when no rule matches.
+const Uncategorized = "Uncategorized"
+
+// Rule match types.
+const (
+	MatchExact  = "exact"
+	MatchPrefix = "prefix"
+	MatchRegex  = "regex"
+)
+
+var (
+	// legalSuffixes are removed from the end of merchant names, longest first.
+	legalSuffixes = []string{
+		"(PRIVATE) LIMITED", "(PVT) LIMITED", "PRIVATE LIMITED", "PVT LIMITED",
+		"(PVT) LTD", "(PVT)LTD", "PVT LTD", "PVT. LTD", "(PRIVATE) LTD", "PRIVATE LTD",
+		"LIMITED", "LTD", "PLC", "INC", "LLC", "CO", "(PVT)", "PVT",
+	}
+	// defaultCities are trailing location tokens seen in Sri Lankan card
+	// alerts.
+	defaultCities = []string{
+		"COLOMBO", "KANDY", "GALLE", "NUGEGODA", "KOTTE", "DEHIWALA", "MT LAVINIA",
+		"MORATUWA", "NEGOMBO", "KADUWELA", "MAHARAGAMA", "BATTARAMULLA", "RAJAGIRIYA",
+		"WATTALA", "KIRIBATHGODA", "PANADURA", "KURUNEGALA", "JAFFNA", "MATARA",
+		"MALABE", "PILIYANDALA", "BORALESGAMUWA", "NAWALA", "WELLAWATTE", "SRI LANKA", "LK",
+	}
+	// terminalRegex matches terminal and store IDs: tokens containing a digit,
+	// optionally prefixed by '#'.
+	terminalRegex = regexp.MustCompile(`^#?[A-Z]*\d[\w-]*$`)
+	// colomboDistrictRegex matches postal districts such as "COLOMBO 03".
+	colomboDistrictRegex = regexp.MustCompile(`\s+COLOMBO\s+\d{1,2}$`)
+	punctuationReplacer  = strings.NewReplacer("~", " ", "*", " ", "_", " ", ",", " ")
+)
+
+// Rule assigns a category to merchants matching Pattern.
+type Rule struct {
+	// Match is "exact", "prefix" or "regex" and is compared against the
+	// normalized merchant name, ignoring case.
+	Match    string `toml:"match"`
+	Pattern  string `toml:"pattern"`
+	Category string `toml:"category"`
+	// Merchant optionally replaces the normalized name, e.g. to merge
+	// "KEELLS SUPER" and "KEELLS" into "Keells".
+	Merchant string `toml:"merchant"`
+	// Channel and Direction optionally restrict the rule, e.g. every CEFT
+	// transfer is a Transfer whatever the counterparty.
+	Channel   string `toml:"channel"`
+	Direction string `toml:"direction"`
+}
+
+// Rules is the rules file format.
+type Rules struct {
+	// Cities replaces the built-in list of trailing location tokens.
+	Cities []string `toml:"cities"`
+	Rules  []Rule   `toml:"rules"`
+}
+
+// ParseRules decodes a TOML rules file.
+func ParseRules(data []byte) (Rules, error) {
+	var r Rules
+	if _, err := toml.Decode(string(data), &r); err != nil {
+		return Rules{}, err
+	}
+	return r, nil
+}
+
+type Config struct {
+	Logger zerolog.Logger
+	Rules  Rules
+}
+
+type compiledRule struct {
+	Rule
+	pattern string
+	regex   *regexp.Regexp
+}
+
+// Categorizer fills in the canonical merchant and category of transactions.
+type Categorizer struct {
+	logger zerolog.Logger
+	rules  []compiledRule
+	cities []string
+}
+
+func New(c *Config) (*Categorizer, error) {
+	rules := make([]compiledRule, 0, len(c.Rules.Rules))
+	for i, r := range c.Rules.Rules {
+		if r.Category == "" && r.Merchant == "" {
+			return nil, fmt.Errorf("rule %d: category or merchant is required", i+1)
+		}
+
+		compiled := compiledRule{Rule: r}
+		switch strings.ToLower(r.Match) {
+		case MatchExact, MatchPrefix:
+			compiled.pattern = Normalize(r.Pattern, nil)
+		case MatchRegex:
+			re, err := regexp.Compile("(?i)" + r.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i+1, err)
+			}
+			compiled.regex = re
+		default:
+			return nil, fmt.Errorf("rule %d: unknown match %q", i+1, r.Match)
+		}
+		rules = append(rules, compiled)
+	}
+
+	cities := defaultCities
+	if len(c.Rules.Cities) > 0 {
+		cities = make([]string, 0, len(c.Rules.Cities))
+		for _, city := range c.Rules.Cities {
+			cities = append(cities, strings.ToUpper(strings.TrimSpace(city)))
+		}
+	}
+
+	return &Categorizer{logger: c.Logger, rules: rules, cities: cities}, nil
+}
+
+// Enrich sets the transaction's Merchant and Category. It implements
+// finance.Enricher.
+func (c *Categorizer) Enrich(_ context.Context, txn *finance.Transaction) error {
+	merchant, category := c.Categorize(txn.Counterparty, string(txn.Channel), string(txn.Direction))
+	txn.Merchant = merchant
+	txn.Category = category
+	return nil
+}
+
+// Categorize returns the canonical merchant name and category for a raw
+// counterparty.
+func (c *Categorizer) Categorize(counterparty, channel, direction string) (string, string) {
+	merchant := Normalize(counterparty, c.cities)
+
+	for _, r := range c.rules {
+		if r.Channel != "" && !strings.EqualFold(r.Channel, channel) {
+			continue
+		}
+		if r.Direction != "" && !strings.EqualFold(r.Direction, direction) {
+			continue
+		}
+		if !r.matches(merchant) {
+			continue
+		}
+
+		category := r.Category
+		if category == "" {
+			category = Uncategorized
+		}
+		if r.Merchant != "" {
+			merchant = r.Merchant
+		}
+		return merchant, category
+	}
+
+	c.logger.Debug().Str("merchant", merchant).Msg("No category rule matched")
+	return merchant, Uncategorized
+}
+
+func (r compiledRule) matches(merchant string) bool {
+	switch {
+	case r.regex != nil:
+		return r.regex.MatchString(merchant)
+	case strings.EqualFold(r.Match, MatchPrefix):
+		return r.pattern != "" && (merchant == r.pattern || strings.HasPrefix(merchant, r.pattern+" "))
+	default:
+		return merchant == r.pattern
+	}
+}
+
+// Normalize upper-cases a raw merchant string and strips separators, legal
+// suffixes, terminal IDs and trailing city tokens, e.g.
+// "MASKED~PETROLEUM~PLC" becomes "MASKED PETROLEUM" and
+// "MASKED BISTRO (PVT) LTD" becomes "MASKED BISTRO". A nil cities list
+// strips no cities.
+func Normalize(raw string, cities []string) string {
+	s := strings.ToUpper(punctuationReplacer.Replace(raw))
+	s = strings.Join(strings.Fields(s), " ")
+
+	for {
+		before := s
+		s = strings.TrimRight(s, " .-/")
+		s = colomboDistrictRegex.ReplaceAllString(s, "")
+		s = trimSuffixes(s, legalSuffixes)
+		s = trimSuffixes(s, cities)
+		s = trimTerminalID(s)
+		if s == before {
+			break
+		}
+	}
+
+	if s == "" {
+		return strings.Join(strings.Fields(strings.ToUpper(raw)), " ")
+	}
+	return s
+}
+
+// trimSuffixes removes one whole-word suffix, keeping at least one word.
+func trimSuffixes(s string, suffixes []string) string {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, " "+suffix) {
+			return strings.TrimSpace(strings.TrimSuffix(s, suffix))
+		}
+	}
+	return s
+}
+
+func trimTerminalID(s string) string {
+	i := strings.LastIndex(s, " ")
+	if i < 0 {
+		return s
+	}
+	if terminalRegex.MatchString(s[i+1:]) {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,140 @@
+package category_test
+
+import (
+	"context"
+	"testing"
+
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/service/category"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	cities := []string{"COLOMBO", "NUGEGODA", "KOTTE"}
+
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{raw: "MASKED~PETROLEUM~PLC", want: "MASKED PETROLEUM"},
+		{raw: "MASKED BISTRO (PVT) LTD", want: "MASKED BISTRO"},
+		{raw: "Masked Super Kotte", want: "MASKED SUPER"},
+		{raw: "MASKED BANK ATM NUGEGODA", want: "MASKED BANK ATM"},
+		{raw: "MASKED FOOD CITY T0012345 COLOMBO 03", want: "MASKED FOOD CITY"},
+		{raw: "MASKED CAFE #0042 COLOMBO", want: "MASKED CAFE"},
+		{raw: "MASKED CARD PAYMENT 123456789", want: "MASKED CARD PAYMENT"},
+		{raw: "MASKED-PARK TERMINAL", want: "MASKED-PARK TERMINAL"},
+		{raw: "  KOTTE  ", want: "KOTTE"},
+		{raw: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.raw, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, category.Normalize(tt.raw, cities))
+		})
+	}
+}
+
+func TestCategorizer(t *testing.T) {
+	t.Parallel()
+
+	rules, err := category.ParseRules([]byte(`
+[[rules]]
+match = "exact"
+pattern = "masked petroleum plc"
+category = "Fuel"
+merchant = "Masked Petroleum"
+
+[[rules]]
+match = "prefix"
+pattern = "KEELLS"
+category = "Groceries"
+merchant = "Keells"
+
+[[rules]]
+match = "regex"
+pattern = '\b(BISTRO|CAFE|RESTAURANT)\b'
+category = "Dining"
+
+[[rules]]
+match = "regex"
+pattern = '.*'
+channel = "CEFT"
+category = "Transfers"
+`))
+	require.NoError(t, err)
+
+	c, err := category.New(&category.Config{Logger: zerolog.Nop(), Rules: rules})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		txn          finance.Transaction
+		wantMerchant string
+		wantCategory string
+	}{
+		{
+			name:         "exact match renames merchant",
+			txn:          finance.Transaction{Counterparty: "MASKED~PETROLEUM~PLC", Channel: finance.TransactionTypeCard},
+			wantMerchant: "Masked Petroleum",
+			wantCategory: "Fuel",
+		},
+		{
+			name:         "prefix matches whole words only",
+			txn:          finance.Transaction{Counterparty: "KEELLS SUPER NUGEGODA"},
+			wantMerchant: "Keells",
+			wantCategory: "Groceries",
+		},
+		{
+			name:         "prefix does not match a longer word",
+			txn:          finance.Transaction{Counterparty: "KEELLSMART"},
+			wantMerchant: "KEELLSMART",
+			wantCategory: category.Uncategorized,
+		},
+		{
+			name:         "regex keeps normalized merchant",
+			txn:          finance.Transaction{Counterparty: "MASKED BISTRO (PVT) LTD"},
+			wantMerchant: "MASKED BISTRO",
+			wantCategory: "Dining",
+		},
+		{
+			name:         "channel restricted rule",
+			txn:          finance.Transaction{Counterparty: "8001234567 MASKED BANK", Channel: finance.TransactionTypeCEFT},
+			wantMerchant: "8001234567 MASKED BANK",
+			wantCategory: "Transfers",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			txn := tt.txn
+			require.NoError(t, c.Enrich(context.Background(), &txn))
+			assert.Equal(t, tt.wantMerchant, txn.Merchant)
+			assert.Equal(t, tt.wantCategory, txn.Category)
+			assert.Equal(t, tt.txn.Counterparty, txn.Counterparty, "raw counterparty is kept")
+		})
+	}
+}
+
+func TestNew_InvalidRules(t *testing.T) {
+	t.Parallel()
+
+	for name, rule := range map[string]category.Rule{
+		"unknown match":    {Match: "fuzzy", Pattern: "X", Category: "Y"},
+		"bad regex":        {Match: category.MatchRegex, Pattern: "(", Category: "Y"},
+		"nothing assigned": {Match: category.MatchExact, Pattern: "X"},
+	} {
+		_, err := category.New(&category.Config{Rules: category.Rules{Rules: []category.Rule{rule}}})
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,119 @@
+package deadletter
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"time"
+
+	"auto-finance/internal/errors"
+	"auto-finance/internal/models"
+	"auto-finance/internal/service/message"
+	"auto-finance/internal/storage"
+
+	"github.com/rs/zerolog"
+)
+
+type Config struct {
+	Logger  zerolog.Logger
+	Storage storage.DeadLetterStorage
+}
+
+// ReplayReport summarizes a replay run.
+type ReplayReport struct {
+	Total      int
+	Replayed   int
+	Duplicates int
+	Failed     int
+}
+
+type Service struct {
+	logger  zerolog.Logger
+	storage storage.DeadLetterStorage
+	now     func() time.Time
+}
+
+func New(c *Config) *Service {
+	return &Service{
+		logger:  c.Logger,
+		storage: c.Storage,
+		now:     time.Now,
+	}
+}
+
+// Capture stores msg together with the error that stopped it.
+func (s *Service) Capture(ctx context.Context, msg message.Message, cause error) error {
+	letter := &models.DeadLetter{
+		MessageID:  msg.ID,
+		Sender:     msg.Sender,
+		Body:       msg.Body,
+		ReceivedAt: msg.ReceivedAt,
+		ExternalID: msg.ExternalID,
+		ErrorType:  string(errors.ErrorTypeOf(cause)),
+		Error:      cause.Error(),
+		Attempts:   1,
+		FailedAt:   s.now(),
+	}
+
+	if err := s.storage.Save(ctx, letter); err != nil {
+		return fmt.Errorf("failed to save dead letter %s: %w", msg.ID, err)
+	}
+
+	s.logger.Warn().Str("message_id", msg.ID.String()).Str("error_type", letter.ErrorType).Msg("Message dead lettered")
+	return nil
+}
+
+// Replay passes every stored message through svc again. Messages that succeed
+// or turn out to be duplicates are removed; the rest are kept with their
+// attempt count and error updated.
+func (s *Service) Replay(ctx context.Context, svc message.Service) (ReplayReport, error) {
+	letters, err := s.storage.List(ctx)
+	if err != nil {
+		return ReplayReport{}, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+
+	report := ReplayReport{Total: len(letters)}
+
+	for _, letter := range letters {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		log := s.logger.With().Str("message_id", letter.MessageID.String()).Int("attempts", letter.Attempts).Logger()
+
+		_, err := svc.PassMessage(ctx, message.Message{
+			ID:         letter.MessageID,
+			Sender:     letter.Sender,
+			Body:       letter.Body,
+			ReceivedAt: letter.ReceivedAt,
+			ExternalID: letter.ExternalID,
+		})
+
+		switch {
+		case err == nil:
+			report.Replayed++
+			log.Info().Msg("Dead letter replayed")
+		case stdErrors.Is(err, message.ErrDuplicate):
+			report.Duplicates++
+			log.Info().Msg("Dead letter was already processed")
+		default:
+			report.Failed++
+			log.Warn().Err(err).Msg("Dead letter replay failed")
+
+			letter.Attempts++
+			letter.ErrorType = string(errors.ErrorTypeOf(err))
+			letter.Error = err.Error()
+			letter.FailedAt = s.now()
+			if saveErr := s.storage.Save(ctx, letter); saveErr != nil {
+				return report, fmt.Errorf("failed to update dead letter %s: %w", letter.MessageID, saveErr)
+			}
+			continue
+		}
+
+		if err := s.storage.Delete(ctx, letter.MessageID); err != nil {
+			return report, fmt.Errorf("failed to delete dead letter %s: %w", letter.MessageID, err)
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,70 @@
+package deadletter_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	appErrors "auto-finance/internal/errors"
+	"auto-finance/internal/service/deadletter"
+	"auto-finance/internal/service/message"
+	deadletterStorage "auto-finance/internal/storage/deadletter"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedService fails messages whose body is listed in errs.
+type scriptedService struct {
+	errs  map[string]error
+	calls []message.Message
+}
+
+func (s *scriptedService) PassMessage(_ context.Context, msg message.Message) (message.Result, error) {
+	s.calls = append(s.calls, msg)
+	return message.Result{}, s.errs[msg.Body]
+}
+
+func TestService_CaptureAndReplay(t *testing.T) {
+	ctx := context.Background()
+	store := deadletterStorage.NewFile(t.TempDir())
+	svc := deadletter.New(&deadletter.Config{Logger: zerolog.Nop(), Storage: store})
+
+	receivedAt := time.Date(2025, time.November, 7, 18, 42, 10, 0, time.UTC)
+	parseErr := appErrors.NewTypedError(errors.New("unrecognized format"), appErrors.ErrorTypeParser)
+
+	fixed := message.Message{ID: uuid.New(), Sender: "HNB", Body: "fixed", ReceivedAt: receivedAt, ExternalID: "sms-1"}
+	duplicate := message.Message{ID: uuid.New(), Sender: "HNB", Body: "duplicate", ReceivedAt: receivedAt}
+	broken := message.Message{ID: uuid.New(), Sender: "HNB", Body: "broken", ReceivedAt: receivedAt}
+
+	for _, msg := range []message.Message{fixed, duplicate, broken} {
+		require.NoError(t, svc.Capture(ctx, msg, parseErr))
+	}
+
+	letters, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, letters, 3)
+	assert.Equal(t, "parser", letters[0].ErrorType)
+	assert.Equal(t, 1, letters[0].Attempts)
+
+	next := &scriptedService{errs: map[string]error{
+		"duplicate": fmt.Errorf("%w: key", message.ErrDuplicate),
+		"broken":    appErrors.NewRetryableError(errors.New("quota exceeded"), appErrors.ErrorTypeGoogle, time.Second, 3),
+	}}
+
+	report, err := svc.Replay(ctx, next)
+	require.NoError(t, err)
+	assert.Equal(t, deadletter.ReplayReport{Total: 3, Replayed: 1, Duplicates: 1, Failed: 1}, report)
+	assert.Contains(t, next.calls, fixed)
+
+	letters, err = store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.Equal(t, broken.ID, letters[0].MessageID)
+	assert.Equal(t, 2, letters[0].Attempts)
+	assert.Equal(t, "google", letters[0].ErrorType)
+}
@@ -0,0 +1,97 @@
+package ebill
+
+import (
+	"context"
+
+	"auto-finance/internal/events"
+	"auto-finance/internal/models/ebill"
+	"auto-finance/internal/service/tariff"
+	"auto-finance/internal/storage"
+
+	"github.com/rs/zerolog"
+)
+
+// BillService saves electricity bills from any provider.
+type BillService interface {
+	HandleBill(ctx context.Context, bill *ebill.ElectricityBill) error
+}
+
+type Config struct {
+	Logger  zerolog.Logger
+	Storage storage.MessageStorage[*ebill.ElectricityBill]
+	// Publisher, when set, receives an events.TypeBillRecorded event for
+	// every saved bill.
+	Publisher events.Publisher
+	// Verifier, when set, checks every saved bill against the tariff and
+	// publishes an events.TypeBillMismatch event when it differs.
+	Verifier *tariff.Verifier
+}
+
+type billService struct {
+	logger    zerolog.Logger
+	storage   storage.MessageStorage[*ebill.ElectricityBill]
+	publisher events.Publisher
+	verifier  *tariff.Verifier
+}
+
+func NewBillService(c *Config) BillService {
+	return &billService{
+		logger:    c.Logger,
+		storage:   c.Storage,
+		publisher: c.Publisher,
+		verifier:  c.Verifier,
+	}
+}
+
+func (s *billService) HandleBill(ctx context.Context, bill *ebill.ElectricityBill) error {
+	s.logger.Info().Str("provider", bill.Provider).Msgf("Handling bill: %s", bill.AccountName)
+
+	if err := s.storage.Save(ctx, bill); err != nil {
+		s.logger.Error().Err(err).Str("provider", bill.Provider).Msg("Failed to save bill")
+		return err
+	}
+
+	s.logger.Info().Str("provider", bill.Provider).Msg("Bill saved successfully")
+
+	if s.publisher != nil {
+		// The bill is already saved, so a failed notification must not fail
+		// the message and have it saved again on replay.
+		if err := s.publisher.Publish(ctx, events.Event{Type: events.TypeBillRecorded, Data: bill}); err != nil {
+			s.logger.Error().Err(err).Msg("Failed to publish bill event")
+		}
+	}
+
+	if s.verifier != nil {
+		s.verify(ctx, bill)
+	}
+
+	return nil
+}
+
+// verify only logs failures: the bill is saved and a wrong or missing
+// tariff must not have the message retried.
+func (s *billService) verify(ctx context.Context, bill *ebill.ElectricityBill) {
+	result, err := s.verifier.Verify(bill)
+	if err != nil {
+		s.logger.Warn().Err(err).Str("account_type", bill.AccountType).Msg("Failed to verify bill")
+		return
+	}
+	if result.OK() {
+		s.logger.Info().Str("tariff", result.Tariff).Msg("Bill matches tariff")
+		return
+	}
+
+	for _, m := range result.Mismatches {
+		s.logger.Warn().
+			Str("tariff", result.Tariff).
+			Str("field", m.Field).
+			Str("parsed", m.Parsed.String()).
+			Str("expected", m.Expected.String()).
+			Msg("Bill differs from tariff")
+	}
+	if s.publisher != nil {
+		if err := s.publisher.Publish(ctx, events.Event{Type: events.TypeBillMismatch, Data: result}); err != nil {
+			s.logger.Error().Err(err).Msg("Failed to publish bill mismatch event")
+		}
+	}
+}
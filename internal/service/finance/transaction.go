@@ -0,0 +1,189 @@
+package finance
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"auto-finance/internal/events"
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// DefaultReversalWindow is how far back a reversal is matched against
+// authorizations when Config.ReversalWindow is zero.
+const DefaultReversalWindow = 7 * 24 * time.Hour
+
+// TransactionService handles bank transactions from every institution.
+type TransactionService interface {
+	HandleTransaction(ctx context.Context, txn *finance.Transaction) error
+}
+
+// Enricher adds derived details to a transaction before it is saved.
+type Enricher interface {
+	Enrich(ctx context.Context, txn *finance.Transaction) error
+}
+
+// Hook reacts to a transaction after it has been saved.
+type Hook interface {
+	AfterSave(ctx context.Context, txn *finance.Transaction) error
+}
+
+type Config struct {
+	Logger  zerolog.Logger
+	Storage storage.MessageStorage[*finance.Transaction]
+	// Enrichers run in order before the transaction is saved.
+	Enrichers []Enricher
+	// Hooks run in order after the transaction is saved. Their errors are
+	// logged only, since failing the message would save it again on replay.
+	Hooks []Hook
+	// Publisher, when set, receives an events.TypeTransactionRecorded event
+	// for every saved transaction.
+	Publisher events.Publisher
+	// ReversalWindow is how long before a reversal its authorization may
+	// have occurred. Reversals are only linked when Storage is a
+	// storage.TransactionLedger.
+	ReversalWindow time.Duration
+}
+
+type transactionService struct {
+	logger         zerolog.Logger
+	storage        storage.MessageStorage[*finance.Transaction]
+	enrichers      []Enricher
+	hooks          []Hook
+	publisher      events.Publisher
+	reversalWindow time.Duration
+}
+
+func NewTransactionService(c *Config) TransactionService {
+	window := c.ReversalWindow
+	if window <= 0 {
+		window = DefaultReversalWindow
+	}
+
+	return &transactionService{
+		logger:         c.Logger,
+		storage:        c.Storage,
+		enrichers:      c.Enrichers,
+		hooks:          c.Hooks,
+		publisher:      c.Publisher,
+		reversalWindow: window,
+	}
+}
+
+func (s *transactionService) HandleTransaction(ctx context.Context, txn *finance.Transaction) error {
+	logger := s.logger.With().Str("institution", txn.Institution).Logger()
+	logger.Info().Msgf("Handling %s transaction", txn.Channel)
+
+	for _, e := range s.enrichers {
+		if err := e.Enrich(ctx, txn); err != nil {
+			logger.Error().Err(err).Msg("Failed to enrich transaction")
+			return err
+		}
+	}
+
+	if txn.Status == finance.StatusDeclined {
+		logger.Warn().
+			Str("account", txn.Account).
+			Str("amount", txn.Amount.String()).
+			Str("counterparty", txn.Counterparty).
+			Time("occurredAt", txn.OccurredAt).
+			Msg("Declined transaction, kept out of spending for fraud review")
+	}
+
+	var authorization *finance.Transaction
+	if txn.Status == finance.StatusReversed {
+		var err error
+		authorization, err = s.findAuthorization(ctx, txn)
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to look up reversed authorization")
+			return err
+		}
+		if authorization == nil {
+			logger.Warn().Str("account", txn.Account).Str("amount", txn.Amount.String()).Msg("No authorization found for reversal")
+		} else if authorization.SourceMessageID != uuid.Nil {
+			txn.SetExtension(finance.ExtensionReversalOf, authorization.SourceMessageID.String())
+		}
+	}
+
+	if err := s.storage.Save(ctx, txn); err != nil {
+		logger.Error().Err(err).Msg("Failed to save transaction")
+		return err
+	}
+
+	logger.Info().Msg("Transaction saved successfully")
+
+	if authorization != nil {
+		// The reversal is saved; failing now would save it again on replay.
+		if err := s.storage.(storage.TransactionLedger).UpdateStatus(ctx, authorization, finance.StatusReversed); err != nil {
+			logger.Error().Err(err).Msg("Failed to mark authorization as reversed")
+		} else {
+			logger.Info().Time("authorizedAt", authorization.OccurredAt).Msg("Authorization marked as reversed")
+		}
+	}
+
+	if s.publisher != nil {
+		if err := s.publisher.Publish(ctx, events.Event{Type: events.TypeTransactionRecorded, Data: txn}); err != nil {
+			logger.Error().Err(err).Msg("Failed to publish transaction event")
+		}
+	}
+
+	for _, h := range s.hooks {
+		if err := h.AfterSave(ctx, txn); err != nil {
+			logger.Error().Err(err).Msg("Post-save hook failed")
+		}
+	}
+
+	return nil
+}
+
+// findAuthorization returns the latest authorization the reversal cancels:
+// same card, amount, currency and merchant, within the reversal window. It
+// returns nil when there is none or the storage cannot look it up.
+func (s *transactionService) findAuthorization(ctx context.Context, reversal *finance.Transaction) (*finance.Transaction, error) {
+	ledger, ok := s.storage.(storage.TransactionLedger)
+	if !ok {
+		return nil, nil
+	}
+
+	candidates, err := ledger.Since(ctx, reversal.Institution, reversal.OccurredAt.Add(-s.reversalWindow))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recent transactions: %w", err)
+	}
+
+	var match *finance.Transaction
+	for _, c := range candidates {
+		if !reverses(reversal, c) {
+			continue
+		}
+		if match == nil || c.OccurredAt.After(match.OccurredAt) {
+			match = c
+		}
+	}
+	return match, nil
+}
+
+func reverses(reversal, auth *finance.Transaction) bool {
+	return auth.Status == finance.StatusAuthorized &&
+		!auth.OccurredAt.After(reversal.OccurredAt) &&
+		sameAccount(auth.Account, reversal.Account) &&
+		auth.Amount == reversal.Amount &&
+		sameMerchant(auth, reversal)
+}
+
+// sameAccount ignores leading zeros, which the sheet drops from numeric
+// looking cells.
+func sameAccount(a, b string) bool {
+	return strings.TrimLeft(a, "0") == strings.TrimLeft(b, "0")
+}
+
+func sameMerchant(a, b *finance.Transaction) bool {
+	if a.Merchant != "" && b.Merchant != "" {
+		return strings.EqualFold(a.Merchant, b.Merchant)
+	}
+	return strings.EqualFold(strings.Join(strings.Fields(a.Counterparty), " "), strings.Join(strings.Fields(b.Counterparty), " "))
+}
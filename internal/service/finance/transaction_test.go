@@ -0,0 +1,112 @@
+package finance_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/finance"
+	financeService "auto-finance/internal/service/finance"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ledger is an in-memory storage.TransactionLedger.
+type ledger struct {
+	saved []*finance.Transaction
+}
+
+func (l *ledger) Save(_ context.Context, txn *finance.Transaction) error {
+	stored := *txn
+	l.saved = append(l.saved, &stored)
+	return nil
+}
+
+func (l *ledger) Since(_ context.Context, institution string, since time.Time) ([]*finance.Transaction, error) {
+	var txns []*finance.Transaction
+	for _, txn := range l.saved {
+		if txn.Institution == institution && !txn.OccurredAt.Before(since) {
+			found := *txn
+			txns = append(txns, &found)
+		}
+	}
+	return txns, nil
+}
+
+func (l *ledger) UpdateStatus(_ context.Context, txn *finance.Transaction, status string) error {
+	for _, stored := range l.saved {
+		if stored.SourceMessageID == txn.SourceMessageID {
+			stored.Status = status
+		}
+	}
+	return nil
+}
+
+func TestHandleTransaction_Reversal(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2025, time.November, 7, 18, 0, 0, 0, time.UTC)
+	card := func(status string, amount int64, counterparty string, at time.Time) *finance.Transaction {
+		direction := finance.DirectionDebit
+		if status == finance.StatusReversed {
+			direction = finance.DirectionCredit
+		}
+		return &finance.Transaction{
+			Institution:     finance.InstitutionSampath,
+			Account:         "1234",
+			Channel:         finance.TransactionTypeCard,
+			Direction:       direction,
+			Amount:          models.NewMoney(amount, "LKR"),
+			Counterparty:    counterparty,
+			Status:          status,
+			SourceMessageID: uuid.New(),
+			OccurredAt:      at,
+		}
+	}
+
+	store := &ledger{}
+	svc := financeService.NewTransactionService(&financeService.Config{
+		Logger:         zerolog.Nop(),
+		Storage:        store,
+		ReversalWindow: 48 * time.Hour,
+	})
+	ctx := context.Background()
+
+	old := card(finance.StatusAuthorized, 150000, "MASKED CAFE", base.Add(-72*time.Hour))
+	other := card(finance.StatusAuthorized, 99900, "MASKED CAFE", base.Add(-2*time.Hour))
+	first := card(finance.StatusAuthorized, 150000, "MASKED CAFE", base.Add(-3*time.Hour))
+	latest := card(finance.StatusAuthorized, 150000, "MASKED  CAFE", base.Add(-1*time.Hour))
+	declined := card(finance.StatusDeclined, 150000, "MASKED CAFE", base.Add(-30*time.Minute))
+	for _, txn := range []*finance.Transaction{old, other, first, latest, declined} {
+		require.NoError(t, svc.HandleTransaction(ctx, txn))
+	}
+
+	reversal := card(finance.StatusReversed, 150000, "masked cafe", base)
+	require.NoError(t, svc.HandleTransaction(ctx, reversal))
+	assert.Equal(t, latest.SourceMessageID.String(), reversal.Extensions[finance.ExtensionReversalOf])
+
+	statuses := make(map[uuid.UUID]string)
+	for _, txn := range store.saved {
+		statuses[txn.SourceMessageID] = txn.Status
+	}
+	assert.Equal(t, finance.StatusReversed, statuses[latest.SourceMessageID], "latest matching authorization is reversed")
+	assert.Equal(t, finance.StatusAuthorized, statuses[first.SourceMessageID])
+	assert.Equal(t, finance.StatusAuthorized, statuses[other.SourceMessageID], "different amount")
+	assert.Equal(t, finance.StatusAuthorized, statuses[old.SourceMessageID], "outside the window")
+	assert.Equal(t, finance.StatusDeclined, statuses[declined.SourceMessageID])
+
+	// A second reversal links to the next authorization; a third finds none
+	// and is saved unlinked.
+	second := card(finance.StatusReversed, 150000, "MASKED CAFE", base.Add(time.Minute))
+	require.NoError(t, svc.HandleTransaction(ctx, second))
+	assert.Equal(t, first.SourceMessageID.String(), second.Extensions[finance.ExtensionReversalOf])
+
+	third := card(finance.StatusReversed, 150000, "MASKED CAFE", base.Add(2*time.Minute))
+	require.NoError(t, svc.HandleTransaction(ctx, third))
+	assert.Empty(t, third.Extensions[finance.ExtensionReversalOf])
+	assert.Len(t, store.saved, 8)
+}
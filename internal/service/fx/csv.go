@@ -0,0 +1,94 @@
+package fx
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CSV serves daily rates read from a CSV file with the columns date
+// (2006-01-02), currency and rate, the rate being base currency units per
+// unit of the currency. A header row is allowed.
+type CSV struct {
+	base  string
+	rates map[string][]datedRate
+}
+
+type datedRate struct {
+	date string
+	rate *big.Rat
+}
+
+// NewCSV reads the rates file at path.
+func NewCSV(base, path string) (*CSV, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rates file: %w", err)
+	}
+	defer f.Close()
+
+	c, err := ReadCSV(base, f)
+	if err != nil {
+		return nil, fmt.Errorf("rates file %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// ReadCSV reads rates in the NewCSV format from r.
+func ReadCSV(base string, r io.Reader) (*CSV, error) {
+	c := &CSV{base: strings.ToUpper(base), rates: make(map[string][]datedRate)}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		date, err := time.Parse(time.DateOnly, record[0])
+		if err != nil {
+			if line == 1 {
+				continue // header
+			}
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rate, err := ParseRate(record[2])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		currency := strings.ToUpper(strings.TrimSpace(record[1]))
+		c.rates[currency] = append(c.rates[currency], datedRate{date: date.Format(time.DateOnly), rate: rate})
+	}
+
+	for _, rates := range c.rates {
+		sort.SliceStable(rates, func(i, j int) bool { return rates[i].date < rates[j].date })
+	}
+	return c, nil
+}
+
+// Rate uses the latest rate on or before the day, so weekends and holidays
+// use the last published rate.
+func (c *CSV) Rate(_ context.Context, from, to string, on time.Time) (*big.Rat, error) {
+	day := on.Format(time.DateOnly)
+	return crossRate(c.base, from, to, func(currency string) (*big.Rat, bool) {
+		rates := c.rates[currency]
+		i := sort.Search(len(rates), func(i int) bool { return rates[i].date > day })
+		if i == 0 {
+			return nil, false
+		}
+		return rates[i-1].rate, true
+	})
+}
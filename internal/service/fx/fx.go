@@ -0,0 +1,122 @@
+// Package fx converts transaction amounts into a base currency using rates
+// from a pluggable provider.
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/finance"
+
+	"github.com/rs/zerolog"
+)
+
+// ErrNoRate is returned by providers that have no rate for a currency pair
+// on a date.
+var ErrNoRate = errors.New("no exchange rate")
+
+// Provider looks up exchange rates.
+type Provider interface {
+	// Rate returns how many units of to one unit of from was worth on the
+	// given day.
+	Rate(ctx context.Context, from, to string, on time.Time) (*big.Rat, error)
+}
+
+type Config struct {
+	Logger zerolog.Logger
+	// Base is the home currency every transaction is converted into.
+	Base     string
+	Provider Provider
+	// Location decides the day of a transaction, UTC when nil.
+	Location *time.Location
+}
+
+// Converter fills in the base currency amount of transactions. It
+// implements finance.Enricher.
+type Converter struct {
+	logger   zerolog.Logger
+	base     string
+	provider Provider
+	location *time.Location
+}
+
+func New(c *Config) (*Converter, error) {
+	base := strings.ToUpper(strings.TrimSpace(c.Base))
+	if base == "" {
+		return nil, fmt.Errorf("fx: base currency is required")
+	}
+	if c.Provider == nil {
+		return nil, fmt.Errorf("fx: provider is required")
+	}
+
+	location := c.Location
+	if location == nil {
+		location = time.UTC
+	}
+
+	return &Converter{logger: c.Logger, base: base, provider: c.Provider, location: location}, nil
+}
+
+// Enrich sets Converted and FXRate. A transaction without a rate is saved
+// unconverted and logged; other provider errors fail the transaction so
+// that it can be retried.
+func (c *Converter) Enrich(ctx context.Context, txn *finance.Transaction) error {
+	from := txn.Amount.Currency
+	if from == "" {
+		return nil
+	}
+	if from == c.base {
+		txn.Converted = txn.Amount
+		txn.FXRate = "1"
+		return nil
+	}
+
+	on := txn.OccurredAt.In(c.location)
+	rate, err := c.provider.Rate(ctx, from, c.base, on)
+	if errors.Is(err, ErrNoRate) {
+		c.logger.Warn().Str("currency", from).Str("date", on.Format(time.DateOnly)).Msg("No exchange rate, leaving transaction unconverted")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up %s/%s rate: %w", from, c.base, err)
+	}
+
+	txn.Converted = Convert(txn.Amount, rate, c.base)
+	txn.FXRate = FormatRate(rate)
+	return nil
+}
+
+// Convert multiplies amount by rate into currency to, rounding half away
+// from zero to the minor unit of to.
+func Convert(amount models.Money, rate *big.Rat, to string) models.Money {
+	v := new(big.Rat).SetFrac(big.NewInt(amount.Minor), pow10(models.MinorDigits(amount.Currency)))
+	v.Mul(v, rate)
+	v.Mul(v, new(big.Rat).SetInt(pow10(models.MinorDigits(to))))
+	return models.RoundMoney(v, to)
+}
+
+// ParseRate parses a positive decimal rate such as "302.15".
+func ParseRate(s string) (*big.Rat, error) {
+	rate, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid exchange rate %q", s)
+	}
+	return rate, nil
+}
+
+// FormatRate prints a rate with up to eight decimal places and no trailing
+// zeros.
+func FormatRate(rate *big.Rat) string {
+	s := rate.FloatString(8)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,172 @@
+package fx_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/service/fx"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rate(t *testing.T, s string) *big.Rat {
+	t.Helper()
+	r, err := fx.ParseRate(s)
+	require.NoError(t, err)
+	return r
+}
+
+func TestConvert(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		amount models.Money
+		rate   string
+		to     string
+		want   models.Money
+	}{
+		{name: "USD to LKR", amount: models.NewMoney(103, "USD"), rate: "302.15", to: "LKR", want: models.NewMoney(31121, "LKR")},
+		{name: "rounds half away from zero", amount: models.NewMoney(1, "USD"), rate: "0.5", to: "LKR", want: models.NewMoney(1, "LKR")},
+		{name: "negative", amount: models.NewMoney(-1, "USD"), rate: "0.5", to: "LKR", want: models.NewMoney(-1, "LKR")},
+		{name: "zero decimal currency", amount: models.NewMoney(1000, "JPY"), rate: "2.0123", to: "LKR", want: models.NewMoney(201230, "LKR")},
+		{name: "three decimal currency", amount: models.NewMoney(1500, "KWD"), rate: "985.5", to: "LKR", want: models.NewMoney(147825, "LKR")},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, fx.Convert(tt.amount, rate(t, tt.rate), tt.to))
+		})
+	}
+}
+
+type failingProvider struct{ err error }
+
+func (p failingProvider) Rate(context.Context, string, string, time.Time) (*big.Rat, error) {
+	return nil, p.err
+}
+
+func TestConverter_Enrich(t *testing.T) {
+	t.Parallel()
+
+	static, err := fx.NewStatic("LKR", map[string]string{"USD": "302.15"})
+	require.NoError(t, err)
+	c, err := fx.New(&fx.Config{Logger: zerolog.Nop(), Base: "lkr", Provider: static})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	usd := &finance.Transaction{Amount: models.NewMoney(103, "USD")}
+	require.NoError(t, c.Enrich(ctx, usd))
+	assert.Equal(t, models.NewMoney(103, "USD"), usd.Amount, "original amount is kept")
+	assert.Equal(t, models.NewMoney(31121, "LKR"), usd.Converted)
+	assert.Equal(t, "302.15", usd.FXRate)
+
+	lkr := &finance.Transaction{Amount: models.NewMoney(150000, "LKR")}
+	require.NoError(t, c.Enrich(ctx, lkr))
+	assert.Equal(t, lkr.Amount, lkr.Converted)
+	assert.Equal(t, "1", lkr.FXRate)
+
+	eur := &finance.Transaction{Amount: models.NewMoney(500, "EUR")}
+	require.NoError(t, c.Enrich(ctx, eur), "missing rates leave the transaction unconverted")
+	assert.True(t, eur.Converted.IsZero())
+
+	down, err := fx.New(&fx.Config{Logger: zerolog.Nop(), Base: "LKR", Provider: failingProvider{err: errors.New("timeout")}})
+	require.NoError(t, err)
+	assert.Error(t, down.Enrich(ctx, &finance.Transaction{Amount: models.NewMoney(103, "USD")}))
+}
+
+func TestStatic_CrossRate(t *testing.T) {
+	t.Parallel()
+
+	s, err := fx.NewStatic("LKR", map[string]string{"USD": "300", "EUR": "330"})
+	require.NoError(t, err)
+
+	r, err := s.Rate(context.Background(), "EUR", "USD", time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, "1.1", fx.FormatRate(r))
+
+	r, err = s.Rate(context.Background(), "LKR", "USD", time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, "0.00333333", fx.FormatRate(r))
+
+	_, err = s.Rate(context.Background(), "GBP", "LKR", time.Time{})
+	assert.ErrorIs(t, err, fx.ErrNoRate)
+
+	_, err = fx.NewStatic("LKR", map[string]string{"USD": "-1"})
+	assert.Error(t, err)
+}
+
+func TestCSV_Rate(t *testing.T) {
+	t.Parallel()
+
+	c, err := fx.ReadCSV("LKR", strings.NewReader(`date,currency,rate
+2025-11-07,USD,301.50
+2025-11-05,USD,300.00
+2025-11-10,USD,303.25
+2025-11-07,EUR,349.10
+`))
+	require.NoError(t, err)
+
+	day := func(d int) time.Time { return time.Date(2025, time.November, d, 12, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		on   time.Time
+		want string
+	}{
+		{on: day(5), want: "300"},
+		{on: day(7), want: "301.5"},
+		{on: day(9), want: "301.5"}, // weekend uses the last published rate
+		{on: day(10), want: "303.25"},
+	}
+	for _, tt := range tests {
+		r, err := c.Rate(context.Background(), "USD", "LKR", tt.on)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, fx.FormatRate(r), tt.on.Format(time.DateOnly))
+	}
+
+	_, err = c.Rate(context.Background(), "USD", "LKR", day(4))
+	assert.ErrorIs(t, err, fx.ErrNoRate)
+
+	_, err = fx.ReadCSV("LKR", strings.NewReader("2025-11-07,USD,abc\n"))
+	assert.Error(t, err)
+}
+
+func TestHTTP_Rate(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Query().Get("from") == "XXX" {
+			http.NotFound(w, r)
+			return
+		}
+		assert.Equal(t, "/2025-11-07", r.URL.Path)
+		_, _ = w.Write([]byte(`{"amount":1.0,"base":"USD","date":"2025-11-07","rates":{"LKR":302.15}}`))
+	}))
+	defer srv.Close()
+
+	h := fx.NewHTTP(&fx.HTTPConfig{URL: srv.URL + "/{date}?from={from}&to={to}"})
+	on := time.Date(2025, time.November, 7, 18, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 2; i++ {
+		r, err := h.Rate(context.Background(), "usd", "LKR", on)
+		require.NoError(t, err)
+		assert.Equal(t, "302.15", fx.FormatRate(r))
+	}
+	assert.Equal(t, 1, calls, "rates are cached")
+
+	_, err := h.Rate(context.Background(), "XXX", "LKR", on)
+	assert.ErrorIs(t, err, fx.ErrNoRate)
+}
@@ -0,0 +1,104 @@
+package fx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type HTTPConfig struct {
+	// URL is a template in which {from}, {to} and {date} (2006-01-02) are
+	// replaced, e.g. "https://api.frankfurter.app/{date}?from={from}&to={to}".
+	// The response must be a JSON object with a "rates" object keyed by
+	// currency, e.g. {"rates": {"LKR": 302.15}}.
+	URL    string
+	Client *http.Client
+}
+
+// HTTP looks rates up from a rates API, remembering each answer for the
+// life of the process.
+type HTTP struct {
+	url    string
+	client *http.Client
+
+	mu    sync.Mutex
+	cache map[string]*big.Rat
+}
+
+func NewHTTP(c *HTTPConfig) *HTTP {
+	client := c.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &HTTP{url: c.URL, client: client, cache: make(map[string]*big.Rat)}
+}
+
+type ratesResponse struct {
+	Rates map[string]json.Number `json:"rates"`
+}
+
+func (h *HTTP) Rate(ctx context.Context, from, to string, on time.Time) (*big.Rat, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	day := on.Format(time.DateOnly)
+	key := day + "|" + from + "|" + to
+
+	h.mu.Lock()
+	rate, ok := h.cache[key]
+	h.mu.Unlock()
+	if ok {
+		return rate, nil
+	}
+
+	u := strings.NewReplacer(
+		"{from}", url.QueryEscape(from),
+		"{to}", url.QueryEscape(to),
+		"{date}", day,
+	).Replace(h.url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w for %s/%s on %s", ErrNoRate, from, to, day)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("rates API returned %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+
+	var body ratesResponse
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode rates response: %w", err)
+	}
+
+	raw, ok := body.Rates[to]
+	if !ok {
+		return nil, fmt.Errorf("%w for %s/%s on %s", ErrNoRate, from, to, day)
+	}
+	rate, err = ParseRate(raw.String())
+	if err != nil {
+		return nil, err
+	}
+
+	h.mu.Lock()
+	h.cache[key] = rate
+	h.mu.Unlock()
+	return rate, nil
+}
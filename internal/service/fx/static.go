@@ -0,0 +1,66 @@
+package fx
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Static serves fixed rates against one base currency, whatever the date.
+type Static struct {
+	base  string
+	rates map[string]*big.Rat
+}
+
+// NewStatic parses rates, given as base currency units per unit of each
+// currency (e.g. {"USD": "302.15"} for an LKR base).
+func NewStatic(base string, rates map[string]string) (*Static, error) {
+	s := &Static{base: strings.ToUpper(base), rates: make(map[string]*big.Rat, len(rates))}
+	for currency, raw := range rates {
+		rate, err := ParseRate(raw)
+		if err != nil {
+			return nil, fmt.Errorf("rate for %s: %w", currency, err)
+		}
+		s.rates[strings.ToUpper(currency)] = rate
+	}
+	return s, nil
+}
+
+func (s *Static) Rate(_ context.Context, from, to string, _ time.Time) (*big.Rat, error) {
+	return crossRate(s.base, from, to, func(currency string) (*big.Rat, bool) {
+		rate, ok := s.rates[currency]
+		return rate, ok
+	})
+}
+
+// crossRate converts between any two currencies given the rate of each
+// against base.
+func crossRate(base, from, to string, rate func(currency string) (*big.Rat, bool)) (*big.Rat, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return big.NewRat(1, 1), nil
+	}
+
+	toBase := func(currency string) (*big.Rat, error) {
+		if currency == base {
+			return big.NewRat(1, 1), nil
+		}
+		r, ok := rate(currency)
+		if !ok {
+			return nil, fmt.Errorf("%w for %s", ErrNoRate, currency)
+		}
+		return r, nil
+	}
+
+	fromRate, err := toBase(from)
+	if err != nil {
+		return nil, err
+	}
+	toRate, err := toBase(to)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Rat).Quo(fromRate, toRate), nil
+}
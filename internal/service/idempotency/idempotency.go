@@ -0,0 +1,149 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"auto-finance/internal/service/message"
+	"auto-finance/internal/storage"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// DefaultWindow is the received time bucket used when hashing message
+	// content. Forwarder retries land in the same or the next bucket.
+	DefaultWindow = 5 * time.Minute
+	// DefaultTTL is how long a client supplied message ID is remembered.
+	DefaultTTL = 7 * 24 * time.Hour
+)
+
+type Config struct {
+	Logger  zerolog.Logger
+	Next    message.Service
+	Storage storage.IdempotencyStorage
+	Window  time.Duration
+	TTL     time.Duration
+}
+
+type service struct {
+	logger  zerolog.Logger
+	next    message.Service
+	storage storage.IdempotencyStorage
+	window  time.Duration
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+// New wraps a message service so that a message is passed on at most once.
+// Duplicates are reported with message.ErrDuplicate.
+func New(c *Config) message.Service {
+	window := c.Window
+	if window <= 0 {
+		window = DefaultWindow
+	}
+
+	ttl := c.TTL
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	return &service{
+		logger:  c.Logger,
+		next:    c.Next,
+		storage: c.Storage,
+		window:  window,
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
+
+func (s *service) PassMessage(ctx context.Context, msg message.Message) (message.Result, error) {
+	now := s.now()
+	if msg.ReceivedAt.IsZero() {
+		msg.ReceivedAt = now
+	}
+
+	keys, expiresAt := s.keys(msg, now)
+	key := keys[0]
+
+	reserved, err := s.reserve(ctx, keys, expiresAt)
+	if err != nil {
+		// Losing a message is worse than recording it twice, so carry on
+		// without deduplication when the store is unavailable.
+		s.logger.Warn().Err(err).Str("key", key).Msg("Failed to reserve idempotency key, processing without deduplication")
+		return s.next.PassMessage(ctx, msg)
+	}
+
+	if !reserved {
+		s.logger.Info().Str("key", key).Str("sender", msg.Sender).Msg("Duplicate message skipped")
+		return message.Result{}, fmt.Errorf("%w: %s", message.ErrDuplicate, key)
+	}
+
+	result, err := s.next.PassMessage(ctx, msg)
+	if err != nil {
+		// Forget the keys so the sender can retry the failed message.
+		s.release(ctx, keys)
+		return result, err
+	}
+
+	return result, nil
+}
+
+// keys returns the idempotency keys for msg and when they expire. A client
+// supplied message ID is used as is; otherwise the keys are hashes of the
+// sender, the whitespace-normalized body and the received time window. A
+// message takes the key of its window and of the next one, so a retry just
+// across a window boundary still shares a key with the original.
+func (s *service) keys(msg message.Message, now time.Time) ([]string, time.Time) {
+	sender := message.NormalizeSender(msg.Sender)
+
+	if msg.ExternalID != "" {
+		return []string{"id:" + sender + ":" + msg.ExternalID}, now.Add(s.ttl)
+	}
+
+	return []string{
+		ContentKey(sender, msg.Body, msg.ReceivedAt, s.window),
+		ContentKey(sender, msg.Body, msg.ReceivedAt.Add(s.window), s.window),
+	}, now.Add(2 * s.window)
+}
+
+// reserve reserves every key, or none when one is already taken.
+func (s *service) reserve(ctx context.Context, keys []string, expiresAt time.Time) (bool, error) {
+	for i, key := range keys {
+		reserved, err := s.storage.Reserve(ctx, key, expiresAt)
+		if err != nil || !reserved {
+			s.release(ctx, keys[:i])
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (s *service) release(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := s.storage.Release(ctx, key); err != nil {
+			s.logger.Error().Err(err).Str("key", key).Msg("Failed to release idempotency key")
+		}
+	}
+}
+
+// ContentKey hashes a message's content and the window holding receivedAt
+// into an idempotency key.
+func ContentKey(sender, body string, receivedAt time.Time, window time.Duration) string {
+	bucket := receivedAt.Truncate(window).Unix()
+
+	h := sha256.New()
+	h.Write([]byte(message.NormalizeSender(sender)))
+	h.Write([]byte{0})
+	h.Write([]byte(strings.Join(strings.Fields(body), " ")))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.FormatInt(bucket, 10)))
+
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,125 @@
+package idempotency_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"auto-finance/internal/service/idempotency"
+	"auto-finance/internal/service/message"
+	idempotencyStorage "auto-finance/internal/storage/idempotency"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingService struct {
+	calls int
+	err   error
+}
+
+func (c *countingService) PassMessage(context.Context, message.Message) (message.Result, error) {
+	c.calls++
+	return message.Result{}, c.err
+}
+
+func TestService_SkipsDuplicates(t *testing.T) {
+	next := &countingService{}
+	svc := idempotency.New(&idempotency.Config{
+		Logger:  zerolog.Nop(),
+		Next:    next,
+		Storage: idempotencyStorage.NewMemory(),
+	})
+
+	receivedAt := time.Date(2025, time.November, 7, 18, 42, 10, 0, time.UTC)
+	msg := message.Message{Sender: "SAMPATH", Body: "Cr Crd no..**1234 Auth Pmt LKR 1.00", ReceivedAt: receivedAt}
+
+	_, err := svc.PassMessage(context.Background(), msg)
+	require.NoError(t, err)
+
+	// Same content posted again a few seconds later with different spacing.
+	retry := msg
+	retry.Sender = " sampath"
+	retry.Body = "Cr Crd  no..**1234\nAuth Pmt LKR 1.00"
+	retry.ReceivedAt = receivedAt.Add(5 * time.Second)
+	_, err = svc.PassMessage(context.Background(), retry)
+	assert.ErrorIs(t, err, message.ErrDuplicate)
+	assert.Equal(t, 1, next.calls)
+
+	// The same body in a later window is a new transaction.
+	later := msg
+	later.ReceivedAt = receivedAt.Add(time.Hour)
+	_, err = svc.PassMessage(context.Background(), later)
+	require.NoError(t, err)
+	assert.Equal(t, 2, next.calls)
+}
+
+func TestService_SkipsDuplicatesAcrossWindowBoundary(t *testing.T) {
+	boundary := time.Date(2025, time.November, 7, 18, 45, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		name          string
+		first, second time.Time
+	}{
+		{name: "forward", first: boundary.Add(-time.Second), second: boundary.Add(time.Second)},
+		{name: "backward", first: boundary.Add(time.Second), second: boundary.Add(-time.Second)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &countingService{}
+			svc := idempotency.New(&idempotency.Config{
+				Logger:  zerolog.Nop(),
+				Next:    next,
+				Storage: idempotencyStorage.NewMemory(),
+				Window:  5 * time.Minute,
+			})
+
+			msg := message.Message{Sender: "SAMPATH", Body: "Cr Crd no..**1234 Auth Pmt LKR 1.00", ReceivedAt: tt.first}
+			_, err := svc.PassMessage(context.Background(), msg)
+			require.NoError(t, err)
+
+			msg.ReceivedAt = tt.second
+			_, err = svc.PassMessage(context.Background(), msg)
+			assert.ErrorIs(t, err, message.ErrDuplicate)
+			assert.Equal(t, 1, next.calls)
+		})
+	}
+}
+
+func TestService_UsesClientMessageID(t *testing.T) {
+	next := &countingService{}
+	svc := idempotency.New(&idempotency.Config{
+		Logger:  zerolog.Nop(),
+		Next:    next,
+		Storage: idempotencyStorage.NewMemory(),
+	})
+
+	first := message.Message{Sender: "HNB", Body: "a", ExternalID: "sms-42", ReceivedAt: time.Now()}
+	second := message.Message{Sender: "HNB", Body: "a", ExternalID: "sms-42", ReceivedAt: time.Now().Add(24 * time.Hour)}
+
+	_, err := svc.PassMessage(context.Background(), first)
+	require.NoError(t, err)
+	_, err = svc.PassMessage(context.Background(), second)
+	assert.ErrorIs(t, err, message.ErrDuplicate)
+	assert.Equal(t, 1, next.calls)
+}
+
+func TestService_ReleasesKeyOnFailure(t *testing.T) {
+	next := &countingService{err: errors.New("sheet unavailable")}
+	svc := idempotency.New(&idempotency.Config{
+		Logger:  zerolog.Nop(),
+		Next:    next,
+		Storage: idempotencyStorage.NewMemory(),
+	})
+
+	msg := message.Message{Sender: "LECO", Body: "bill", ExternalID: "sms-1"}
+
+	_, err := svc.PassMessage(context.Background(), msg)
+	assert.Error(t, err)
+
+	next.err = nil
+	_, err = svc.PassMessage(context.Background(), msg)
+	assert.NoError(t, err, "a failed message must be retryable")
+	assert.Equal(t, 2, next.calls)
+}
@@ -2,59 +2,140 @@ package message
 
 import (
 	"context"
-	"errors"
+	stdErrors "errors"
 	"fmt"
+	"strings"
+	"time"
 
-	ebillModel "auto-finance/internal/models/ebill"
-	financeModel "auto-finance/internal/models/finance"
-	"auto-finance/internal/service/ebill"
-	"auto-finance/internal/service/finance"
+	"auto-finance/internal/errors"
 	"auto-finance/internal/smsparser"
 
+	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 )
 
+// ErrUnknownSender is returned when sender routing is configured and the
+// message comes from a sender that has no route.
+var ErrUnknownSender = stdErrors.New("unknown sender")
+
+// ErrDuplicate is returned when a message has already been processed.
+var ErrDuplicate = stdErrors.New("duplicate message")
+
 type Message struct {
-	Sender string `json:"sender"`
-	Body   string `json:"body"`
+	// ID identifies the raw message in the audit log.
+	ID     uuid.UUID `json:"id"`
+	Sender string    `json:"sender"`
+	Body   string    `json:"body"`
+	// ReceivedAt is when the phone received the SMS. The zero value means
+	// "now".
+	ReceivedAt time.Time `json:"received_at,omitempty"`
+	// ExternalID is an optional client supplied message ID used for
+	// deduplication.
+	ExternalID string `json:"external_id,omitempty"`
+}
+
+// Result describes how a message was handled.
+type Result struct {
+	// Parser is the name of the parser that recognized the message, or the
+	// routed parser that failed to.
+	Parser string `json:"parser,omitempty"`
 }
 
 type Service interface {
-	PassMessage(ctx context.Context, msg Message) error
+	PassMessage(ctx context.Context, msg Message) (Result, error)
 }
 type Config struct {
-	Logger             zerolog.Logger
-	Parsers            []smsparser.UniversalParser
-	LecoBillService    ebill.LECOBillService
-	SampathBankService finance.SampathBillService
+	Logger zerolog.Logger
+	// Registry holds the enabled parser plugins. When Routes is empty every
+	// plugin is tried in registration order.
+	Registry *smsparser.Registry
+	// Routes maps a normalized sender ID to the plugin responsible for it.
+	Routes map[string]smsparser.Plugin
 }
 type service struct {
-	logger             zerolog.Logger
-	parsers            []smsparser.UniversalParser
-	lecoBillService    ebill.LECOBillService
-	sampathBillService finance.SampathBillService
+	logger   zerolog.Logger
+	registry *smsparser.Registry
+	routes   map[string]smsparser.Plugin
 }
 
 func New(c *Config) Service {
+	registry := c.Registry
+	if registry == nil {
+		registry = smsparser.NewRegistry()
+	}
+
 	return &service{
-		logger:             c.Logger,
-		parsers:            c.Parsers,
-		lecoBillService:    c.LecoBillService,
-		sampathBillService: c.SampathBankService,
+		logger:   c.Logger,
+		registry: registry,
+		routes:   c.Routes,
 	}
 }
 
-func (s *service) PassMessage(ctx context.Context, msg Message) error {
+// sourced is implemented by parsed results that record the raw message they
+// came from.
+type sourced interface {
+	SetSourceMessageID(id uuid.UUID)
+}
+
+// NormalizeSender returns the canonical form of a sender ID used as the
+// routing key.
+func NormalizeSender(sender string) string {
+	return strings.ToUpper(strings.TrimSpace(sender))
+}
+
+// NewRoutes resolves the sender to parser name table from the configuration
+// against the registered plugins.
+func NewRoutes(senderRoutes map[string]string, registry *smsparser.Registry) (map[string]smsparser.Plugin, error) {
+	routes := make(map[string]smsparser.Plugin, len(senderRoutes))
+	for sender, name := range senderRoutes {
+		plugin, ok := registry.Get(name)
+		if !ok {
+			return nil, fmt.Errorf("sender %s routed to unknown or disabled parser %q", sender, name)
+		}
+		routes[NormalizeSender(sender)] = plugin
+	}
+	return routes, nil
+}
+
+func (s *service) PassMessage(ctx context.Context, msg Message) (Result, error) {
 	s.logger.Info().Ctx(ctx).Str("sender", msg.Sender).Msg("Processing message")
 
+	receivedAt := msg.ReceivedAt
+	if receivedAt.IsZero() {
+		receivedAt = time.Now()
+	}
+
+	if len(s.routes) > 0 {
+		plugin, ok := s.routes[NormalizeSender(msg.Sender)]
+		if !ok {
+			return Result{}, fmt.Errorf("%w: %s", ErrUnknownSender, msg.Sender)
+		}
+		parser := plugin.Parser()
+
+		result := Result{Parser: parser.GetName()}
+
+		obj, err := parser.ParseAt(msg.Body, receivedAt)
+		if err != nil {
+			return result, errors.NewTypedError(fmt.Errorf("parser %s failed: %w", parser.GetName(), err), errors.ErrorTypeParser)
+		}
+
+		if obj == nil {
+			return result, errors.NewTypedError(fmt.Errorf("parser %s returned nil for message: %s", parser.GetName(), msg.Body), errors.ErrorTypeParser)
+		}
+
+		return result, s.dispatch(ctx, plugin, msg, obj)
+	}
+
 	parseErrors := make([]error, 0)
 
-	if len(s.parsers) == 0 {
-		return fmt.Errorf("no parsers configured")
+	plugins := s.registry.Plugins()
+	if len(plugins) == 0 {
+		return Result{}, errors.NewTypedError(fmt.Errorf("no parsers configured"), errors.ErrorTypeConfig)
 	}
 
-	for _, parser := range s.parsers {
-		obj, err := parser.Parse(msg.Body)
+	for _, plugin := range plugins {
+		parser := plugin.Parser()
+		obj, err := parser.ParseAt(msg.Body, receivedAt)
 		if err != nil {
 			parseErrors = append(parseErrors, err)
 			continue
@@ -65,28 +146,21 @@ func (s *service) PassMessage(ctx context.Context, msg Message) error {
 			continue
 		}
 
-		parseErrors = nil // reset errors if we successfully parsed at least one message
-
-		switch v := obj.(type) {
-		case *ebillModel.ElectricityBill:
-			if err := s.lecoBillService.HandleLECOBill(ctx, v); err != nil {
-				s.logger.Error().Err(err).Msg("Failed to handle LECO bill")
-				return fmt.Errorf("failed to handle LECO bill: %w", err)
-			}
-
-			return nil
-		case *financeModel.SampathModel:
-			if err := s.sampathBillService.HandleSampathBill(ctx, v); err != nil {
-				s.logger.Error().Err(err).Msg("Failed to handle Sampath bill")
-				return fmt.Errorf("failed to handle Sampath bill: %w", err)
-			}
-
-			return nil
-		default:
-			s.logger.Warn().Msgf("Unknown object type: %T", v)
-		}
+		return Result{Parser: parser.GetName()}, s.dispatch(ctx, plugin, msg, obj)
+	}
+
+	return Result{}, errors.NewTypedError(stdErrors.Join(parseErrors...), errors.ErrorTypeParser)
+}
+
+func (s *service) dispatch(ctx context.Context, plugin smsparser.Plugin, msg Message, obj interface{}) error {
+	if v, ok := obj.(sourced); ok {
+		v.SetSourceMessageID(msg.ID)
+	}
 
+	if err := plugin.Handle(ctx, obj); err != nil {
+		s.logger.Error().Err(err).Str("plugin", plugin.Name()).Msg("Failed to handle parsed message")
+		return fmt.Errorf("failed to handle %s message: %w", plugin.Name(), err)
 	}
 
-	return errors.Join(parseErrors...)
+	return nil
 }
@@ -0,0 +1,147 @@
+package message_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	appErrors "auto-finance/internal/errors"
+	ebillModel "auto-finance/internal/models/ebill"
+	financeModel "auto-finance/internal/models/finance"
+	"auto-finance/internal/service/message"
+	"auto-finance/internal/smsparser"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubParser struct {
+	name  string
+	obj   interface{}
+	err   error
+	calls int
+}
+
+func (p *stubParser) GetName() string { return p.name }
+
+func (p *stubParser) Parse(string) (interface{}, error) {
+	p.calls++
+	return p.obj, p.err
+}
+
+func (p *stubParser) ParseAt(body string, _ time.Time) (interface{}, error) {
+	return p.Parse(body)
+}
+
+type recordingBillService struct {
+	transactions []*financeModel.Transaction
+	leco         []*ebillModel.ElectricityBill
+}
+
+func (r *recordingBillService) handle(_ context.Context, v interface{}) error {
+	switch v := v.(type) {
+	case *financeModel.Transaction:
+		r.transactions = append(r.transactions, v)
+	case *ebillModel.ElectricityBill:
+		r.leco = append(r.leco, v)
+	}
+	return nil
+}
+
+func newRegistry(t *testing.T, recorder *recordingBillService, parsers ...*stubParser) *smsparser.Registry {
+	t.Helper()
+
+	registry := smsparser.NewRegistry()
+	for _, p := range parsers {
+		require.NoError(t, smsparser.Register(registry, smsparser.Registration[interface{}]{
+			Name:   p.name,
+			Parser: p,
+			Handle: recorder.handle,
+		}))
+	}
+	return registry
+}
+
+func TestPassMessage_Routing(t *testing.T) {
+	sampathParser := &stubParser{name: "sampath", obj: &financeModel.Transaction{Account: "1234"}}
+	lecoParser := &stubParser{name: "leco", err: errors.New("not a leco bill")}
+
+	recorder := &recordingBillService{}
+	registry := newRegistry(t, recorder, lecoParser, sampathParser)
+
+	routes, err := message.NewRoutes(map[string]string{
+		"sampath": "Sampath",
+		" LECO ":  "leco",
+	}, registry)
+	require.NoError(t, err)
+
+	svc := message.New(&message.Config{
+		Logger:   zerolog.Nop(),
+		Registry: registry,
+		Routes:   routes,
+	})
+
+	t.Run("routes by sender", func(t *testing.T) {
+		id := uuid.New()
+		result, err := svc.PassMessage(context.Background(), message.Message{ID: id, Sender: "Sampath", Body: "body"})
+		require.NoError(t, err)
+		assert.Equal(t, "sampath", result.Parser)
+		require.Len(t, recorder.transactions, 1)
+		assert.Equal(t, id, recorder.transactions[0].SourceMessageID)
+		assert.Equal(t, 0, lecoParser.calls, "leco parser must not run for a sampath sender")
+	})
+
+	t.Run("unknown sender is rejected", func(t *testing.T) {
+		_, err := svc.PassMessage(context.Background(), message.Message{Sender: "UNKNOWN", Body: "body"})
+		assert.ErrorIs(t, err, message.ErrUnknownSender)
+	})
+
+	t.Run("routed parser error is returned alone", func(t *testing.T) {
+		_, err := svc.PassMessage(context.Background(), message.Message{Sender: "leco", Body: "body"})
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, message.ErrUnknownSender)
+		assert.Contains(t, err.Error(), "not a leco bill")
+		assert.Equal(t, 1, sampathParser.calls)
+	})
+}
+
+func TestPassMessage_FallbackWithoutRoutes(t *testing.T) {
+	recorder := &recordingBillService{}
+	svc := message.New(&message.Config{
+		Logger: zerolog.Nop(),
+		Registry: newRegistry(t, recorder,
+			&stubParser{name: "sampath", err: errors.New("no match")},
+			&stubParser{name: "leco", obj: &ebillModel.ElectricityBill{AccountNumber: "1"}},
+		),
+	})
+
+	_, err := svc.PassMessage(context.Background(), message.Message{Sender: "ANYONE", Body: "body"})
+	require.NoError(t, err)
+	assert.Len(t, recorder.leco, 1)
+}
+
+func TestPassMessage_ValidationFailure(t *testing.T) {
+	recorder := &recordingBillService{}
+	registry := smsparser.NewRegistry()
+	require.NoError(t, smsparser.Register(registry, smsparser.Registration[interface{}]{
+		Name:     "sampath",
+		Parser:   &stubParser{name: "sampath", obj: &financeModel.Transaction{}},
+		Validate: func(interface{}) error { return errors.New("account is required") },
+		Handle:   recorder.handle,
+	}))
+
+	svc := message.New(&message.Config{Logger: zerolog.Nop(), Registry: registry})
+
+	_, err := svc.PassMessage(context.Background(), message.Message{Sender: "SAMPATH", Body: "body"})
+	require.Error(t, err)
+	assert.Equal(t, appErrors.ErrorTypeValidation, appErrors.ErrorTypeOf(err))
+	assert.Empty(t, recorder.transactions)
+}
+
+func TestNewRoutes_UnknownParser(t *testing.T) {
+	_, err := message.NewRoutes(map[string]string{"BOC": "boc"}, smsparser.NewRegistry())
+	assert.Error(t, err)
+}
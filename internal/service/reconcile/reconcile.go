@@ -0,0 +1,142 @@
+// Package reconcile checks the balances reported in bank alerts against the
+// balance expected from the transactions recorded since the last report.
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"auto-finance/internal/events"
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/storage"
+
+	"github.com/rs/zerolog"
+)
+
+type Config struct {
+	Logger        zerolog.Logger
+	Balances      storage.BalanceStorage
+	Discrepancies storage.DiscrepancyStorage
+	Publisher     events.Publisher
+}
+
+// Service tracks the expected balance of every account and records a
+// discrepancy when an alert reports something else. It implements
+// finance.Hook.
+type Service struct {
+	logger        zerolog.Logger
+	balances      storage.BalanceStorage
+	discrepancies storage.DiscrepancyStorage
+	publisher     events.Publisher
+
+	// mu serializes the read-modify-write of balances.
+	mu sync.Mutex
+}
+
+func New(c *Config) *Service {
+	return &Service{
+		logger:        c.Logger,
+		balances:      c.Balances,
+		discrepancies: c.Discrepancies,
+		publisher:     c.Publisher,
+	}
+}
+
+// AfterSave moves the expected balance on by the transaction and, when the
+// alert reported a balance, compares the two and makes the reported balance
+// the new checkpoint.
+func (s *Service) AfterSave(ctx context.Context, txn *finance.Transaction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := txn.Institution + "|" + txn.Account
+	logger := s.logger.With().Str("institution", txn.Institution).Str("account", txn.Account).Logger()
+
+	prev, found, err := s.balances.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to read expected balance: %w", err)
+	}
+	if found && txn.OccurredAt.Before(prev.At) {
+		// A late or backfilled alert cannot be placed between the
+		// transactions already applied.
+		logger.Debug().Time("occurredAt", txn.OccurredAt).Msg("Skipping transaction older than the expected balance")
+		return nil
+	}
+
+	expected, known := models.Money{}, false
+	if found {
+		expected, err = prev.Expected.Add(change(txn))
+		known = err == nil
+	}
+
+	reported := txn.BalanceAfter
+	if reported.IsZero() {
+		if !known {
+			// Nothing to move on, e.g. a foreign currency purchase; wait for
+			// the next reported balance.
+			return s.balances.Delete(ctx, key)
+		}
+		return s.balances.Put(ctx, key, finance.Balance{Expected: expected, At: txn.OccurredAt, CheckpointAt: prev.CheckpointAt})
+	}
+
+	checkpoint := finance.Balance{Expected: reported, At: txn.OccurredAt, CheckpointAt: txn.OccurredAt}
+	if err := s.balances.Put(ctx, key, checkpoint); err != nil {
+		return err
+	}
+
+	if !known {
+		return nil
+	}
+	// A balance in another currency than the transactions cannot be
+	// compared and only starts a new checkpoint.
+	if cmp, err := reported.Cmp(expected); err != nil || cmp == 0 {
+		return nil
+	}
+
+	difference, _ := reported.Sub(expected)
+	return s.record(ctx, logger, &finance.Discrepancy{
+		Institution:     txn.Institution,
+		Account:         txn.Account,
+		Expected:        expected,
+		Reported:        reported,
+		Difference:      difference,
+		CheckpointAt:    prev.CheckpointAt,
+		OccurredAt:      txn.OccurredAt,
+		SourceMessageID: txn.SourceMessageID,
+	})
+}
+
+func (s *Service) record(ctx context.Context, logger zerolog.Logger, d *finance.Discrepancy) error {
+	logger.Warn().
+		Str("expected", d.Expected.String()).
+		Str("reported", d.Reported.String()).
+		Str("difference", d.Difference.String()).
+		Time("checkpointAt", d.CheckpointAt).
+		Msg("Reported balance does not match expected balance")
+
+	if s.discrepancies != nil {
+		if err := s.discrepancies.Save(ctx, d); err != nil {
+			return fmt.Errorf("failed to save balance discrepancy: %w", err)
+		}
+	}
+	if s.publisher != nil {
+		if err := s.publisher.Publish(ctx, events.Event{Type: events.TypeBalanceDiscrepancy, Data: d}); err != nil {
+			return fmt.Errorf("failed to publish balance discrepancy: %w", err)
+		}
+	}
+	return nil
+}
+
+// change is how a transaction moves the balance: debits lower it, credits
+// (including reversals) raise it and declines leave it alone.
+func change(txn *finance.Transaction) models.Money {
+	switch {
+	case txn.Status == finance.StatusDeclined:
+		return models.NewMoney(0, txn.Amount.Currency)
+	case txn.Direction == finance.DirectionCredit:
+		return txn.Amount
+	}
+	return txn.Amount.Neg()
+}
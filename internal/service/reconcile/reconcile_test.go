@@ -0,0 +1,112 @@
+package reconcile_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"auto-finance/internal/events"
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/service/reconcile"
+	"auto-finance/internal/storage/balance"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type discrepancies struct {
+	saved []*finance.Discrepancy
+}
+
+func (d *discrepancies) Save(_ context.Context, discrepancy *finance.Discrepancy) error {
+	d.saved = append(d.saved, discrepancy)
+	return nil
+}
+
+func (d *discrepancies) List(context.Context) ([]*finance.Discrepancy, error) {
+	return d.saved, nil
+}
+
+func lkr(rupees int64) models.Money {
+	return models.NewMoney(rupees*100, "LKR")
+}
+
+func TestService_AfterSave(t *testing.T) {
+	t.Parallel()
+
+	store := &discrepancies{}
+	var published []events.Event
+	bus := events.NewBus()
+	bus.Subscribe(func(_ context.Context, e events.Event) error {
+		published = append(published, e)
+		return nil
+	})
+
+	svc := reconcile.New(&reconcile.Config{
+		Logger:        zerolog.Nop(),
+		Balances:      balance.NewMemory(),
+		Discrepancies: store,
+		Publisher:     bus,
+	})
+
+	start := time.Date(2025, time.November, 7, 9, 0, 0, 0, time.UTC)
+	step := 0
+	card := func(status string, amount, balanceAfter models.Money) *finance.Transaction {
+		step++
+		direction := finance.DirectionDebit
+		if status == finance.StatusReversed {
+			direction = finance.DirectionCredit
+		}
+		return &finance.Transaction{
+			Institution:  finance.InstitutionSampath,
+			Account:      "1234",
+			Direction:    direction,
+			Amount:       amount,
+			BalanceAfter: balanceAfter,
+			Status:       status,
+			OccurredAt:   start.Add(time.Duration(step) * time.Hour),
+		}
+	}
+	ctx := context.Background()
+
+	for _, txn := range []*finance.Transaction{
+		card(finance.StatusAuthorized, lkr(1000), lkr(49000)), // first checkpoint
+		card(finance.StatusAuthorized, lkr(2000), models.Money{}),
+		card(finance.StatusAuthorized, lkr(500), lkr(46500)),
+		card(finance.StatusDeclined, lkr(300), lkr(46500)),
+		card(finance.StatusReversed, lkr(500), lkr(47000)),
+	} {
+		require.NoError(t, svc.AfterSave(ctx, txn))
+	}
+	assert.Empty(t, store.saved, "balances add up")
+
+	// 500 went missing, e.g. an unalerted fee.
+	missed := card(finance.StatusAuthorized, lkr(1000), lkr(45500))
+	require.NoError(t, svc.AfterSave(ctx, missed))
+	require.Len(t, store.saved, 1)
+	d := store.saved[0]
+	assert.Equal(t, lkr(46000), d.Expected)
+	assert.Equal(t, lkr(45500), d.Reported)
+	assert.Equal(t, lkr(-500), d.Difference)
+	assert.True(t, d.CheckpointAt.Equal(start.Add(5*time.Hour)))
+	require.Len(t, published, 1)
+	assert.Equal(t, events.TypeBalanceDiscrepancy, published[0].Type)
+
+	// The reported balance is the new checkpoint.
+	require.NoError(t, svc.AfterSave(ctx, card(finance.StatusAuthorized, lkr(500), lkr(45000))))
+	assert.Len(t, store.saved, 1)
+
+	// Older alerts are skipped.
+	late := card(finance.StatusAuthorized, lkr(100), lkr(1))
+	late.OccurredAt = start
+	require.NoError(t, svc.AfterSave(ctx, late))
+	assert.Len(t, store.saved, 1)
+
+	// A foreign currency purchase without a balance loses track until the
+	// next reported balance.
+	require.NoError(t, svc.AfterSave(ctx, card(finance.StatusAuthorized, models.NewMoney(2000, "USD"), models.Money{})))
+	require.NoError(t, svc.AfterSave(ctx, card(finance.StatusAuthorized, lkr(100), lkr(38000))))
+	assert.Len(t, store.saved, 1)
+}
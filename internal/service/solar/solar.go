@@ -0,0 +1,264 @@
+// Package solar analyses the electricity bills of accounts with rooftop solar:
+// self-consumption, export ratio, generation income against import cost,
+// payback progress and sudden drops in exports.
+package solar
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/ebill"
+	"auto-finance/internal/storage"
+
+	"github.com/rs/zerolog"
+)
+
+// Defaults used when the Config fields are zero.
+const (
+	// DefaultDailyYield is a typical output in Sri Lanka, in units per kWp
+	// per day.
+	DefaultDailyYield = 4.0
+	// DefaultDropThreshold flags exports that fell by half.
+	DefaultDropThreshold = 0.5
+	// DefaultDropWindow is how many earlier months exports are compared
+	// with.
+	DefaultDropWindow = 3
+)
+
+// defaultDays is the length assumed for an account's first billing period,
+// which has no earlier reading to measure from.
+const defaultDays = 30
+
+// currency is the only currency bills are issued in.
+const currency = "LKR"
+
+type Config struct {
+	Logger  zerolog.Logger
+	Bills   storage.BillLedger
+	Summary storage.SolarSummaryStorage
+	// Accounts limits the analysis to these account numbers. Empty analyses
+	// every account with exports.
+	Accounts []string
+	// CapacityKWp is the size of the system. Zero leaves generation,
+	// self-consumption and the ratios unknown.
+	CapacityKWp float64
+	// DailyYield is units per kWp per day, DefaultDailyYield when zero.
+	DailyYield float64
+	// SystemCost is what the system cost, for payback progress.
+	SystemCost models.Money
+	// InstalledOn is when income starts counting towards payback. Zero
+	// counts every bill.
+	InstalledOn time.Time
+	// DropThreshold is the fractional fall in daily exports, against the
+	// average of the previous DropWindow months, that flags a month.
+	DropThreshold float64
+	DropWindow    int
+}
+
+// Service builds the solar summary.
+type Service struct {
+	logger        zerolog.Logger
+	bills         storage.BillLedger
+	summary       storage.SolarSummaryStorage
+	accounts      map[string]bool
+	capacity      float64
+	dailyYield    float64
+	systemCost    models.Money
+	installedOn   time.Time
+	dropThreshold float64
+	dropWindow    int
+}
+
+func New(c *Config) *Service {
+	s := &Service{
+		logger:        c.Logger,
+		bills:         c.Bills,
+		summary:       c.Summary,
+		capacity:      c.CapacityKWp,
+		dailyYield:    c.DailyYield,
+		systemCost:    c.SystemCost,
+		installedOn:   c.InstalledOn,
+		dropThreshold: c.DropThreshold,
+		dropWindow:    c.DropWindow,
+	}
+	if s.dailyYield == 0 {
+		s.dailyYield = DefaultDailyYield
+	}
+	if s.dropThreshold == 0 {
+		s.dropThreshold = DefaultDropThreshold
+	}
+	if s.dropWindow == 0 {
+		s.dropWindow = DefaultDropWindow
+	}
+	if len(c.Accounts) > 0 {
+		s.accounts = make(map[string]bool, len(c.Accounts))
+		for _, a := range c.Accounts {
+			s.accounts[a] = true
+		}
+	}
+	return s
+}
+
+// Run reads the stored bills, analyses them and, when a summary storage is
+// configured, replaces the summary.
+func (s *Service) Run(ctx context.Context) ([]*ebill.SolarMonth, error) {
+	bills, err := s.bills.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bills: %w", err)
+	}
+
+	months := s.Analyze(bills)
+	for _, m := range months {
+		if m.ExportDrop {
+			s.logger.Warn().
+				Str("account", m.AccountNumber).
+				Time("read_on", m.ReadOn).
+				Int("export_units", m.ExportUnits).
+				Msg("Solar exports dropped sharply, check the inverter")
+		}
+	}
+
+	if s.summary != nil {
+		if err := s.summary.Replace(ctx, months); err != nil {
+			return months, fmt.Errorf("failed to write solar summary: %w", err)
+		}
+	}
+	return months, nil
+}
+
+// Analyze summarizes bills per account and reading date. A bill saved more
+// than once, such as after a replay, counts once. Accounts that never
+// exported are left out unless listed in Config.Accounts.
+func (s *Service) Analyze(bills []*ebill.ElectricityBill) []*ebill.SolarMonth {
+	byAccount := make(map[string]map[string]*ebill.ElectricityBill)
+	exported := make(map[string]bool)
+	for _, b := range bills {
+		if s.accounts != nil && !s.accounts[b.AccountNumber] {
+			continue
+		}
+		if byAccount[b.AccountNumber] == nil {
+			byAccount[b.AccountNumber] = make(map[string]*ebill.ElectricityBill)
+		}
+		byAccount[b.AccountNumber][b.ReadOn.Format(time.DateOnly)] = b
+		if b.ExportUnits > 0 || s.accounts != nil {
+			exported[b.AccountNumber] = true
+		}
+	}
+
+	accounts := make([]string, 0, len(byAccount))
+	for account := range byAccount {
+		if exported[account] {
+			accounts = append(accounts, account)
+		}
+	}
+	sort.Strings(accounts)
+
+	var months []*ebill.SolarMonth
+	for _, account := range accounts {
+		readings := make([]*ebill.ElectricityBill, 0, len(byAccount[account]))
+		for _, b := range byAccount[account] {
+			readings = append(readings, b)
+		}
+		sort.Slice(readings, func(i, j int) bool { return readings[i].ReadOn.Before(readings[j].ReadOn) })
+		months = append(months, s.analyzeAccount(readings)...)
+	}
+	return months
+}
+
+func (s *Service) analyzeAccount(bills []*ebill.ElectricityBill) []*ebill.SolarMonth {
+	months := make([]*ebill.SolarMonth, 0, len(bills))
+	zero := models.NewMoney(0, currency)
+	ytdIncome, ytdCost, cumulative := zero, zero, zero
+
+	for i, b := range bills {
+		m := &ebill.SolarMonth{
+			AccountNumber: b.AccountNumber,
+			ReadOn:        b.ReadOn,
+			Days:          defaultDays,
+			ImportUnits:   b.ImportUnits,
+			ExportUnits:   b.ExportUnits,
+		}
+		if i > 0 {
+			m.Days = int(math.Round(b.ReadOn.Sub(bills[i-1].ReadOn).Hours() / 24))
+			if b.ReadOn.Year() != bills[i-1].ReadOn.Year() {
+				ytdIncome, ytdCost = zero, zero
+			}
+		}
+
+		if s.capacity > 0 {
+			m.EstimatedGeneration = int(math.Round(s.capacity * s.dailyYield * float64(m.Days)))
+			m.SelfConsumption = max(m.EstimatedGeneration-m.ExportUnits, 0)
+			if m.EstimatedGeneration > 0 {
+				m.SelfConsumptionRatio = float64(m.SelfConsumption) / float64(m.EstimatedGeneration)
+				m.ExportRatio = min(float64(m.ExportUnits)/float64(m.EstimatedGeneration), 1)
+			}
+		}
+
+		m.GenerationIncome = income(b)
+		m.ImportCost = sum(b.MonthlyBill, b.SSCL)
+		m.Net = sum(m.GenerationIncome, m.ImportCost.Neg())
+
+		ytdIncome = sum(ytdIncome, m.GenerationIncome)
+		ytdCost = sum(ytdCost, m.ImportCost)
+		m.YearToDateIncome, m.YearToDateImportCost = ytdIncome, ytdCost
+
+		if s.installedOn.IsZero() || !b.ReadOn.Before(s.installedOn) {
+			cumulative = sum(cumulative, m.GenerationIncome)
+		}
+		m.CumulativeIncome = cumulative
+		if s.systemCost.Minor > 0 {
+			m.PaybackProgress = float64(cumulative.Minor) / float64(s.systemCost.Minor)
+		}
+
+		m.ExportDrop = s.exportDropped(months, m)
+		months = append(months, m)
+	}
+	return months
+}
+
+// exportDropped compares daily exports with the average of the previous
+// dropWindow months. Months without enough history are never flagged.
+func (s *Service) exportDropped(previous []*ebill.SolarMonth, m *ebill.SolarMonth) bool {
+	if len(previous) < s.dropWindow || m.Days <= 0 {
+		return false
+	}
+
+	var total float64
+	for _, p := range previous[len(previous)-s.dropWindow:] {
+		if p.Days <= 0 {
+			return false
+		}
+		total += float64(p.ExportUnits) / float64(p.Days)
+	}
+	average := total / float64(s.dropWindow)
+	if average == 0 {
+		return false
+	}
+	return float64(m.ExportUnits)/float64(m.Days) < average*(1-s.dropThreshold)
+}
+
+// income is this month's generation amount, or the last generation payment
+// for bills that do not print it.
+func income(b *ebill.ElectricityBill) models.Money {
+	if !b.GenerationAmount.IsZero() {
+		return b.GenerationAmount
+	}
+	if !b.LastGenPayment.IsZero() {
+		return b.LastGenPayment
+	}
+	return models.NewMoney(0, currency)
+}
+
+// sum adds LKR amounts; bill amounts are never in another currency, and
+// unset amounts count as zero.
+func sum(amounts ...models.Money) models.Money {
+	total := models.NewMoney(0, currency)
+	for _, a := range amounts {
+		total.Minor += a.Minor
+	}
+	return total
+}
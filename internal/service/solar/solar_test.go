@@ -0,0 +1,153 @@
+package solar_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/ebill"
+	"auto-finance/internal/service/solar"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lkr(rupees int64) models.Money {
+	return models.NewMoney(rupees*100, "LKR")
+}
+
+func date(s string) time.Time {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func bill(readOn string, exportUnits int) *ebill.ElectricityBill {
+	return &ebill.ElectricityBill{
+		AccountNumber:    "0123456789",
+		ReadOn:           date(readOn),
+		ImportUnits:      140,
+		ExportUnits:      exportUnits,
+		MonthlyBill:      lkr(3000),
+		SSCL:             models.NewMoney(7692, "LKR"),
+		GenerationAmount: lkr(10000),
+	}
+}
+
+func history() []*ebill.ElectricityBill {
+	december := bill("2024-12-06", 450)
+	december.GenerationAmount = models.Money{}
+	december.LastGenPayment = lkr(9000)
+
+	return []*ebill.ElectricityBill{
+		december,
+		bill("2025-01-05", 500),
+		bill("2025-02-04", 520),
+		bill("2025-03-06", 480),
+		// Saved twice, e.g. after a replay.
+		bill("2025-02-04", 520),
+		bill("2025-04-05", 150),
+		{AccountNumber: "9999999999", ReadOn: date("2025-01-05"), ImportUnits: 300, MonthlyBill: lkr(9000)},
+	}
+}
+
+func TestService_Analyze(t *testing.T) {
+	svc := solar.New(&solar.Config{
+		Logger:      zerolog.Nop(),
+		CapacityKWp: 5,
+		SystemCost:  lkr(1000000),
+		InstalledOn: date("2025-01-01"),
+	})
+
+	months := svc.Analyze(history())
+	require.Len(t, months, 5, "duplicate bills and accounts without exports are left out")
+
+	var readOn []string
+	var drops []string
+	for _, m := range months {
+		readOn = append(readOn, m.ReadOn.Format(time.DateOnly))
+		if m.ExportDrop {
+			drops = append(drops, m.ReadOn.Format(time.DateOnly))
+		}
+	}
+	assert.Equal(t, []string{"2024-12-06", "2025-01-05", "2025-02-04", "2025-03-06", "2025-04-05"}, readOn)
+	assert.Equal(t, []string{"2025-04-05"}, drops)
+
+	december, january, april := months[0], months[1], months[4]
+
+	assert.Equal(t, lkr(9000), december.GenerationIncome, "falls back to the last generation payment")
+	assert.Equal(t, lkr(9000), december.YearToDateIncome)
+	assert.Equal(t, lkr(0), december.CumulativeIncome, "income before installation does not count")
+
+	assert.Equal(t, 30, january.Days)
+	assert.Equal(t, 600, january.EstimatedGeneration)
+	assert.Equal(t, 100, january.SelfConsumption)
+	assert.InDelta(t, 1.0/6, january.SelfConsumptionRatio, 1e-9)
+	assert.InDelta(t, 5.0/6, january.ExportRatio, 1e-9)
+	assert.Equal(t, models.NewMoney(307692, "LKR"), january.ImportCost)
+	assert.Equal(t, models.NewMoney(692308, "LKR"), january.Net)
+	assert.Equal(t, lkr(10000), january.YearToDateIncome, "year to date restarts in January")
+
+	assert.Equal(t, lkr(40000), april.YearToDateIncome)
+	assert.Equal(t, models.NewMoney(4*307692, "LKR"), april.YearToDateImportCost)
+	assert.Equal(t, lkr(40000), april.CumulativeIncome)
+	assert.InDelta(t, 0.04, april.PaybackProgress, 1e-9)
+}
+
+func TestService_Analyze_WithoutCapacity(t *testing.T) {
+	months := solar.New(&solar.Config{Logger: zerolog.Nop()}).Analyze(history())
+	require.NotEmpty(t, months)
+	for _, m := range months {
+		assert.Zero(t, m.EstimatedGeneration)
+		assert.Zero(t, m.ExportRatio)
+		assert.Zero(t, m.PaybackProgress)
+	}
+}
+
+type ledger struct {
+	bills []*ebill.ElectricityBill
+	err   error
+}
+
+func (l *ledger) Save(_ context.Context, b *ebill.ElectricityBill) error {
+	l.bills = append(l.bills, b)
+	return nil
+}
+
+func (l *ledger) List(context.Context) ([]*ebill.ElectricityBill, error) {
+	return l.bills, l.err
+}
+
+type summary struct {
+	months []*ebill.SolarMonth
+}
+
+func (s *summary) Replace(_ context.Context, months []*ebill.SolarMonth) error {
+	s.months = months
+	return nil
+}
+
+func TestService_Run(t *testing.T) {
+	out := &summary{}
+	svc := solar.New(&solar.Config{
+		Logger:   zerolog.Nop(),
+		Bills:    &ledger{bills: history()},
+		Summary:  out,
+		Accounts: []string{"9999999999"},
+	})
+
+	months, err := svc.Run(context.Background())
+	require.NoError(t, err)
+	require.Len(t, months, 1, "listed accounts are analysed even without exports")
+	assert.Equal(t, "9999999999", months[0].AccountNumber)
+	assert.Equal(t, months, out.months)
+
+	failing := solar.New(&solar.Config{Logger: zerolog.Nop(), Bills: &ledger{err: errors.New("sheet unavailable")}})
+	_, err = failing.Run(context.Background())
+	assert.Error(t, err)
+}
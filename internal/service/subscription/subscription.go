@@ -0,0 +1,344 @@
+// Package subscription finds recurring card charges in the stored
+// transactions and alerts when a subscription appears, changes price or
+// misses a charge.
+package subscription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"auto-finance/internal/events"
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/storage"
+
+	"github.com/rs/zerolog"
+)
+
+// DefaultLookback is how much history is examined when Config.Lookback is
+// zero; a little over a year so annual charges are seen twice.
+const DefaultLookback = 400 * 24 * time.Hour
+
+// Alert kinds.
+const (
+	AlertNew     = "new"
+	AlertChanged = "changed"
+	AlertMissed  = "missed"
+)
+
+// amountTolerance is how far, as a fraction, the charges of a new
+// subscription may differ from each other.
+const amountTolerance = 0.25
+
+// cadence describes the gap between two charges.
+type cadence struct {
+	name     finance.Cadence
+	min, max time.Duration
+	// grace is how late a charge may be before it counts as missed.
+	grace time.Duration
+	next  func(time.Time) time.Time
+}
+
+const day = 24 * time.Hour
+
+var cadences = []cadence{
+	{name: finance.CadenceMonthly, min: 25 * day, max: 35 * day, grace: 7 * day, next: func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }},
+	{name: finance.CadenceAnnual, min: 350 * day, max: 380 * day, grace: 30 * day, next: func(t time.Time) time.Time { return t.AddDate(1, 0, 0) }},
+}
+
+// Alert is the data of an events.TypeSubscriptionAlert event.
+type Alert struct {
+	Kind         string                `json:"kind"`
+	Subscription *finance.Subscription `json:"subscription"`
+	// Previous is the amount before a price change.
+	Previous models.Money `json:"previous,omitzero"`
+}
+
+// Summary implements events.Describer.
+func (a Alert) Summary() (subject, text string) {
+	sub := a.Subscription
+	switch a.Kind {
+	case AlertNew:
+		return fmt.Sprintf("New subscription: %s", sub.Merchant),
+			fmt.Sprintf("New %s subscription to %s for %s on %s %s", sub.Cadence, sub.Merchant, sub.Amount, sub.Institution, sub.Account)
+	case AlertChanged:
+		return fmt.Sprintf("Subscription price changed: %s", sub.Merchant),
+			fmt.Sprintf("%s now charges %s, was %s", sub.Merchant, sub.Amount, a.Previous)
+	default:
+		return fmt.Sprintf("Subscription charge missed: %s", sub.Merchant),
+			fmt.Sprintf("%s (%s %s) was expected on %s but has not been charged", sub.Merchant, sub.Cadence, sub.Amount, sub.NextExpectedAt.Format(time.DateOnly))
+	}
+}
+
+// Fields implements events.Describer. The alert kind is the status.
+func (a Alert) Fields() events.Fields {
+	return events.Fields{Values: map[string]string{
+		events.FieldInstitution: a.Subscription.Institution,
+		events.FieldAccount:     a.Subscription.Account,
+		events.FieldStatus:      a.Kind,
+	}}
+}
+
+type Config struct {
+	Logger       zerolog.Logger
+	Transactions storage.TransactionLedger
+	// Institutions are the institutions whose transactions are examined.
+	Institutions []string
+	Storage      storage.SubscriptionStorage
+	Publisher    events.Publisher
+	// Lookback is how much history is examined, DefaultLookback when zero.
+	Lookback time.Duration
+}
+
+// Service maintains the subscription list.
+type Service struct {
+	logger       zerolog.Logger
+	transactions storage.TransactionLedger
+	institutions []string
+	storage      storage.SubscriptionStorage
+	publisher    events.Publisher
+	lookback     time.Duration
+}
+
+func New(c *Config) *Service {
+	lookback := c.Lookback
+	if lookback <= 0 {
+		lookback = DefaultLookback
+	}
+
+	return &Service{
+		logger:       c.Logger,
+		transactions: c.Transactions,
+		institutions: c.Institutions,
+		storage:      c.Storage,
+		publisher:    c.Publisher,
+		lookback:     lookback,
+	}
+}
+
+// Run examines the recent transactions, updates the stored subscription
+// list and publishes an alert for every new, changed or missed
+// subscription. It returns the updated list and the alerts.
+func (s *Service) Run(ctx context.Context, now time.Time) ([]*finance.Subscription, []Alert, error) {
+	var txns []*finance.Transaction
+	for _, institution := range s.institutions {
+		found, err := s.transactions.Since(ctx, institution, now.Add(-s.lookback))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s transactions: %w", institution, err)
+		}
+		txns = append(txns, found...)
+	}
+
+	stored, err := s.storage.List(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read subscriptions: %w", err)
+	}
+
+	subscriptions, alerts := Update(stored, txns, now)
+
+	if err := s.storage.Replace(ctx, subscriptions); err != nil {
+		return nil, nil, fmt.Errorf("failed to store subscriptions: %w", err)
+	}
+
+	var errs []error
+	for _, alert := range alerts {
+		s.logger.Info().
+			Str("kind", alert.Kind).
+			Str("merchant", alert.Subscription.Merchant).
+			Str("amount", alert.Subscription.Amount.String()).
+			Msg("Subscription alert")
+		if s.publisher == nil {
+			continue
+		}
+		if err := s.publisher.Publish(ctx, events.Event{Type: events.TypeSubscriptionAlert, Data: alert}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return subscriptions, alerts, fmt.Errorf("failed to publish subscription alerts: %w", errors.Join(errs...))
+	}
+
+	return subscriptions, alerts, nil
+}
+
+// Update advances the known subscriptions with the charges made since their
+// last charge, detects new ones, and flags those whose next charge is
+// overdue. Known subscriptions follow the merchant's charges at their
+// cadence whatever the amount, so a price change is reported rather than
+// breaking the pattern.
+func Update(stored []*finance.Subscription, txns []*finance.Transaction, now time.Time) ([]*finance.Subscription, []Alert) {
+	groups := group(txns)
+
+	var alerts []Alert
+	known := make(map[string]bool, len(stored))
+	subscriptions := make([]*finance.Subscription, 0, len(stored))
+
+	for _, sub := range stored {
+		sub := *sub
+		known[sub.Key] = true
+
+		c := cadenceOf(sub.Cadence)
+		for _, txn := range groups[sub.Key] {
+			gap := txn.OccurredAt.Sub(sub.LastChargedAt)
+			if gap < c.min {
+				continue // the same charge again, or a one-off purchase
+			}
+
+			previous := sub.Amount
+			sub.Amount = txn.Amount
+			sub.Charges++
+			sub.LastChargedAt = txn.OccurredAt
+			sub.NextExpectedAt = c.next(txn.OccurredAt)
+			sub.Missed = false
+			if txn.Amount != previous {
+				alerts = append(alerts, Alert{Kind: AlertChanged, Subscription: snapshot(&sub), Previous: previous})
+			}
+		}
+		subscriptions = append(subscriptions, &sub)
+	}
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		if !known[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if sub := detect(key, groups[key]); sub != nil {
+			subscriptions = append(subscriptions, sub)
+			alerts = append(alerts, Alert{Kind: AlertNew, Subscription: snapshot(sub)})
+		}
+	}
+
+	for _, sub := range subscriptions {
+		if !sub.Missed && now.After(sub.NextExpectedAt.Add(cadenceOf(sub.Cadence).grace)) {
+			sub.Missed = true
+			alerts = append(alerts, Alert{Kind: AlertMissed, Subscription: snapshot(sub)})
+		}
+	}
+
+	return subscriptions, alerts
+}
+
+// snapshot copies sub so that an alert keeps the state it was raised with
+// while Update carries on changing the subscription.
+func snapshot(sub *finance.Subscription) *finance.Subscription {
+	snap := *sub
+	return &snap
+}
+
+// detect looks for a chain of charges ending with the latest one, each a
+// cadence apart and of a similar amount. Any other charge within the chain
+// disqualifies it, so that regular shopping at one merchant is not taken
+// for a subscription.
+func detect(key string, charges []*finance.Transaction) *finance.Subscription {
+	if len(charges) < 2 {
+		return nil
+	}
+	last := charges[len(charges)-1]
+
+	for _, c := range cadences {
+		chain := []*finance.Transaction{last}
+		for i := len(charges) - 2; i >= 0; i-- {
+			prev := chain[len(chain)-1]
+			gap := prev.OccurredAt.Sub(charges[i].OccurredAt)
+			if gap > c.max {
+				break
+			}
+			if gap >= c.min && similar(charges[i].Amount, prev.Amount) {
+				chain = append(chain, charges[i])
+			}
+		}
+
+		first := chain[len(chain)-1]
+		within := 0
+		for _, txn := range charges {
+			if !txn.OccurredAt.Before(first.OccurredAt) {
+				within++
+			}
+		}
+		if len(chain) < 2 || within != len(chain) {
+			continue
+		}
+
+		return &finance.Subscription{
+			Key:            key,
+			Institution:    last.Institution,
+			Account:        last.Account,
+			Merchant:       merchant(last),
+			Cadence:        c.name,
+			Amount:         last.Amount,
+			Charges:        len(chain),
+			FirstChargedAt: first.OccurredAt,
+			LastChargedAt:  last.OccurredAt,
+			NextExpectedAt: c.next(last.OccurredAt),
+		}
+	}
+	return nil
+}
+
+// group collects the charges of each account, merchant and currency,
+// oldest first. Only spending counts: declined and reversed transactions
+// and credits are left out.
+func group(txns []*finance.Transaction) map[string][]*finance.Transaction {
+	groups := make(map[string][]*finance.Transaction)
+	for _, txn := range txns {
+		if txn.Direction != finance.DirectionDebit ||
+			txn.Status == finance.StatusDeclined ||
+			txn.Status == finance.StatusReversed ||
+			merchant(txn) == "" {
+			continue
+		}
+		k := key(txn)
+		groups[k] = append(groups[k], txn)
+	}
+	for _, charges := range groups {
+		sort.SliceStable(charges, func(i, j int) bool { return charges[i].OccurredAt.Before(charges[j].OccurredAt) })
+	}
+	return groups
+}
+
+func key(txn *finance.Transaction) string {
+	return strings.Join([]string{txn.Institution, txn.Account, merchant(txn), txn.Amount.Currency}, "|")
+}
+
+// merchant prefers the categorizer's canonical name.
+func merchant(txn *finance.Transaction) string {
+	if txn.Merchant != "" {
+		return strings.ToUpper(txn.Merchant)
+	}
+	return strings.Join(strings.Fields(strings.ToUpper(txn.Counterparty)), " ")
+}
+
+func similar(a, b models.Money) bool {
+	if a.Currency != b.Currency {
+		return false
+	}
+	diff := a.Minor - b.Minor
+	if diff < 0 {
+		diff = -diff
+	}
+	larger := max(abs(a.Minor), abs(b.Minor))
+	return float64(diff) <= amountTolerance*float64(larger)
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func cadenceOf(name finance.Cadence) cadence {
+	for _, c := range cadences {
+		if c.name == name {
+			return c
+		}
+	}
+	return cadences[0]
+}
@@ -0,0 +1,179 @@
+package subscription_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"auto-finance/internal/events"
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/service/subscription"
+	subscriptionStorage "auto-finance/internal/storage/subscription"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ledger is an in-memory storage.TransactionLedger.
+type ledger struct {
+	txns []*finance.Transaction
+}
+
+func (l *ledger) Save(_ context.Context, txn *finance.Transaction) error {
+	l.txns = append(l.txns, txn)
+	return nil
+}
+
+func (l *ledger) Since(_ context.Context, institution string, since time.Time) ([]*finance.Transaction, error) {
+	var found []*finance.Transaction
+	for _, txn := range l.txns {
+		if txn.Institution == institution && !txn.OccurredAt.Before(since) {
+			found = append(found, txn)
+		}
+	}
+	return found, nil
+}
+
+func (l *ledger) UpdateStatus(context.Context, *finance.Transaction, string) error {
+	return nil
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 10, 0, 0, 0, time.UTC)
+}
+
+func charge(counterparty string, amount models.Money, at time.Time) *finance.Transaction {
+	return &finance.Transaction{
+		Institution:  finance.InstitutionSampath,
+		Account:      "1234",
+		Direction:    finance.DirectionDebit,
+		Channel:      finance.TransactionTypeOnline,
+		Status:       finance.StatusAuthorized,
+		Counterparty: counterparty,
+		Amount:       amount,
+		OccurredAt:   at,
+	}
+}
+
+func TestService_Run(t *testing.T) {
+	t.Parallel()
+
+	usd := func(cents int64) models.Money { return models.NewMoney(cents, "USD") }
+	lkr := func(rupees int64) models.Money { return models.NewMoney(rupees*100, "LKR") }
+
+	declined := charge("MASKED STREAM", usd(1099), date(2025, time.July, 5))
+	declined.Status = finance.StatusDeclined
+
+	store := &ledger{txns: []*finance.Transaction{
+		declined,
+		charge("MASKED STREAM", usd(1099), date(2025, time.August, 5)),
+		charge("MASKED STREAM", usd(1099), date(2025, time.September, 5)),
+		charge("Masked  Stream", usd(1099), date(2025, time.October, 4)),
+		charge("MASKED CLOUD", usd(2999), date(2024, time.November, 1)),
+		charge("MASKED CLOUD", usd(2999), date(2025, time.October, 30)),
+		charge("MASKED ONE-OFF", lkr(5000), date(2025, time.October, 1)),
+	}}
+	// Weekly shopping at one merchant is not a subscription.
+	for at := date(2025, time.August, 2); at.Before(date(2025, time.November, 1)); at = at.AddDate(0, 0, 7) {
+		store.txns = append(store.txns, charge("MASKED GROCER", lkr(12000), at))
+	}
+
+	var published []subscription.Alert
+	bus := events.NewBus()
+	bus.Subscribe(func(_ context.Context, e events.Event) error {
+		published = append(published, e.Data.(subscription.Alert))
+		return nil
+	})
+
+	svc := subscription.New(&subscription.Config{
+		Logger:       zerolog.Nop(),
+		Transactions: store,
+		Institutions: []string{finance.InstitutionSampath},
+		Storage:      subscriptionStorage.NewMemory(),
+		Publisher:    bus,
+	})
+	ctx := context.Background()
+
+	subs, alerts, err := svc.Run(ctx, date(2025, time.November, 1))
+	require.NoError(t, err)
+	require.Len(t, subs, 2)
+	require.Len(t, alerts, 2)
+	assert.Equal(t, published, alerts)
+
+	cloud, stream := alerts[0].Subscription, alerts[1].Subscription
+	assert.Equal(t, subscription.AlertNew, alerts[0].Kind)
+	assert.Equal(t, "MASKED CLOUD", cloud.Merchant)
+	assert.Equal(t, finance.CadenceAnnual, cloud.Cadence)
+	assert.Equal(t, "MASKED STREAM", stream.Merchant)
+	assert.Equal(t, finance.CadenceMonthly, stream.Cadence)
+	assert.Equal(t, 3, stream.Charges)
+	assert.Equal(t, date(2025, time.November, 4), stream.NextExpectedAt)
+
+	// Running again without new charges changes nothing.
+	_, alerts, err = svc.Run(ctx, date(2025, time.November, 2))
+	require.NoError(t, err)
+	assert.Empty(t, alerts)
+
+	// A price rise is reported as a change, not a new subscription.
+	store.txns = append(store.txns, charge("MASKED STREAM", usd(1299), date(2025, time.November, 4)))
+	subs, alerts, err = svc.Run(ctx, date(2025, time.November, 5))
+	require.NoError(t, err)
+	require.Len(t, subs, 2)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, subscription.AlertChanged, alerts[0].Kind)
+	assert.Equal(t, usd(1099), alerts[0].Previous)
+	assert.Equal(t, usd(1299), alerts[0].Subscription.Amount)
+	assert.Equal(t, 4, alerts[0].Subscription.Charges)
+
+	// No December charge: missed once the grace period is over, and only
+	// reported once.
+	_, alerts, err = svc.Run(ctx, date(2025, time.December, 10))
+	require.NoError(t, err)
+	assert.Empty(t, alerts)
+
+	_, alerts, err = svc.Run(ctx, date(2025, time.December, 20))
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, subscription.AlertMissed, alerts[0].Kind)
+	assert.Equal(t, "MASKED STREAM", alerts[0].Subscription.Merchant)
+
+	_, alerts, err = svc.Run(ctx, date(2025, time.December, 21))
+	require.NoError(t, err)
+	assert.Empty(t, alerts)
+}
+
+func TestUpdate_AlertsKeepTheirState(t *testing.T) {
+	lkr := func(rupees int64) models.Money { return models.NewMoney(rupees*100, "LKR") }
+	stored := []*finance.Subscription{{
+		Key:            finance.InstitutionSampath + "|1234|MASKED STREAM|LKR",
+		Institution:    finance.InstitutionSampath,
+		Account:        "1234",
+		Merchant:       "MASKED STREAM",
+		Cadence:        finance.CadenceMonthly,
+		Amount:         lkr(1000),
+		Charges:        2,
+		LastChargedAt:  date(2025, time.January, 5),
+		NextExpectedAt: date(2025, time.February, 5),
+	}}
+	txns := []*finance.Transaction{
+		charge("MASKED STREAM", lkr(1200), date(2025, time.February, 5)),
+		charge("MASKED STREAM", lkr(1500), date(2025, time.March, 5)),
+	}
+
+	subs, alerts := subscription.Update(stored, txns, date(2025, time.May, 1))
+
+	require.Len(t, subs, 1)
+	assert.True(t, subs[0].Missed)
+	require.Len(t, alerts, 3)
+	assert.Equal(t, subscription.AlertChanged, alerts[0].Kind)
+	assert.Equal(t, lkr(1000), alerts[0].Previous)
+	assert.Equal(t, lkr(1200), alerts[0].Subscription.Amount)
+	assert.False(t, alerts[0].Subscription.Missed)
+	assert.Equal(t, lkr(1200), alerts[1].Previous)
+	assert.Equal(t, lkr(1500), alerts[1].Subscription.Amount)
+	assert.False(t, alerts[1].Subscription.Missed)
+	assert.Equal(t, subscription.AlertMissed, alerts[2].Kind)
+	assert.True(t, alerts[2].Subscription.Missed)
+}
@@ -0,0 +1,225 @@
+// Package tariff computes what an electricity bill should be from its meter
+// readings and flags bills whose charges differ from what was parsed.
+package tariff
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/ebill"
+)
+
+// ErrNoTariff is returned when no tariff applies to a bill's account type.
+var ErrNoTariff = errors.New("no tariff for account type")
+
+// Scheme is how exported solar units are settled.
+type Scheme string
+
+const (
+	// SchemeNone bills every imported unit and ignores exports.
+	SchemeNone Scheme = ""
+	// SchemeNetMetering bills only the units imported beyond those exported.
+	SchemeNetMetering Scheme = "net_metering"
+	// SchemeNetAccounting bills every imported unit and credits exported
+	// units at the export rate.
+	SchemeNetAccounting Scheme = "net_accounting"
+)
+
+// Block is one step of a block tariff.
+type Block struct {
+	// UpTo is the last unit charged at Rate, 0 for no limit.
+	UpTo int
+	// Rate is the charge per unit.
+	Rate models.Money
+	// FixedCharge is the monthly fixed charge when consumption ends in this
+	// block.
+	FixedCharge models.Money
+}
+
+// Schedule is a set of blocks used for a range of consumption. Sri Lankan
+// domestic tariffs price the first units differently for small consumers.
+type Schedule struct {
+	// MaxUnits is the highest consumption the schedule applies to, 0 for
+	// any.
+	MaxUnits int
+	Blocks   []Block
+}
+
+// Tariff prices one kind of account.
+type Tariff struct {
+	Name string
+	// AccountTypes are the account types printed on bills, e.g. "Domestic",
+	// matched case-insensitively. A tariff without any applies to every
+	// account no other tariff claims.
+	AccountTypes []string
+	// Schedules are tried in order and the first that covers the billed
+	// units is used.
+	Schedules []Schedule
+	// SSCL is the Social Security Contribution Levy as a percentage of the
+	// monthly bill, nil for none.
+	SSCL       *big.Rat
+	Scheme     Scheme
+	ExportRate models.Money
+}
+
+// Charges is what a bill should contain.
+type Charges struct {
+	// Units are the units billed after settling exports.
+	Units       int
+	Energy      models.Money
+	FixedCharge models.Money
+	// MonthlyBill is Energy plus FixedCharge.
+	MonthlyBill models.Money
+	SSCL        models.Money
+	// GenerationUnits and GenerationCredit are the exported units paid for
+	// under net accounting.
+	GenerationUnits  int
+	GenerationCredit models.Money
+}
+
+// Validate checks that the schedules can price any consumption.
+func (t Tariff) Validate() error {
+	if len(t.Schedules) == 0 {
+		return fmt.Errorf("tariff %s: at least one schedule is required", t.Name)
+	}
+	for i, s := range t.Schedules {
+		if len(s.Blocks) == 0 {
+			return fmt.Errorf("tariff %s: schedule %d has no blocks", t.Name, i+1)
+		}
+		prev := 0
+		for j, b := range s.Blocks {
+			last := j == len(s.Blocks)-1
+			if b.UpTo == 0 && !last {
+				return fmt.Errorf("tariff %s: schedule %d: only the last block can be unlimited", t.Name, i+1)
+			}
+			if b.UpTo != 0 && b.UpTo <= prev {
+				return fmt.Errorf("tariff %s: schedule %d: block limits must increase", t.Name, i+1)
+			}
+			if last && s.MaxUnits != 0 && b.UpTo != 0 && b.UpTo < s.MaxUnits {
+				return fmt.Errorf("tariff %s: schedule %d: blocks end before %d units", t.Name, i+1, s.MaxUnits)
+			}
+			if last && s.MaxUnits == 0 && b.UpTo != 0 {
+				return fmt.Errorf("tariff %s: schedule %d: the last block must be unlimited", t.Name, i+1)
+			}
+			prev = b.UpTo
+		}
+	}
+	if last := t.Schedules[len(t.Schedules)-1]; last.MaxUnits != 0 {
+		return fmt.Errorf("tariff %s: the last schedule must apply to any consumption", t.Name)
+	}
+	if t.SSCL != nil && t.SSCL.Sign() < 0 {
+		return fmt.Errorf("tariff %s: sscl must not be negative", t.Name)
+	}
+	switch t.Scheme {
+	case SchemeNone, SchemeNetMetering:
+	case SchemeNetAccounting:
+		if t.ExportRate.IsZero() {
+			return fmt.Errorf("tariff %s: net accounting requires an export rate", t.Name)
+		}
+	default:
+		return fmt.Errorf("tariff %s: unknown scheme %q", t.Name, t.Scheme)
+	}
+	return nil
+}
+
+func (t Tariff) applies(accountType string) bool {
+	for _, at := range t.AccountTypes {
+		if strings.EqualFold(strings.TrimSpace(at), strings.TrimSpace(accountType)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Calculate prices a bill's readings.
+func (t Tariff) Calculate(bill *ebill.ElectricityBill) (Charges, error) {
+	var c Charges
+
+	c.Units = t.billedUnits(bill)
+	schedule := t.schedule(c.Units)
+
+	currency := schedule.Blocks[0].Rate.Currency
+	c.Energy = models.NewMoney(0, currency)
+	c.FixedCharge = schedule.Blocks[0].FixedCharge
+
+	prev := 0
+	for _, b := range schedule.Blocks {
+		if c.Units <= prev {
+			break
+		}
+		upTo := c.Units
+		if b.UpTo != 0 && b.UpTo < upTo {
+			upTo = b.UpTo
+		}
+		var err error
+		if c.Energy, err = c.Energy.Add(times(b.Rate, upTo-prev)); err != nil {
+			return Charges{}, fmt.Errorf("tariff %s: %w", t.Name, err)
+		}
+		c.FixedCharge = b.FixedCharge
+		prev = upTo
+	}
+
+	var err error
+	if c.MonthlyBill, err = c.Energy.Add(c.FixedCharge); err != nil {
+		return Charges{}, fmt.Errorf("tariff %s: %w", t.Name, err)
+	}
+
+	c.SSCL = models.NewMoney(0, c.MonthlyBill.Currency)
+	if t.SSCL != nil {
+		c.SSCL = percent(c.MonthlyBill, t.SSCL)
+	}
+
+	if t.Scheme == SchemeNetAccounting {
+		c.GenerationUnits = bill.GenerationUnits
+		if c.GenerationUnits == 0 {
+			c.GenerationUnits = bill.ExportUnits
+		}
+		c.GenerationCredit = times(t.ExportRate, c.GenerationUnits)
+	}
+
+	return c, nil
+}
+
+// billedUnits settles exports under the tariff's scheme. Under net metering
+// the bill's own net units are preferred since they include units carried
+// forward from earlier months.
+func (t Tariff) billedUnits(bill *ebill.ElectricityBill) int {
+	if t.Scheme != SchemeNetMetering {
+		return bill.ImportUnits
+	}
+
+	switch strings.ToLower(bill.NetUnitsType) {
+	case "imp", "import":
+		return bill.NetUnits
+	case "exp", "export":
+		return 0
+	}
+	if units := bill.ImportUnits - bill.ExportUnits; units > 0 {
+		return units
+	}
+	return 0
+}
+
+func (t Tariff) schedule(units int) Schedule {
+	for _, s := range t.Schedules {
+		if s.MaxUnits == 0 || units <= s.MaxUnits {
+			return s
+		}
+	}
+	return t.Schedules[len(t.Schedules)-1]
+}
+
+func times(rate models.Money, units int) models.Money {
+	return models.NewMoney(rate.Minor*int64(units), rate.Currency)
+}
+
+// percent returns pct percent of m rounded half away from zero.
+func percent(m models.Money, pct *big.Rat) models.Money {
+	v := new(big.Rat).SetInt64(m.Minor)
+	v.Mul(v, pct)
+	v.Quo(v, big.NewRat(100, 1))
+	return models.RoundMoney(v, m.Currency)
+}
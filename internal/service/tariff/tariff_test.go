@@ -0,0 +1,195 @@
+package tariff_test
+
+import (
+	"math/big"
+	"testing"
+
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/ebill"
+	"auto-finance/internal/service/tariff"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lkr(rupees float64) models.Money {
+	return models.NewMoney(int64(rupees*100+0.5), "LKR")
+}
+
+// domestic is a simplified two-schedule domestic tariff.
+func domestic(scheme tariff.Scheme) tariff.Tariff {
+	return tariff.Tariff{
+		Name:         "domestic",
+		AccountTypes: []string{"Domestic", "DOMESTIC-01"},
+		Schedules: []tariff.Schedule{
+			{MaxUnits: 60, Blocks: []tariff.Block{
+				{UpTo: 30, Rate: lkr(4), FixedCharge: lkr(75)},
+				{UpTo: 60, Rate: lkr(6), FixedCharge: lkr(200)},
+			}},
+			{Blocks: []tariff.Block{
+				{UpTo: 60, Rate: lkr(11), FixedCharge: lkr(0)},
+				{UpTo: 90, Rate: lkr(14), FixedCharge: lkr(400)},
+				{UpTo: 120, Rate: lkr(25), FixedCharge: lkr(1000)},
+				{UpTo: 180, Rate: lkr(33), FixedCharge: lkr(1500)},
+				{Rate: lkr(52), FixedCharge: lkr(2000)},
+			}},
+		},
+		SSCL:       big.NewRat(25641, 10000),
+		Scheme:     scheme,
+		ExportRate: lkr(27.06),
+	}
+}
+
+func TestTariff_Calculate(t *testing.T) {
+	tests := []struct {
+		name             string
+		scheme           tariff.Scheme
+		bill             ebill.ElectricityBill
+		wantUnits        int
+		wantMonthlyBill  models.Money
+		wantSSCL         models.Money
+		wantGenerationCr models.Money
+	}{
+		{
+			name:            "first block only",
+			bill:            ebill.ElectricityBill{ImportUnits: 25},
+			wantUnits:       25,
+			wantMonthlyBill: lkr(175),
+			wantSSCL:        lkr(4.49),
+		},
+		{
+			name:            "second block of small consumer schedule",
+			bill:            ebill.ElectricityBill{ImportUnits: 45},
+			wantUnits:       45,
+			wantMonthlyBill: lkr(410),
+			wantSSCL:        lkr(10.51),
+		},
+		{
+			name:            "large consumer schedule",
+			bill:            ebill.ElectricityBill{ImportUnits: 140},
+			wantUnits:       140,
+			wantMonthlyBill: lkr(3990),
+			wantSSCL:        lkr(102.31),
+		},
+		{
+			name:            "net metering with exported surplus",
+			scheme:          tariff.SchemeNetMetering,
+			bill:            ebill.ElectricityBill{ImportUnits: 140, ExportUnits: 625, NetUnits: 485, NetUnitsType: "Exp"},
+			wantUnits:       0,
+			wantMonthlyBill: lkr(75),
+			wantSSCL:        lkr(1.92),
+		},
+		{
+			name:            "net metering uses the bill's net import units",
+			scheme:          tariff.SchemeNetMetering,
+			bill:            ebill.ElectricityBill{ImportUnits: 22, ExportUnits: 9, NetUnits: 13, NetUnitsType: "Imp"},
+			wantUnits:       13,
+			wantMonthlyBill: lkr(127),
+			wantSSCL:        lkr(3.26),
+		},
+		{
+			name:             "net accounting credits generation units",
+			scheme:           tariff.SchemeNetAccounting,
+			bill:             ebill.ElectricityBill{ImportUnits: 140, ExportUnits: 625, GenerationUnits: 430},
+			wantUnits:        140,
+			wantMonthlyBill:  lkr(3990),
+			wantSSCL:         lkr(102.31),
+			wantGenerationCr: lkr(11635.80),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := domestic(tt.scheme).Calculate(&tt.bill)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantUnits, got.Units)
+			assert.Equal(t, tt.wantMonthlyBill, got.MonthlyBill)
+			assert.Equal(t, tt.wantSSCL, got.SSCL)
+			assert.Equal(t, tt.wantGenerationCr, got.GenerationCredit)
+		})
+	}
+}
+
+func TestTariff_Validate(t *testing.T) {
+	tests := map[string]func(*tariff.Tariff){
+		"no schedules":         func(tr *tariff.Tariff) { tr.Schedules = nil },
+		"last schedule capped": func(tr *tariff.Tariff) { tr.Schedules = tr.Schedules[:1] },
+		"limits out of order": func(tr *tariff.Tariff) {
+			tr.Schedules[1].Blocks[1].UpTo = 50
+		},
+		"unlimited block in the middle": func(tr *tariff.Tariff) {
+			tr.Schedules[1].Blocks[2].UpTo = 0
+		},
+		"net accounting without export rate": func(tr *tariff.Tariff) {
+			tr.Scheme = tariff.SchemeNetAccounting
+			tr.ExportRate = models.Money{}
+		},
+		"unknown scheme": func(tr *tariff.Tariff) { tr.Scheme = "feed_in" },
+	}
+
+	assert.NoError(t, domestic(tariff.SchemeNone).Validate())
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			tr := domestic(tariff.SchemeNone)
+			mutate(&tr)
+			assert.Error(t, tr.Validate())
+		})
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	general := tariff.Tariff{
+		Name:      "general",
+		Schedules: []tariff.Schedule{{Blocks: []tariff.Block{{Rate: lkr(30), FixedCharge: lkr(240)}}}},
+	}
+
+	v, err := tariff.New(&tariff.Config{
+		Tariffs:   []tariff.Tariff{domestic(tariff.SchemeNetAccounting), general},
+		Tolerance: lkr(1),
+	})
+	require.NoError(t, err)
+
+	t.Run("matching bill", func(t *testing.T) {
+		result, err := v.Verify(&ebill.ElectricityBill{
+			AccountType:      "DOMESTIC-01",
+			ImportUnits:      140,
+			GenerationUnits:  430,
+			MonthlyBill:      lkr(3990),
+			SSCL:             lkr(102.50),
+			GenerationAmount: lkr(11635.80),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "domestic", result.Tariff)
+		assert.True(t, result.OK(), "differences within tolerance should pass: %+v", result.Mismatches)
+	})
+
+	t.Run("overcharged bill", func(t *testing.T) {
+		result, err := v.Verify(&ebill.ElectricityBill{
+			AccountType:      "domestic",
+			ImportUnits:      140,
+			GenerationUnits:  430,
+			MonthlyBill:      lkr(4100),
+			GenerationAmount: lkr(11000),
+		})
+		require.NoError(t, err)
+		assert.False(t, result.OK())
+		assert.Equal(t, []tariff.Mismatch{
+			{Field: "monthly_bill", Parsed: lkr(4100), Expected: lkr(3990)},
+			{Field: "generation_amount", Parsed: lkr(11000), Expected: lkr(11635.80)},
+		}, result.Mismatches)
+	})
+
+	t.Run("fallback tariff", func(t *testing.T) {
+		result, err := v.Verify(&ebill.ElectricityBill{AccountType: "GP-1", ImportUnits: 10, MonthlyBill: lkr(540)})
+		require.NoError(t, err)
+		assert.Equal(t, "general", result.Tariff)
+		assert.True(t, result.OK())
+	})
+
+	t.Run("no tariff", func(t *testing.T) {
+		only, err := tariff.New(&tariff.Config{Tariffs: []tariff.Tariff{domestic(tariff.SchemeNone)}})
+		require.NoError(t, err)
+		_, err = only.Verify(&ebill.ElectricityBill{AccountType: "Industrial"})
+		assert.ErrorIs(t, err, tariff.ErrNoTariff)
+	})
+}
@@ -0,0 +1,142 @@
+package tariff
+
+import (
+	"fmt"
+	"strings"
+
+	"auto-finance/internal/events"
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/ebill"
+)
+
+// Mismatch is a bill field that differs from the expected charge.
+type Mismatch struct {
+	Field    string
+	Parsed   models.Money
+	Expected models.Money
+}
+
+// Verification is the result of checking one bill.
+type Verification struct {
+	Bill       *ebill.ElectricityBill
+	Tariff     string
+	Expected   Charges
+	Mismatches []Mismatch
+}
+
+// OK reports whether the bill matched the tariff.
+func (v *Verification) OK() bool {
+	return len(v.Mismatches) == 0
+}
+
+// Summary implements events.Describer.
+func (v *Verification) Summary() (subject, text string) {
+	parts := make([]string, 0, len(v.Mismatches))
+	for _, m := range v.Mismatches {
+		parts = append(parts, fmt.Sprintf("%s billed %s, expected %s", m.Field, m.Parsed, m.Expected))
+	}
+	return fmt.Sprintf("%s bill mismatch for %s", v.Bill.ProviderName(), v.Bill.AccountNumber),
+		fmt.Sprintf("%s bill for %s (%s) does not match the %s tariff: %s",
+			v.Bill.ProviderName(), v.Bill.AccountName, v.Bill.AccountNumber, v.Tariff, strings.Join(parts, "; "))
+}
+
+// Fields implements events.Describer. Mismatches are filtered by the
+// electricity account number.
+func (v *Verification) Fields() events.Fields {
+	return events.Fields{Values: map[string]string{events.FieldAccount: v.Bill.AccountNumber}}
+}
+
+type Config struct {
+	Tariffs []Tariff
+	// Tolerance is the largest difference not reported, to absorb rounding
+	// on the utility's side. Zero requires an exact match.
+	Tolerance models.Money
+}
+
+// Verifier checks bills against the configured tariffs.
+type Verifier struct {
+	tariffs   []Tariff
+	tolerance models.Money
+}
+
+// New validates every tariff.
+func New(c *Config) (*Verifier, error) {
+	for _, t := range c.Tariffs {
+		if err := t.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return &Verifier{tariffs: c.Tariffs, tolerance: c.Tolerance}, nil
+}
+
+// Tariff returns the tariff for an account type: the first that lists it,
+// or else the first that lists no account types.
+func (v *Verifier) Tariff(accountType string) (Tariff, error) {
+	var fallback *Tariff
+	for i, t := range v.tariffs {
+		if t.applies(accountType) {
+			return t, nil
+		}
+		if len(t.AccountTypes) == 0 && fallback == nil {
+			fallback = &v.tariffs[i]
+		}
+	}
+	if fallback != nil {
+		return *fallback, nil
+	}
+	return Tariff{}, fmt.Errorf("%w %q", ErrNoTariff, accountType)
+}
+
+// Verify compares a bill's monthly bill, SSCL and generation amount with
+// the tariff. Fields the SMS did not carry are left unchecked.
+func (v *Verifier) Verify(bill *ebill.ElectricityBill) (*Verification, error) {
+	t, err := v.Tariff(bill.AccountType)
+	if err != nil {
+		return nil, err
+	}
+	expected, err := t.Calculate(bill)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Verification{Bill: bill, Tariff: t.Name, Expected: expected}
+	checks := []struct {
+		field    string
+		parsed   models.Money
+		expected models.Money
+		carried  bool
+	}{
+		{"monthly_bill", bill.MonthlyBill, expected.MonthlyBill, !bill.MonthlyBill.IsZero()},
+		{"sscl", bill.SSCL, expected.SSCL, !bill.SSCL.IsZero()},
+		{"generation_amount", bill.GenerationAmount, expected.GenerationCredit, t.Scheme == SchemeNetAccounting && !bill.GenerationAmount.IsZero()},
+	}
+	for _, c := range checks {
+		if !c.carried {
+			continue
+		}
+		differs, err := v.differs(c.parsed, c.expected)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", c.field, err)
+		}
+		if differs {
+			result.Mismatches = append(result.Mismatches, Mismatch{Field: c.field, Parsed: c.parsed, Expected: c.expected})
+		}
+	}
+
+	return result, nil
+}
+
+func (v *Verifier) differs(parsed, expected models.Money) (bool, error) {
+	diff, err := parsed.Sub(expected)
+	if err != nil {
+		return false, err
+	}
+	if diff.Minor < 0 {
+		diff = diff.Neg()
+	}
+	cmp, err := diff.Cmp(v.tolerance)
+	if err != nil {
+		return false, err
+	}
+	return cmp > 0, nil
+}
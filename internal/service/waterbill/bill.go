@@ -0,0 +1,59 @@
+package waterbill
+
+import (
+	"context"
+
+	"auto-finance/internal/events"
+	"auto-finance/internal/models/waterbill"
+	"auto-finance/internal/storage"
+
+	"github.com/rs/zerolog"
+)
+
+// BillService saves water bills.
+type BillService interface {
+	HandleBill(ctx context.Context, bill *waterbill.WaterBill) error
+}
+
+type Config struct {
+	Logger  zerolog.Logger
+	Storage storage.MessageStorage[*waterbill.WaterBill]
+	// Publisher, when set, receives an events.TypeBillRecorded event for
+	// every saved bill.
+	Publisher events.Publisher
+}
+
+type billService struct {
+	logger    zerolog.Logger
+	storage   storage.MessageStorage[*waterbill.WaterBill]
+	publisher events.Publisher
+}
+
+func NewBillService(c *Config) BillService {
+	return &billService{
+		logger:    c.Logger,
+		storage:   c.Storage,
+		publisher: c.Publisher,
+	}
+}
+
+func (s *billService) HandleBill(ctx context.Context, bill *waterbill.WaterBill) error {
+	s.logger.Info().Str("provider", bill.Provider).Msgf("Handling water bill: %s", bill.AccountNumber)
+
+	if err := s.storage.Save(ctx, bill); err != nil {
+		s.logger.Error().Err(err).Str("provider", bill.Provider).Msg("Failed to save water bill")
+		return err
+	}
+
+	s.logger.Info().Str("provider", bill.Provider).Msg("Water bill saved successfully")
+
+	if s.publisher != nil {
+		// The bill is already saved, so a failed notification must not fail
+		// the message and have it saved again on replay.
+		if err := s.publisher.Publish(ctx, events.Event{Type: events.TypeBillRecorded, Data: bill}); err != nil {
+			s.logger.Error().Err(err).Msg("Failed to publish water bill event")
+		}
+	}
+
+	return nil
+}
@@ -1 +1,222 @@
 package hnb
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/smsparser"
+)
+
+// HNB alerts share the same building blocks: an amount prefixed by either an
+// ISO currency or "Rs.", a masked account/card number and a timestamp.
+const (
+	amountPattern    = `(?:([A-Z]{3})|Rs\.?)\s*([\d,]*\.?\d+)`
+	maskedPattern    = `[X*#]*(\d{3,5})`
+	timestampPattern = `on\s+(\d{4}-\d{2}-\d{2}\s+\d{2}:\d{2}(?::\d{2})?)`
+	tailPattern      = `\.?(?:\s+Avl\s+(?:Bal|Limit)\b.*)?$`
+
+	defaultCurrency = "LKR"
+)
+
+var (
+	// cardRegex matches credit/debit card purchase, reversal and decline alerts.
+	// Captures: [1] status token, [2] currency, [3] amount, [4] merchant,
+	// [5] card digits, [6] timestamp.
+	cardRegex = regexp.MustCompile(`(?i)Card\s+(Purchase|Reversal|Declined)\s+Alert:\s*` + amountPattern +
+		`\s+at\s+(.+?)\s+on\s+card\s+ending\s+` + maskedPattern + `\s+` + timestampPattern)
+	// accountTxnRegex matches account debits and credits.
+	// Captures: [1] currency, [2] amount, [3] txn type token, [4] account digits,
+	// [5] timestamp, [6] description.
+	accountTxnRegex = regexp.MustCompile(`(?i)Transaction\s+Alert:\s*` + amountPattern +
+		`\s+(debited\s+from|credited\s+to)\s+A/C\s+` + maskedPattern + `\s+` + timestampPattern +
+		`(?:\.?\s+Desc:\s*(.+?))?` + tailPattern)
+	// atmRegex matches ATM cash withdrawals.
+	// Captures: [1] currency, [2] amount, [3] account digits, [4] ATM location,
+	// [5] timestamp.
+	atmRegex = regexp.MustCompile(`(?i)ATM\s+Withdrawal\s+Alert:\s*` + amountPattern +
+		`\s+withdrawn\s+from\s+A/C\s+` + maskedPattern + `\s+at\s+(.+?)\s+` + timestampPattern)
+	// transferRegex matches outgoing and incoming CEFT and online transfers.
+	// Captures: [1] channel token, [2] currency, [3] amount, [4] direction token,
+	// [5] account digits, [6] counterparty, [7] timestamp, [8] reference.
+	transferRegex = regexp.MustCompile(`(?i)(CEFT|Online)\s+Transfer\s+Alert:\s*` + amountPattern +
+		`\s+(transferred\s+from|received\s+to)\s+A/C\s+` + maskedPattern + `\s+(?:to|from)\s+(.+?)\s+` + timestampPattern +
+		`(?:\.?\s+Ref:\s*(.+?))?` + tailPattern)
+	// avlBalRegex captures "Avl Bal"/"Avl Limit" fragments.
+	avlBalRegex = regexp.MustCompile(`(?i)Avl\s+(?:Bal|Limit)\s+` + amountPattern)
+
+	timestampLayouts = []string{time.DateTime, "2006-01-02 15:04"}
+
+	ErrUnrecognizedFormat = errors.New("unrecognized SMS format")
+)
+
+type parser struct{}
+
+func New() smsparser.SMSParser[*finance.Transaction] {
+	return &parser{}
+}
+
+func (p *parser) GetName() string {
+	return "HNB Bank Parser"
+}
+
+func (p *parser) Parse(sms string) (*finance.Transaction, error) {
+	return p.ParseAt(sms, time.Now())
+}
+
+// ParseAt parses the SMS. HNB alerts carry a full timestamp, so receivedAt is
+// only used when that timestamp cannot be read.
+func (p *parser) ParseAt(sms string, receivedAt time.Time) (*finance.Transaction, error) {
+	// Collapse newlines and repeated spaces so the regexes only need to deal
+	// with single spaces between tokens.
+	cleaned := strings.Join(strings.Fields(sms), " ")
+
+	if matches := cardRegex.FindStringSubmatch(cleaned); len(matches) == 7 {
+		amount, err := parseAmount(matches[3], matches[2])
+		if err != nil {
+			return nil, err
+		}
+
+		status := ""
+		direction := finance.DirectionDebit
+		switch strings.ToLower(matches[1]) {
+		case "purchase":
+			status = finance.StatusAuthorized
+		case "reversal":
+			status = finance.StatusReversed
+			direction = finance.DirectionCredit
+		case "declined":
+			status = finance.StatusDeclined
+		}
+
+		txn := &finance.Transaction{
+			Institution:  finance.InstitutionHNB,
+			Account:      matches[5],
+			Direction:    direction,
+			Channel:      finance.TransactionTypeCard,
+			Amount:       amount,
+			Counterparty: strings.TrimSpace(matches[4]),
+			Status:       status,
+			OccurredAt:   parseTimestamp(matches[6], receivedAt),
+		}
+		applyAvailableBalance(txn, cleaned)
+		return txn, nil
+	}
+
+	if matches := atmRegex.FindStringSubmatch(cleaned); len(matches) == 6 {
+		amount, err := parseAmount(matches[2], matches[1])
+		if err != nil {
+			return nil, err
+		}
+
+		txn := &finance.Transaction{
+			Institution:  finance.InstitutionHNB,
+			Account:      matches[3],
+			Direction:    finance.DirectionDebit,
+			Channel:      finance.TransactionTypeATM,
+			Amount:       amount,
+			Counterparty: strings.TrimSpace(matches[4]),
+			Status:       finance.StatusDebit,
+			OccurredAt:   parseTimestamp(matches[5], receivedAt),
+		}
+		applyAvailableBalance(txn, cleaned)
+		return txn, nil
+	}
+
+	if matches := transferRegex.FindStringSubmatch(cleaned); len(matches) == 9 {
+		amount, err := parseAmount(matches[3], matches[2])
+		if err != nil {
+			return nil, err
+		}
+
+		transactionType := finance.TransactionTypeOnline
+		if strings.EqualFold(matches[1], "ceft") {
+			transactionType = finance.TransactionTypeCEFT
+		}
+
+		status, direction := finance.StatusDebit, finance.DirectionDebit
+		if strings.HasPrefix(strings.ToLower(matches[4]), "received") {
+			status, direction = finance.StatusCredit, finance.DirectionCredit
+		}
+
+		txn := &finance.Transaction{
+			Institution:  finance.InstitutionHNB,
+			Account:      matches[5],
+			Direction:    direction,
+			Channel:      transactionType,
+			Amount:       amount,
+			Counterparty: strings.TrimSpace(matches[6]),
+			Status:       status,
+			OccurredAt:   parseTimestamp(matches[7], receivedAt),
+		}
+		txn.SetExtension(finance.ExtensionReference, strings.TrimSpace(matches[8]))
+		applyAvailableBalance(txn, cleaned)
+		return txn, nil
+	}
+
+	if matches := accountTxnRegex.FindStringSubmatch(cleaned); len(matches) == 7 {
+		amount, err := parseAmount(matches[2], matches[1])
+		if err != nil {
+			return nil, err
+		}
+
+		status, direction := finance.StatusDebit, finance.DirectionDebit
+		if strings.HasPrefix(strings.ToLower(matches[3]), "credited") {
+			status, direction = finance.StatusCredit, finance.DirectionCredit
+		}
+
+		txn := &finance.Transaction{
+			Institution:  finance.InstitutionHNB,
+			Account:      matches[4],
+			Direction:    direction,
+			Channel:      finance.TransactionTypeOnline,
+			Amount:       amount,
+			Counterparty: strings.TrimSpace(matches[6]),
+			Status:       status,
+			OccurredAt:   parseTimestamp(matches[5], receivedAt),
+		}
+		applyAvailableBalance(txn, cleaned)
+		return txn, nil
+	}
+
+	return nil, ErrUnrecognizedFormat
+}
+
+func currencyOrDefault(currency string) string {
+	if currency == "" {
+		return defaultCurrency
+	}
+	return strings.ToUpper(currency)
+}
+
+func parseAmount(raw, currency string) (models.Money, error) {
+	return models.ParseMoney(raw, currencyOrDefault(currency))
+}
+
+// parseTimestamp reads the alert timestamp in the receivedAt location,
+// falling back to the received time when the value cannot be parsed.
+func parseTimestamp(raw string, receivedAt time.Time) time.Time {
+	for _, layout := range timestampLayouts {
+		if t, err := time.ParseInLocation(layout, raw, receivedAt.Location()); err == nil {
+			return t
+		}
+	}
+	return receivedAt
+}
+
+func applyAvailableBalance(txn *finance.Transaction, sms string) {
+	matches := avlBalRegex.FindStringSubmatch(sms)
+	if len(matches) != 3 {
+		return
+	}
+
+	amount, err := parseAmount(matches[2], matches[1])
+	if err != nil {
+		return
+	}
+
+	txn.BalanceAfter = amount
+}
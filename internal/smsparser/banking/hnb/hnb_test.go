@@ -0,0 +1,197 @@
+package hnb_test
+
+import (
+	"testing"
+	"time"
+
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/smsparser/banking/hnb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// at parses an alert timestamp in the local zone, which is where Parse reads
+// HNB timestamps when no received time is given.
+func at(value string) time.Time {
+	t, err := time.ParseInLocation(time.DateTime, value, time.Local)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParser_ParseSamples(t *testing.T) {
+	t.Parallel()
+
+	parser := hnb.New()
+
+	tests := []struct {
+		name string
+		sms  string
+		want *finance.Transaction
+	}{
+		{
+			name: "credit card purchase with available limit",
+			sms:  "HNB Credit Card Purchase Alert: LKR 2,450.00 at MASKED SUPER KOTTE on card ending 1234 on 2024-01-15 14:30:00. Avl Limit LKR 97,550.00",
+			want: &finance.Transaction{
+				Institution:  finance.InstitutionHNB,
+				Account:      "1234",
+				Direction:    finance.DirectionDebit,
+				Channel:      finance.TransactionTypeCard,
+				Amount:       models.NewMoney(245000, "LKR"),
+				Counterparty: "MASKED SUPER KOTTE",
+				BalanceAfter: models.NewMoney(9755000, "LKR"),
+				Status:       "authorized",
+				OccurredAt:   at("2024-01-15 14:30:00"),
+			},
+		},
+		{
+			name: "credit card reversal in usd with newlines",
+			sms: `HNB Credit Card Reversal Alert: USD 12.99 at MASKED STREAMING
+on card ending 1234 on 2024-01-16 09:12:45.
+Avl Limit LKR 101,200.00`,
+			want: &finance.Transaction{
+				Institution:  finance.InstitutionHNB,
+				Account:      "1234",
+				Direction:    finance.DirectionCredit,
+				Channel:      finance.TransactionTypeCard,
+				Amount:       models.NewMoney(1299, "USD"),
+				Counterparty: "MASKED STREAMING",
+				BalanceAfter: models.NewMoney(10120000, "LKR"),
+				Status:       "reversed",
+				OccurredAt:   at("2024-01-16 09:12:45"),
+			},
+		},
+		{
+			name: "debit card declined",
+			sms:  "HNB Debit Card Declined Alert: LKR 150,000.00 at MASKED ELECTRICALS on card ending XXXX5678 on 2024-01-17 11:00",
+			want: &finance.Transaction{
+				Institution:  finance.InstitutionHNB,
+				Account:      "5678",
+				Direction:    finance.DirectionDebit,
+				Channel:      finance.TransactionTypeCard,
+				Amount:       models.NewMoney(15000000, "LKR"),
+				Counterparty: "MASKED ELECTRICALS",
+				Status:       "decline",
+				OccurredAt:   at("2024-01-17 11:00:00"),
+			},
+		},
+		{
+			name: "account debit with rupee prefix",
+			sms:  "Transaction Alert: Rs.1,000.00 debited from A/C XXXX1234 on 2024-01-15 14:30:00",
+			want: &finance.Transaction{
+				Institution: finance.InstitutionHNB,
+				Account:     "1234",
+				Direction:   finance.DirectionDebit,
+				Channel:     finance.TransactionTypeOnline,
+				Amount:      models.NewMoney(100000, "LKR"),
+				Status:      "debit",
+				OccurredAt:  at("2024-01-15 14:30:00"),
+			},
+		},
+		{
+			name: "account credit with description and balance",
+			sms:  "Transaction Alert: LKR 75,000.00 credited to A/C XXXX1234 on 2024-01-25 08:00:00 Desc: MASKED SALARY JAN Avl Bal LKR 180,250.00",
+			want: &finance.Transaction{
+				Institution:  finance.InstitutionHNB,
+				Account:      "1234",
+				Direction:    finance.DirectionCredit,
+				Channel:      finance.TransactionTypeOnline,
+				Amount:       models.NewMoney(7500000, "LKR"),
+				Counterparty: "MASKED SALARY JAN",
+				BalanceAfter: models.NewMoney(18025000, "LKR"),
+				Status:       "credit",
+				OccurredAt:   at("2024-01-25 08:00:00"),
+			},
+		},
+		{
+			name: "atm withdrawal",
+			sms:  "ATM Withdrawal Alert: LKR 10,000.00 withdrawn from A/C XXXX1234 at MASKED ATM NUGEGODA on 2024-01-15 18:45:10. Avl Bal LKR 90,000.00",
+			want: &finance.Transaction{
+				Institution:  finance.InstitutionHNB,
+				Account:      "1234",
+				Direction:    finance.DirectionDebit,
+				Channel:      finance.TransactionTypeATM,
+				Amount:       models.NewMoney(1000000, "LKR"),
+				Counterparty: "MASKED ATM NUGEGODA",
+				BalanceAfter: models.NewMoney(9000000, "LKR"),
+				Status:       "debit",
+				OccurredAt:   at("2024-01-15 18:45:10"),
+			},
+		},
+		{
+			name: "outgoing ceft transfer with reference",
+			sms:  "CEFT Transfer Alert: LKR 15,000.00 transferred from A/C XXXX1234 to 8001234567 MASKED BANK on 2024-01-15 14:30:00 Ref: RENT JAN. Avl Bal LKR 75,000.00",
+			want: &finance.Transaction{
+				Institution:  finance.InstitutionHNB,
+				Account:      "1234",
+				Direction:    finance.DirectionDebit,
+				Channel:      finance.TransactionTypeCEFT,
+				Amount:       models.NewMoney(1500000, "LKR"),
+				Counterparty: "8001234567 MASKED BANK",
+				BalanceAfter: models.NewMoney(7500000, "LKR"),
+				Status:       "debit",
+				OccurredAt:   at("2024-01-15 14:30:00"),
+				Extensions:   map[string]string{finance.ExtensionReference: "RENT JAN"},
+			},
+		},
+		{
+			name: "incoming ceft transfer",
+			sms:  "CEFT Transfer Alert: LKR 20,000.00 received to A/C XXXX1234 from MASKED PERERA on 2024-01-20 10:00:00 Ref: LOAN REPAY.",
+			want: &finance.Transaction{
+				Institution:  finance.InstitutionHNB,
+				Account:      "1234",
+				Direction:    finance.DirectionCredit,
+				Channel:      finance.TransactionTypeCEFT,
+				Amount:       models.NewMoney(2000000, "LKR"),
+				Counterparty: "MASKED PERERA",
+				Status:       "credit",
+				OccurredAt:   at("2024-01-20 10:00:00"),
+				Extensions:   map[string]string{finance.ExtensionReference: "LOAN REPAY"},
+			},
+		},
+		{
+			name: "online transfer without reference",
+			sms:  "Online Transfer Alert: LKR 5,000.00 transferred from A/C XXXX1234 to A/C XXXX9876 on 2024-01-15 14:30:00",
+			want: &finance.Transaction{
+				Institution:  finance.InstitutionHNB,
+				Account:      "1234",
+				Direction:    finance.DirectionDebit,
+				Channel:      finance.TransactionTypeOnline,
+				Amount:       models.NewMoney(500000, "LKR"),
+				Counterparty: "A/C XXXX9876",
+				Status:       "debit",
+				OccurredAt:   at("2024-01-15 14:30:00"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parser.Parse(tt.sms)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParser_ParseUnrecognized(t *testing.T) {
+	t.Parallel()
+
+	parser := hnb.New()
+
+	for _, sms := range []string{
+		"",
+		"Your OTP for HNB Digital Banking is 123456",
+		"Cr Crd no..**1234 Auth Pmt LKR 6,789.50 at MASKED BISTRO Avl Bal LKR 40,289.06 Sampath Bank 07-NOV",
+	} {
+		got, err := parser.Parse(sms)
+		assert.ErrorIs(t, err, hnb.ErrUnrecognizedFormat)
+		assert.Nil(t, got)
+	}
+}
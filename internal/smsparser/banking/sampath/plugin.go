@@ -0,0 +1,19 @@
+package sampath
+
+import (
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/smsparser"
+)
+
+// Name is the registry and sender route name of the Sampath parser.
+const Name = "sampath"
+
+// Register adds the Sampath parser to r, handing its transactions to handle.
+func Register(r *smsparser.Registry, handle smsparser.Handler[*finance.Transaction]) error {
+	return smsparser.Register(r, smsparser.Registration[*finance.Transaction]{
+		Name:     Name,
+		Parser:   New(),
+		Validate: (*finance.Transaction).Validate,
+		Handle:   handle,
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"auto-finance/internal/models"
 	"auto-finance/internal/models/finance"
 	"auto-finance/internal/smsparser"
 )
@@ -24,11 +25,18 @@ var (
 	accountTxnRegex = regexp.MustCompile(`(?i)^([A-Z]{3})\s+([\d,.]+)\s+(credited\s+to|debited\s+from)\s+AC\s+\*\*(\d{3,5})\s+(via\s+ATM\s+at|for)\s+(.+?)(?:\s+(?:For\s+Inq|For\s+Enq|Enq)\b.*)?$`)
 	// avlBalRegex captures "Avl Bal <currency> <amount>" fragments present in credit card SMS alerts.
 	avlBalRegex = regexp.MustCompile(`(?i)Avl\s+Bal\s+([A-Z]{3})\s+([\d,.]+|\.00)`)
+	// dateTokenRegex captures the trailing "07-NOV" stamp of card alerts.
+	// Captures: [1] day, [2] month abbreviation.
+	dateTokenRegex = regexp.MustCompile(`(?i)\b(\d{1,2})-(JAN|FEB|MAR|APR|MAY|JUN|JUL|AUG|SEP|OCT|NOV|DEC)\s*$`)
 )
 
+// ExtensionStatusCode keeps the raw card alert status token (e.g. "Auth"
+// or "Rvsd"; decline tokens start with "Dcl") on the transaction.
+const ExtensionStatusCode = "status_code"
+
 type parser struct{}
 
-func New() smsparser.SMSParser[*finance.SampathModel] {
+func New() smsparser.SMSParser[*finance.Transaction] {
 	return &parser{}
 }
 
@@ -36,12 +44,19 @@ func (p *parser) GetName() string {
 	return "Sampath Bank Parser"
 }
 
-func (p *parser) Parse(sms string) (*finance.SampathModel, error) {
+func (p *parser) Parse(sms string) (*finance.Transaction, error) {
+	return p.ParseAt(sms, time.Now())
+}
+
+// ParseAt parses the SMS and stamps it with the date carried by the alert,
+// using receivedAt to fill in the year and time of day.
+func (p *parser) ParseAt(sms string, receivedAt time.Time) (*finance.Transaction, error) {
 	// Normalize whitespace to make regex matching more predictable and trim
 	// leading/trailing spaces. Newlines and tabs are collapsed into single
 	// spaces. We do not change the case since our regexes are case-insensitive.
 	cleaned := strings.TrimSpace(sms)
 	cleaned = strings.Join(strings.Fields(cleaned), " ")
+	occurredAt := resolveSmsTime(cleaned, receivedAt)
 
 	if matches := cardAuthRegex.FindStringSubmatch(cleaned); len(matches) == 6 {
 		cardDigits := matches[1]
@@ -50,60 +65,64 @@ func (p *parser) Parse(sms string) (*finance.SampathModel, error) {
 		amtStr := normalizeAmount(matches[4])
 		merchant := strings.TrimSpace(matches[5])
 
-		// Normalize amount string and convert to float.
-		amount, err := strconv.ParseFloat(amtStr, 64)
+		amount, err := models.ParseMoney(amtStr, currency)
 		if err != nil {
 			return nil, err
 		}
 
 		status := ""
+		direction := finance.DirectionDebit
 		lc := strings.ToLower(statusCode)
 		switch {
 		case strings.HasPrefix(lc, "dcl"):
-			status = "decline"
+			status = finance.StatusDeclined
 		case strings.HasPrefix(lc, "rvs"):
-			status = "reversed"
+			status = finance.StatusReversed
+			direction = finance.DirectionCredit
 		case strings.HasPrefix(lc, "aut"):
-			status = "authorized"
+			status = finance.StatusAuthorized
 		}
 
 		// Merchant strings sometimes include tildes (~) which represent
 		// separators in the SMS. Replace with spaces for readability.
 		merchant = strings.ReplaceAll(merchant, "~", " ")
-		model := &finance.SampathModel{
-			TransactionType: finance.TransactionTypeCard,
-			Identifier:      cardDigits,
-			Amount:          amount,
-			Currency:        currency,
-			Merchant:        merchant,
-			Status:          status,
-			SmsDateTime:     time.Now().Format(time.DateTime),
+		txn := &finance.Transaction{
+			Institution:  finance.InstitutionSampath,
+			Account:      cardDigits,
+			Direction:    direction,
+			Channel:      finance.TransactionTypeCard,
+			Amount:       amount,
+			Counterparty: merchant,
+			Status:       status,
+			OccurredAt:   occurredAt,
 		}
-		applyAvailableBalance(model, cleaned)
-		return model, nil
+		txn.SetExtension(ExtensionStatusCode, statusCode)
+		applyAvailableBalance(txn, cleaned)
+		return txn, nil
 	}
 
 	if matches := cardCreditRegex.FindStringSubmatch(cleaned); len(matches) == 5 {
 		cardDigits := matches[1]
 		currency := strings.ToUpper(matches[2])
 		amtStr := normalizeAmount(matches[3])
-		amount, err := strconv.ParseFloat(amtStr, 64)
+		amount, err := models.ParseMoney(amtStr, currency)
 		if err != nil {
 			return nil, err
 		}
 		description := strings.ReplaceAll(strings.TrimSpace(matches[4]), "~", " ")
 
-		model := &finance.SampathModel{
-			TransactionType: finance.TransactionTypeCard,
-			Identifier:      cardDigits,
-			Amount:          amount,
-			Currency:        currency,
-			Merchant:        description,
-			Status:          "credit",
-			SmsDateTime:     time.Now().Format(time.DateTime),
+		txn := &finance.Transaction{
+			Institution:  finance.InstitutionSampath,
+			Account:      cardDigits,
+			Direction:    finance.DirectionCredit,
+			Channel:      finance.TransactionTypeCard,
+			Amount:       amount,
+			Counterparty: description,
+			Status:       finance.StatusCredit,
+			OccurredAt:   occurredAt,
 		}
-		applyAvailableBalance(model, cleaned)
-		return model, nil
+		applyAvailableBalance(txn, cleaned)
+		return txn, nil
 	}
 
 	// If no card match, attempt to match an account transaction (credit or debit).
@@ -116,18 +135,18 @@ func (p *parser) Parse(sms string) (*finance.SampathModel, error) {
 		channel := strings.ToLower(strings.TrimSpace(matches[5]))
 		description := strings.TrimSpace(matches[6])
 
-		amount, err := strconv.ParseFloat(amtStr, 64)
+		amount, err := models.ParseMoney(amtStr, currency)
 		if err != nil {
 			return nil, err
 		}
 		description = strings.ReplaceAll(description, "~", " ")
 
 		// Derive a status: credit or debit
-		status := ""
+		status := finance.StatusDebit
+		direction := finance.DirectionDebit
 		if strings.HasPrefix(txnType, "credited") {
-			status = "credit"
-		} else if strings.HasPrefix(txnType, "debited") {
-			status = "debit"
+			status = finance.StatusCredit
+			direction = finance.DirectionCredit
 		}
 
 		transactionType := finance.TransactionTypeOnline
@@ -135,14 +154,15 @@ func (p *parser) Parse(sms string) (*finance.SampathModel, error) {
 			transactionType = finance.TransactionTypeATM
 		}
 
-		return &finance.SampathModel{
-			TransactionType: transactionType,
-			Identifier:      accountDigits,
-			Amount:          amount,
-			Currency:        currency,
-			Merchant:        description,
-			Status:          status,
-			SmsDateTime:     time.Now().Format(time.DateTime),
+		return &finance.Transaction{
+			Institution:  finance.InstitutionSampath,
+			Account:      accountDigits,
+			Direction:    direction,
+			Channel:      transactionType,
+			Amount:       amount,
+			Counterparty: description,
+			Status:       status,
+			OccurredAt:   occurredAt,
 		}, nil
 	}
 
@@ -150,6 +170,49 @@ func (p *parser) Parse(sms string) (*finance.SampathModel, error) {
 	return nil, errors.New("unrecognized SMS format")
 }
 
+// resolveSmsTime reconciles the day-month stamp at the end of the SMS with the
+// time the message was received. When the stamp matches the received date the
+// received time is used as is; otherwise the message was delivered late (or is
+// being backfilled) and we fall back to midnight of the stamped date. The year
+// is taken from receivedAt, stepping back one year when the stamp would
+// otherwise land in the future (a 31-DEC alert received on 01-JAN).
+func resolveSmsTime(sms string, receivedAt time.Time) time.Time {
+	matches := dateTokenRegex.FindStringSubmatch(sms)
+	if len(matches) != 3 {
+		return receivedAt
+	}
+
+	day, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return receivedAt
+	}
+
+	month, err := time.Parse("Jan", strings.ToUpper(matches[2][:1])+strings.ToLower(matches[2][1:]))
+	if err != nil {
+		return receivedAt
+	}
+
+	year := receivedAt.Year()
+	stamped := time.Date(year, month.Month(), day, 0, 0, 0, 0, receivedAt.Location())
+	if stamped.After(receivedAt.AddDate(0, 0, 1)) {
+		year--
+		stamped = time.Date(year, month.Month(), day, 0, 0, 0, 0, receivedAt.Location())
+	}
+
+	// Reject impossible stamps such as 31-FEB instead of letting time.Date
+	// roll them over into the next month.
+	if stamped.Day() != day {
+		return receivedAt
+	}
+
+	ry, rm, rd := receivedAt.Date()
+	if stamped.Year() == ry && stamped.Month() == rm && stamped.Day() == rd {
+		return receivedAt
+	}
+
+	return stamped
+}
+
 func normalizeAmount(raw string) string {
 	clean := strings.ReplaceAll(raw, ",", "")
 	switch clean {
@@ -159,23 +222,21 @@ func normalizeAmount(raw string) string {
 	return clean
 }
 
-func applyAvailableBalance(model *finance.SampathModel, sms string) {
-	if currency, amount, ok := parseAvailableBalance(sms); ok {
-		model.AvailableBalanceCurrency = currency
-		model.AvailableBalance = amount
+func applyAvailableBalance(txn *finance.Transaction, sms string) {
+	if amount, ok := parseAvailableBalance(sms); ok {
+		txn.BalanceAfter = amount
 	}
 }
 
-func parseAvailableBalance(sms string) (string, float64, bool) {
+func parseAvailableBalance(sms string) (models.Money, bool) {
 	matches := avlBalRegex.FindStringSubmatch(sms)
 	if len(matches) != 3 {
-		return "", 0, false
+		return models.Money{}, false
 	}
 
-	currency := strings.ToUpper(matches[1])
-	amount, err := strconv.ParseFloat(normalizeAmount(matches[2]), 64)
+	amount, err := models.ParseMoney(normalizeAmount(matches[2]), matches[1])
 	if err != nil {
-		return "", 0, false
+		return models.Money{}, false
 	}
-	return currency, amount, true
+	return amount, true
 }
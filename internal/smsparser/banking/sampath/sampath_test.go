@@ -1,9 +1,13 @@
 package sampath_test
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
+	"auto-finance/internal/models"
 	"auto-finance/internal/models/finance"
+	"auto-finance/internal/smsparser"
 	"auto-finance/internal/smsparser/banking/sampath"
 
 	"github.com/stretchr/testify/assert"
@@ -18,141 +22,150 @@ func TestParser_ParseSamples(t *testing.T) {
 	tests := []struct {
 		name string
 		sms  string
-		want *finance.SampathModel
+		want *finance.Transaction
 	}{
 		{
 			name: "card authorization with commas and newlines",
 			sms: `Cr Crd no..**1234 Auth Pmt LKR 6,789.50 at MASKED BISTRO (PVT) LTD Avl Bal LKR 40,289.06 Enq Call 0112000000
 Sampath Bank 07-NOV`,
-			want: &finance.SampathModel{
-				TransactionType:          finance.TransactionTypeCard,
-				Identifier:               "1234",
-				Amount:                   6789.50,
-				Currency:                 "LKR",
-				Merchant:                 "MASKED BISTRO (PVT) LTD",
-				Status:                   "authorized",
-				AvailableBalance:         40289.06,
-				AvailableBalanceCurrency: "LKR",
+			want: &finance.Transaction{
+				Institution:  finance.InstitutionSampath,
+				Account:      "1234",
+				Direction:    finance.DirectionDebit,
+				Channel:      finance.TransactionTypeCard,
+				Amount:       models.NewMoney(678950, "LKR"),
+				Counterparty: "MASKED BISTRO (PVT) LTD",
+				BalanceAfter: models.NewMoney(4028906, "LKR"),
+				Status:       "authorized",
+				Extensions:   map[string]string{sampath.ExtensionStatusCode: "Auth"},
 			},
 		},
 		{
 			name: "card authorization with hyphenated merchant",
 			sms:  "Cr Crd no..**5678 Auth Pmt LKR 1,250.00 at MASKED-PARK TERMINAL Avl Bal LKR 41,648.06 Enq Call 0112000000 Sampath Bank 07-NOV",
-			want: &finance.SampathModel{
-				TransactionType:          finance.TransactionTypeCard,
-				Identifier:               "5678",
-				Amount:                   1250.00,
-				Currency:                 "LKR",
-				Merchant:                 "MASKED-PARK TERMINAL",
-				Status:                   "authorized",
-				AvailableBalance:         41648.06,
-				AvailableBalanceCurrency: "LKR",
+			want: &finance.Transaction{
+				Institution:  finance.InstitutionSampath,
+				Account:      "5678",
+				Direction:    finance.DirectionDebit,
+				Channel:      finance.TransactionTypeCard,
+				Amount:       models.NewMoney(125000, "LKR"),
+				Counterparty: "MASKED-PARK TERMINAL",
+				BalanceAfter: models.NewMoney(4164806, "LKR"),
+				Status:       "authorized",
+				Extensions:   map[string]string{sampath.ExtensionStatusCode: "Auth"},
 			},
 		},
 		{
 			name: "card authorization with tilde separated merchant",
 			sms:  "Cr Crd no..**9999 Auth Pmt LKR 7,624.00 at MASKED~PETROLEUM~PLC Avl Bal LKR 39,024.06 Enq Call 0112000000 Sampath Bank 07-NOV",
-			want: &finance.SampathModel{
-				TransactionType:          finance.TransactionTypeCard,
-				Identifier:               "9999",
-				Amount:                   7624.00,
-				Currency:                 "LKR",
-				Merchant:                 "MASKED PETROLEUM PLC",
-				Status:                   "authorized",
-				AvailableBalance:         39024.06,
-				AvailableBalanceCurrency: "LKR",
+			want: &finance.Transaction{
+				Institution:  finance.InstitutionSampath,
+				Account:      "9999",
+				Direction:    finance.DirectionDebit,
+				Channel:      finance.TransactionTypeCard,
+				Amount:       models.NewMoney(762400, "LKR"),
+				Counterparty: "MASKED PETROLEUM PLC",
+				BalanceAfter: models.NewMoney(3902406, "LKR"),
+				Status:       "authorized",
+				Extensions:   map[string]string{sampath.ExtensionStatusCode: "Auth"},
 			},
 		},
 		{
 			name: "card reversal in usd",
 			sms:  "Cr Crd no..**1111 Rvsd Pmt USD 1.00 at MASKED TEMP HOLD Avl Bal LKR 421,956.98 Enq Call 0112000000 Sampath Bank 06-NOV",
-			want: &finance.SampathModel{
-				TransactionType:          finance.TransactionTypeCard,
-				Identifier:               "1111",
-				Amount:                   1.00,
-				Currency:                 "USD",
-				Merchant:                 "MASKED TEMP HOLD",
-				Status:                   "reversed",
-				AvailableBalance:         421956.98,
-				AvailableBalanceCurrency: "LKR",
+			want: &finance.Transaction{
+				Institution:  finance.InstitutionSampath,
+				Account:      "1111",
+				Direction:    finance.DirectionCredit,
+				Channel:      finance.TransactionTypeCard,
+				Amount:       models.NewMoney(100, "USD"),
+				Counterparty: "MASKED TEMP HOLD",
+				BalanceAfter: models.NewMoney(42195698, "LKR"),
+				Status:       "reversed",
+				Extensions:   map[string]string{sampath.ExtensionStatusCode: "Rvsd"},
 			},
 		},
 		{
 			name: "card authorization usd zero amount",
 			sms:  "Cr Crd no..**2222 Auth Pmt USD .00 at MASKED ZERO TEST Avl Bal LKR 440,425.09 Enq Call 0112000000 Sampath Bank 03-NOV",
-			want: &finance.SampathModel{
-				TransactionType:          finance.TransactionTypeCard,
-				Identifier:               "2222",
-				Amount:                   0.00,
-				Currency:                 "USD",
-				Merchant:                 "MASKED ZERO TEST",
-				Status:                   "authorized",
-				AvailableBalance:         440425.09,
-				AvailableBalanceCurrency: "LKR",
+			want: &finance.Transaction{
+				Institution:  finance.InstitutionSampath,
+				Account:      "2222",
+				Direction:    finance.DirectionDebit,
+				Channel:      finance.TransactionTypeCard,
+				Amount:       models.NewMoney(0, "USD"),
+				Counterparty: "MASKED ZERO TEST",
+				BalanceAfter: models.NewMoney(44042509, "LKR"),
+				Status:       "authorized",
+				Extensions:   map[string]string{sampath.ExtensionStatusCode: "Auth"},
 			},
 		},
 		{
 			name: "web channel authorization",
 			sms:  "Web Crd no..**2862 Auth Pmt USD 1.03 at MASKED WEB SERVICE Avl Bal LKR 19,051.77 Enq Call 0112000000 Sampath Bank 02-NOV",
-			want: &finance.SampathModel{
-				TransactionType:          finance.TransactionTypeCard,
-				Identifier:               "2862",
-				Amount:                   1.03,
-				Currency:                 "USD",
-				Merchant:                 "MASKED WEB SERVICE",
-				Status:                   "authorized",
-				AvailableBalance:         19051.77,
-				AvailableBalanceCurrency: "LKR",
+			want: &finance.Transaction{
+				Institution:  finance.InstitutionSampath,
+				Account:      "2862",
+				Direction:    finance.DirectionDebit,
+				Channel:      finance.TransactionTypeCard,
+				Amount:       models.NewMoney(103, "USD"),
+				Counterparty: "MASKED WEB SERVICE",
+				BalanceAfter: models.NewMoney(1905177, "LKR"),
+				Status:       "authorized",
+				Extensions:   map[string]string{sampath.ExtensionStatusCode: "Auth"},
 			},
 		},
 		{
 			name: "card payment credited",
 			sms:  "Cr Crd no..**3333 Credited LKR 50,000.00 for MASKED PAYMENT RECEIVED - CLIENT Avl Bal LKR 504,024.06 Enq Call 0112000000 Sampath Bank 08-NOV",
-			want: &finance.SampathModel{
-				TransactionType:          finance.TransactionTypeCard,
-				Identifier:               "3333",
-				Amount:                   50000.00,
-				Currency:                 "LKR",
-				Merchant:                 "MASKED PAYMENT RECEIVED - CLIENT",
-				Status:                   "credit",
-				AvailableBalance:         504024.06,
-				AvailableBalanceCurrency: "LKR",
+			want: &finance.Transaction{
+				Institution:  finance.InstitutionSampath,
+				Account:      "3333",
+				Direction:    finance.DirectionCredit,
+				Channel:      finance.TransactionTypeCard,
+				Amount:       models.NewMoney(5000000, "LKR"),
+				Counterparty: "MASKED PAYMENT RECEIVED - CLIENT",
+				BalanceAfter: models.NewMoney(50402406, "LKR"),
+				Status:       "credit",
 			},
 		},
 		{
 			name: "atm cash withdrawal",
 			sms:  "LKR 4,005.00 debited from AC **4060 via ATM at MASKED BANK ATM CITY For Inq Call 0112000000, Sampath Bank",
-			want: &finance.SampathModel{
-				TransactionType: finance.TransactionTypeATM,
-				Identifier:      "4060",
-				Amount:          4005.00,
-				Currency:        "LKR",
-				Merchant:        "MASKED BANK ATM CITY",
-				Status:          "debit",
+			want: &finance.Transaction{
+				Institution:  finance.InstitutionSampath,
+				Account:      "4060",
+				Direction:    finance.DirectionDebit,
+				Channel:      finance.TransactionTypeATM,
+				Amount:       models.NewMoney(400500, "LKR"),
+				Counterparty: "MASKED BANK ATM CITY",
+				Status:       "debit",
 			},
 		},
 		{
 			name: "account credit transfer",
 			sms:  "LKR 4,280.00 credited to AC **4060 for MASKED INSTALLMENT TRANSFER 8 of 12 For Inq Call 0112000000, Sampath Bank",
-			want: &finance.SampathModel{
-				TransactionType: finance.TransactionTypeOnline,
-				Identifier:      "4060",
-				Amount:          4280.00,
-				Currency:        "LKR",
-				Merchant:        "MASKED INSTALLMENT TRANSFER 8 of 12",
-				Status:          "credit",
+			want: &finance.Transaction{
+				Institution:  finance.InstitutionSampath,
+				Account:      "4060",
+				Direction:    finance.DirectionCredit,
+				Channel:      finance.TransactionTypeOnline,
+				Amount:       models.NewMoney(428000, "LKR"),
+				Counterparty: "MASKED INSTALLMENT TRANSFER 8 of 12",
+				Status:       "credit",
 			},
 		},
 		{
 			name: "account debit card payment",
 			sms:  "LKR 200,000.00 debited from AC **0004 for MASKED CARD PAYMENT 123456789 For Inq Call 0112000000, Sampath Bank",
-			want: &finance.SampathModel{
-				TransactionType: finance.TransactionTypeOnline,
-				Identifier:      "0004",
-				Amount:          200000.00,
-				Currency:        "LKR",
-				Merchant:        "MASKED CARD PAYMENT 123456789",
-				Status:          "debit",
+			want: &finance.Transaction{
+				Institution:  finance.InstitutionSampath,
+				Account:      "0004",
+				Direction:    finance.DirectionDebit,
+				Channel:      finance.TransactionTypeOnline,
+				Amount:       models.NewMoney(20000000, "LKR"),
+				Counterparty: "MASKED CARD PAYMENT 123456789",
+				Status:       "debit",
 			},
 		},
 	}
@@ -164,9 +177,72 @@ Sampath Bank 07-NOV`,
 
 			got, err := parser.Parse(tt.sms)
 			require.NoError(t, err)
-			require.NotEmpty(t, got.SmsDateTime, "parser should stamp the SMS time")
-			got.SmsDateTime = ""
+			require.False(t, got.OccurredAt.IsZero(), "parser should stamp the SMS time")
+			got.OccurredAt = time.Time{}
 			assert.Equal(t, tt.want, got)
 		})
 	}
 }
+
+func TestParser_ParseAtResolvesSmsDate(t *testing.T) {
+	t.Parallel()
+
+	colombo := time.FixedZone("+0530", 5*60*60+30*60)
+	card := "Cr Crd no..**1234 Auth Pmt LKR 1,250.00 at MASKED SHOP Avl Bal LKR 41,648.06 Enq Call 0112000000 Sampath Bank %s"
+
+	tests := []struct {
+		name       string
+		sms        string
+		receivedAt time.Time
+		want       string
+	}{
+		{
+			name:       "stamp matches received date keeps received time",
+			sms:        fmt.Sprintf(card, "07-NOV"),
+			receivedAt: time.Date(2025, time.November, 7, 18, 42, 10, 0, colombo),
+			want:       "2025-11-07 18:42:10",
+		},
+		{
+			name:       "late delivery uses stamped date",
+			sms:        fmt.Sprintf(card, "05-NOV"),
+			receivedAt: time.Date(2025, time.November, 7, 9, 0, 0, 0, colombo),
+			want:       "2025-11-05 00:00:00",
+		},
+		{
+			name:       "december stamp received in january belongs to previous year",
+			sms:        fmt.Sprintf(card, "31-DEC"),
+			receivedAt: time.Date(2026, time.January, 1, 0, 10, 0, 0, colombo),
+			want:       "2025-12-31 00:00:00",
+		},
+		{
+			name:       "backfilled message from earlier in the year",
+			sms:        fmt.Sprintf(card, "14-feb"),
+			receivedAt: time.Date(2025, time.March, 2, 12, 0, 0, 0, colombo),
+			want:       "2025-02-14 00:00:00",
+		},
+		{
+			name:       "impossible stamp falls back to received time",
+			sms:        fmt.Sprintf(card, "31-FEB"),
+			receivedAt: time.Date(2025, time.March, 2, 12, 0, 0, 0, colombo),
+			want:       "2025-03-02 12:00:00",
+		},
+		{
+			name:       "account alert without stamp uses received time",
+			sms:        "LKR 4,005.00 debited from AC **4060 via ATM at MASKED BANK ATM CITY For Inq Call 0112000000, Sampath Bank",
+			receivedAt: time.Date(2025, time.November, 7, 7, 30, 0, 0, colombo),
+			want:       "2025-11-07 07:30:00",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := sampath.New().(smsparser.TimedSMSParser[*finance.Transaction])
+			got, err := parser.ParseAt(tt.sms, tt.receivedAt)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.OccurredAt.Format(time.DateTime))
+		})
+	}
+}
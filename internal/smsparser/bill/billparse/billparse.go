@@ -0,0 +1,194 @@
+// Package billparse holds the field parsers shared by the utility bill SMS
+// parsers. Sri Lankan utilities print bills as "Key: value" lines with
+// rupee amounts, meter readings as "previous-current=units" and dates
+// either as DD-MMM-YY or ISO dates.
+package billparse
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/ebill"
+)
+
+// Currency is the only currency the bills are issued in.
+const Currency = "LKR"
+
+var (
+	dateLayout     = "02-Jan-06"
+	isoDateLayouts = []string{"2006-01-02", "2006/01/02"}
+	amountRegex    = regexp.MustCompile(`[-+]?[\d,]+(?:\.\d+)?`)
+	readingRegex   = regexp.MustCompile(`(\d+)\s*-\s*(\d+)\s*=\s*(\d+)`)
+	netUnitsRegex  = regexp.MustCompile(`([-\d]+)(?:\s*\(([^)]+)\))?`)
+
+	// Custom errors
+	ErrInvalidValue   = errors.New("invalid value")
+	ErrInvalidDate    = errors.New("invalid date format")
+	ErrInvalidReading = errors.New("invalid reading format")
+	ErrInvalidAmount  = errors.New("invalid amount format")
+)
+
+// Field splits a "Key: value" line into its trimmed key, the key lowercased
+// for matching, and the value. ok is false for lines without a colon.
+func Field(line string) (key, normalizedKey, value string, ok bool) {
+	parts := strings.SplitN(line, ":", 2)
+	if len(parts) < 2 {
+		return "", "", "", false
+	}
+	key = strings.TrimSpace(parts[0])
+	return key, strings.ToLower(key), strings.TrimSpace(parts[1]), true
+}
+
+// ParseDate accepts ISO dates and DD-MMM-YY dates with the month in any
+// case.
+func ParseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range isoDateLayouts {
+		if len(s) >= len(layout) && (strings.Contains(s, "-") || strings.Contains(s, "/")) {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, nil
+			}
+		}
+	}
+
+	parts := strings.Split(s, "-")
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("%w: expected DD-MMM-YY format", ErrInvalidDate)
+	}
+
+	// Normalize month: "JUL" -> "Jul"
+	month := strings.ToUpper(parts[1])
+	if len(month) >= 3 {
+		month = strings.ToUpper(month[0:1]) + strings.ToLower(month[1:3])
+	}
+	dateStr := fmt.Sprintf("%s-%s-%s", parts[0], month, parts[2])
+
+	t, err := time.Parse(dateLayout, dateStr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %v", ErrInvalidDate, err)
+	}
+	return t, nil
+}
+
+// ParseReading reads "previous-current=units". The readings are stored in
+// ascending order whichever way round they were printed.
+func ParseReading(s string, prev, curr, units *int) error {
+	matches := readingRegex.FindStringSubmatch(s)
+	if len(matches) != 4 {
+		return fmt.Errorf("%w: expected format '123-456=789'", ErrInvalidReading)
+	}
+
+	var err error
+	first, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return fmt.Errorf("%w: previous reading", ErrInvalidReading)
+	}
+
+	second, err := strconv.Atoi(matches[2])
+	if err != nil {
+		return fmt.Errorf("%w: current reading", ErrInvalidReading)
+	}
+
+	parsedUnits, err := strconv.Atoi(matches[3])
+	if err != nil {
+		return fmt.Errorf("%w: units calculation", ErrInvalidReading)
+	}
+
+	if first <= second {
+		*prev = first
+		*curr = second
+	} else {
+		*prev = second
+		*curr = first
+	}
+
+	*units = parsedUnits
+	return nil
+}
+
+// ParseNetUnits reads "13 (Imp)", the type in brackets being optional.
+func ParseNetUnits(s string, units *int, unitType *string) error {
+	matches := netUnitsRegex.FindStringSubmatch(s)
+	if len(matches) < 2 {
+		return fmt.Errorf("%w: net units format", ErrInvalidValue)
+	}
+
+	var err error
+	*units, err = strconv.Atoi(matches[1])
+	if err != nil {
+		return fmt.Errorf("%w: net units value", ErrInvalidValue)
+	}
+
+	if len(matches) > 2 {
+		*unitType = strings.TrimSpace(matches[2])
+	} else {
+		*unitType = ""
+	}
+	return nil
+}
+
+// ParseUnits reads a plain unit count such as "1,250".
+func ParseUnits(s string) (int, error) {
+	units, err := strconv.Atoi(strings.ReplaceAll(strings.TrimSpace(s), ",", ""))
+	if err != nil {
+		return 0, fmt.Errorf("%w: units", ErrInvalidValue)
+	}
+	return units, nil
+}
+
+// ParseAmount reads the first number in s, e.g. "Rs. 1,234.56", as rupees.
+func ParseAmount(s string) (models.Money, error) {
+	match := amountRegex.FindString(s)
+	if match == "" {
+		return models.Money{}, fmt.Errorf("%w: no numeric value found", ErrInvalidAmount)
+	}
+
+	val, err := models.ParseMoney(match, Currency)
+	if err != nil {
+		return models.Money{}, fmt.Errorf("%w: %v", ErrInvalidAmount, err)
+	}
+
+	return val, nil
+}
+
+// ParseAmountOn reads "Rs. 100.00 on 01-JUL-25", the date being optional.
+func ParseAmountOn(s string) (models.Money, time.Time, error) {
+	amountPart, datePart, hasDate := strings.Cut(s, " on ")
+
+	amount, err := ParseAmount(amountPart)
+	if err != nil {
+		return models.Money{}, time.Time{}, err
+	}
+
+	var date time.Time
+	if hasDate {
+		date, err = ParseDate(datePart)
+	}
+
+	return amount, date, err
+}
+
+// Validate checks the fields every parsed electricity bill must have.
+func Validate(bill *ebill.ElectricityBill) error {
+	if bill.AccountNumber == "" {
+		return errors.New("account number is required")
+	}
+	if bill.ReadOn.IsZero() {
+		return errors.New("read date is required")
+	}
+	if bill.ImportUnits < 0 {
+		return errors.New("import units must be non-negative")
+	}
+	if bill.ExportUnits < 0 {
+		return errors.New("export units must be non-negative")
+	}
+	if bill.MonthlyBill.Minor < 0 {
+		return errors.New("monthly bill must be non-negative")
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package billparse_test
+
+import (
+	"testing"
+	"time"
+
+	"auto-finance/internal/models"
+	"auto-finance/internal/smsparser/bill/billparse"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDate(t *testing.T) {
+	want := time.Date(2025, time.July, 27, 0, 0, 0, 0, time.UTC)
+	for _, s := range []string{"27-JUL-25", "27-jul-25", "2025-07-27", "2025/07/27"} {
+		got, err := billparse.ParseDate(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, want, got, s)
+	}
+
+	_, err := billparse.ParseDate("27 July")
+	assert.ErrorIs(t, err, billparse.ErrInvalidDate)
+}
+
+func TestParseReading(t *testing.T) {
+	var prev, curr, units int
+	require.NoError(t, billparse.ParseReading("8120 - 7980 = 140", &prev, &curr, &units))
+	assert.Equal(t, []int{7980, 8120, 140}, []int{prev, curr, units})
+
+	assert.ErrorIs(t, billparse.ParseReading("140", &prev, &curr, &units), billparse.ErrInvalidReading)
+}
+
+func TestParseAmountOn(t *testing.T) {
+	amount, on, err := billparse.ParseAmountOn("Rs. 1,000.00 on 15-JUL-25")
+	require.NoError(t, err)
+	assert.Equal(t, models.NewMoney(100000, "LKR"), amount)
+	assert.Equal(t, time.Date(2025, time.July, 15, 0, 0, 0, 0, time.UTC), on)
+
+	amount, on, err = billparse.ParseAmountOn("Rs.-12,240.42")
+	require.NoError(t, err)
+	assert.Equal(t, models.NewMoney(-1224042, "LKR"), amount)
+	assert.True(t, on.IsZero())
+
+	_, _, err = billparse.ParseAmountOn("nothing due")
+	assert.ErrorIs(t, err, billparse.ErrInvalidAmount)
+}
+
+func TestField(t *testing.T) {
+	key, normalized, value, ok := billparse.Field("Meter No: 12:34-X")
+	assert.True(t, ok)
+	assert.Equal(t, "Meter No", key)
+	assert.Equal(t, "meter no", normalized)
+	assert.Equal(t, "12:34-X", value)
+
+	_, _, _, ok = billparse.Field("CEB E-Bill")
+	assert.False(t, ok)
+}
@@ -0,0 +1,159 @@
+package ceb
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	models "auto-finance/internal/models/ebill"
+	"auto-finance/internal/smsparser"
+	"auto-finance/internal/smsparser/bill/billparse"
+)
+
+type parser struct{}
+
+var (
+	accountRegex = regexp.MustCompile(`^(\d+)(?:\s*\(([^)]+)\))?`)
+	headingRegex = regexp.MustCompile(`(?i)\bceb\b|ceylon electricity`)
+
+	// Custom errors, shared with the other bill parsers.
+	ErrInvalidValue   = billparse.ErrInvalidValue
+	ErrInvalidDate    = billparse.ErrInvalidDate
+	ErrInvalidReading = billparse.ErrInvalidReading
+	ErrInvalidAmount  = billparse.ErrInvalidAmount
+	// ErrNotCEBBill is returned for messages without a CEB heading.
+	ErrNotCEBBill = errors.New("not a CEB bill")
+)
+
+func New() smsparser.SMSParser[*models.ElectricityBill] {
+	return &parser{}
+}
+
+// Parse reads a CEB e-bill SMS, a heading line naming CEB followed by
+// "Key: value" lines. The heading is required since other utilities use the
+// same keys. Keys vary between CEB regions, so several spellings are
+// accepted. Lines that are not recognized are kept in Extras.
+func (*parser) Parse(sms string) (*models.ElectricityBill, error) {
+	bill := &models.ElectricityBill{Provider: models.ProviderCEB}
+
+	var (
+		parseErr   error
+		identified bool
+		readingSet bool
+	)
+
+	for _, line := range strings.Split(sms, "\n") {
+		line = strings.TrimSpace(line)
+		key, normalizedKey, value, ok := billparse.Field(line)
+		if !ok {
+			if headingRegex.MatchString(line) {
+				identified = true
+			}
+			continue
+		}
+
+		var err error
+		switch normalizedKey {
+		case "acc no", "account no", "account number", "a/c no":
+			err = parseAccount(value, bill)
+		case "name", "account name":
+			bill.AccountName = value
+		case "tariff", "category":
+			bill.AccountType = value
+		case "read on", "reading date", "date of reading":
+			bill.ReadOn, err = billparse.ParseDate(value)
+		case "bill period":
+			err = parsePeriod(value, bill)
+		case "meter reading", "import reading", "import":
+			err = billparse.ParseReading(value, &bill.ImportPrevious, &bill.ImportCurrent, &bill.ImportUnits)
+			readingSet = err == nil
+		case "units", "units consumed":
+			// The reading already carries the units; a separate count is only
+			// used for bills without one.
+			if !readingSet {
+				bill.ImportUnits, err = billparse.ParseUnits(value)
+			}
+		case "export reading", "export":
+			err = billparse.ParseReading(value, &bill.ExportPrevious, &bill.ExportCurrent, &bill.ExportUnits)
+		case "net units":
+			err = billparse.ParseNetUnits(value, &bill.NetUnits, &bill.NetUnitsType)
+		case "charge for the month", "monthly charge", "current charges", "monthly bill":
+			bill.MonthlyBill, err = billparse.ParseAmount(value)
+		case "other charges":
+			bill.OtherCharges, err = billparse.ParseAmount(value)
+		case "sscl", "ssc levy":
+			bill.SSCL, err = billparse.ParseAmount(value)
+		case "balance b/f", "previous balance", "opening balance", "arrears":
+			bill.OpeningBalance, bill.OpeningBalanceDate, err = billparse.ParseAmountOn(value)
+		case "total amount due", "total due", "total payable", "amount due":
+			bill.TotalPayable, err = billparse.ParseAmount(value)
+		case "last payment", "last paid":
+			bill.LastPaymentAmount, bill.LastPaymentDate, err = billparse.ParseAmountOn(value)
+		case "due date", "pay before", "pay on or before":
+			bill.DueDate, err = billparse.ParseDate(value)
+		case "adjustments":
+			bill.Adjustments, err = billparse.ParseAmount(value)
+		case "generation units", "export units":
+			bill.GenerationUnits, err = billparse.ParseUnits(value)
+		case "generation amount", "export amount":
+			bill.GenerationAmount, err = billparse.ParseAmount(value)
+		case "last amount paid for generation", "last generation payment":
+			bill.LastGenPayment, err = billparse.ParseAmount(value)
+		default:
+			if bill.Extras == nil {
+				bill.Extras = make(map[string]string)
+			}
+			bill.Extras[key] = value
+		}
+
+		if err != nil && parseErr == nil {
+			parseErr = fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	if !identified {
+		return nil, ErrNotCEBBill
+	}
+	if err := billparse.Validate(bill); err != nil {
+		return nil, fmt.Errorf("failed to validate bill: %w", err)
+	}
+
+	return bill, parseErr
+}
+
+func (p *parser) GetName() string {
+	return "CEB SMS Parser"
+}
+
+// parseAccount reads "1234567890" or "1234567890 (D1)", the bracketed part
+// being the tariff category.
+func parseAccount(s string, bill *models.ElectricityBill) error {
+	matches := accountRegex.FindStringSubmatch(s)
+	if matches == nil {
+		return fmt.Errorf("%w: account format", ErrInvalidValue)
+	}
+
+	bill.AccountNumber = matches[1]
+	if matches[2] != "" && bill.AccountType == "" {
+		bill.AccountType = strings.TrimSpace(matches[2])
+	}
+	return nil
+}
+
+// parsePeriod reads "2025-09-05 to 2025-10-05" and takes the end of the
+// period as the reading date unless the bill gives one.
+func parsePeriod(s string, bill *models.ElectricityBill) error {
+	_, end, ok := strings.Cut(s, " to ")
+	if !ok {
+		return fmt.Errorf("%w: expected 'start to end'", ErrInvalidDate)
+	}
+	readOn, err := billparse.ParseDate(end)
+	if err != nil {
+		return err
+	}
+	if bill.ReadOn.IsZero() {
+		bill.ReadOn = readOn
+	}
+	return nil
+}
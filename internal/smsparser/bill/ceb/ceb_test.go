@@ -0,0 +1,164 @@
+package ceb_test
+
+import (
+	"testing"
+	"time"
+
+	money "auto-finance/internal/models"
+	models "auto-finance/internal/models/ebill"
+	"auto-finance/internal/smsparser/bill/ceb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParser_Parse(t *testing.T) {
+	tests := []struct {
+		name    string
+		sms     string
+		want    *models.ElectricityBill
+		wantErr string
+	}{
+		{
+			name: "domestic e-bill",
+			sms: `CEB E-Bill
+Acc No: 4512345678
+Name: MASKED A B
+Tariff: D1
+Bill Period: 2025-09-05 to 2025-10-05
+Meter Reading: 12345 - 12485 = 140
+Units: 140
+Charge for the Month: Rs. 3,990.00
+SSCL: Rs. 102.31
+Balance B/F: Rs. 0.00
+Total Amount Due: Rs. 4,092.31
+Last Payment: Rs. 3,850.00 on 12-SEP-25
+Pay Before: 2025-10-25`,
+			want: &models.ElectricityBill{
+				Provider:          models.ProviderCEB,
+				AccountNumber:     "4512345678",
+				AccountType:       "D1",
+				AccountName:       "MASKED A B",
+				ReadOn:            date("2025-10-05"),
+				ImportPrevious:    12345,
+				ImportCurrent:     12485,
+				ImportUnits:       140,
+				MonthlyBill:       money.NewMoney(399000, "LKR"),
+				SSCL:              money.NewMoney(10231, "LKR"),
+				OpeningBalance:    money.NewMoney(0, "LKR"),
+				TotalPayable:      money.NewMoney(409231, "LKR"),
+				LastPaymentAmount: money.NewMoney(385000, "LKR"),
+				LastPaymentDate:   date("2025-09-12"),
+				DueDate:           date("2025-10-25"),
+			},
+		},
+		{
+			name: "net accounting with solar export",
+			sms: `CEB Bill
+Account No: 4598765432 (D1)
+Date of Reading: 06-OCT-25
+Import Reading: 8120-7980=140
+Export Reading: 29575-30200=625
+Net Units: 485 (Exp)
+Monthly Charge: Rs.3,990.00
+SSC Levy: Rs.102.31
+Export Units: 430
+Export Amount: Rs.11,635.80
+Last Amount Paid for Generation: Rs.9,800.00
+Total Due: Rs.-7,543.49`,
+			want: &models.ElectricityBill{
+				Provider:         models.ProviderCEB,
+				AccountNumber:    "4598765432",
+				AccountType:      "D1",
+				ReadOn:           date("2025-10-06"),
+				ImportPrevious:   7980,
+				ImportCurrent:    8120,
+				ImportUnits:      140,
+				ExportPrevious:   29575,
+				ExportCurrent:    30200,
+				ExportUnits:      625,
+				NetUnits:         485,
+				NetUnitsType:     "Exp",
+				MonthlyBill:      money.NewMoney(399000, "LKR"),
+				SSCL:             money.NewMoney(10231, "LKR"),
+				GenerationUnits:  430,
+				GenerationAmount: money.NewMoney(1163580, "LKR"),
+				LastGenPayment:   money.NewMoney(980000, "LKR"),
+				TotalPayable:     money.NewMoney(-754349, "LKR"),
+			},
+		},
+		{
+			name: "units without a meter reading and unknown lines",
+			sms: `Ceylon Electricity Board
+Acc No: 4511111111
+Read On: 2025-10-05
+Units Consumed: 1,250
+Fixed Charge: Rs. 2,000.00
+Energy Charge: Rs. 60,000.00`,
+			want: &models.ElectricityBill{
+				Provider:      models.ProviderCEB,
+				AccountNumber: "4511111111",
+				ReadOn:        date("2025-10-05"),
+				ImportUnits:   1250,
+				Extras: map[string]string{
+					"Fixed Charge":  "Rs. 2,000.00",
+					"Energy Charge": "Rs. 60,000.00",
+				},
+			},
+		},
+		{
+			name: "LECO bill is not a CEB bill",
+			sms: `A/N: 0102881677 (DOMESTIC-01)
+Read On: 2025-10-05
+Monthly Bill: Rs.105.25`,
+			wantErr: ceb.ErrNotCEBBill.Error(),
+		},
+		{
+			name: "water bill with the same keys is not a CEB bill",
+			sms: `NWSDB Water Bill
+Acc No: 4512345678
+Read On: 2025-10-05
+Meter Reading: 1256 - 1234 = 22`,
+			wantErr: ceb.ErrNotCEBBill.Error(),
+		},
+		{
+			name: "missing reading date",
+			sms: `CEB E-Bill
+Acc No: 4512345678
+Charge for the Month: Rs. 3,990.00`,
+			wantErr: "read date is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ceb.New().Parse(tt.sms)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.Nil(t, got)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParser_Parse_InvalidField(t *testing.T) {
+	bill, err := ceb.New().Parse(`CEB E-Bill
+Acc No: 4512345678
+Read On: 2025-10-05
+Meter Reading: unreadable`)
+
+	assert.ErrorIs(t, err, ceb.ErrInvalidReading)
+	require.NotNil(t, bill, "the bill is returned with the fields that could be read")
+	assert.Equal(t, "4512345678", bill.AccountNumber)
+}
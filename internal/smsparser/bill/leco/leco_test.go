@@ -6,6 +6,7 @@ import (
 
 	"auto-finance/internal/smsparser/bill/leco"
 
+	money "auto-finance/internal/models"
 	models "auto-finance/internal/models/ebill"
 
 	"github.com/stretchr/testify/assert"
@@ -44,7 +45,9 @@ func TestParser_Parse(t *testing.T) {
 			want: func() *models.ElectricityBill {
 				readOn, _ := time.Parse("2006-01-02", "2025-10-05")
 				lastPayment, _ := time.Parse("2006-01-02", "2025-01-03")
+				dueDate, _ := time.Parse("2006-01-02", "2025-10-25")
 				return &models.ElectricityBill{
+					Provider:           models.ProviderLECO,
 					AccountNumber:      "0102881677",
 					AccountType:        "DOMESTIC-01",
 					AccountName:        "PERERA A B C",
@@ -57,15 +60,19 @@ func TestParser_Parse(t *testing.T) {
 					ExportUnits:        625,
 					NetUnits:           5,
 					NetUnitsType:       "",
-					MonthlyBill:        105.25,
-					OtherCharges:       12.34,
-					SSCL:               1.45,
-					OpeningBalance:     -12345.67,
+					MonthlyBill:        money.NewMoney(10525, "LKR"),
+					OtherCharges:       money.NewMoney(1234, "LKR"),
+					SSCL:               money.NewMoney(145, "LKR"),
+					OpeningBalance:     money.NewMoney(-1234567, "LKR"),
 					OpeningBalanceDate: time.Time{},
-					TotalPayable:       -12240.42,
-					LastPaymentAmount:  950.50,
+					TotalPayable:       money.NewMoney(-1224042, "LKR"),
+					LastPaymentAmount:  money.NewMoney(95050, "LKR"),
 					LastPaymentDate:    lastPayment,
-					LastGenPayment:     9800.00,
+					LastGenPayment:     money.NewMoney(980000, "LKR"),
+					DueDate:            dueDate,
+					Adjustments:        money.NewMoney(0, "LKR"),
+					GenerationUnits:    430,
+					GenerationAmount:   money.NewMoney(995000, "LKR"),
 				}
 			}(),
 			wantErr: false,
@@ -90,6 +97,7 @@ Last Amount Paid for Generation: Rs. 50.00`,
 				openingBalanceDate, _ := time.Parse("02-Jan-06", "01-Jul-25")
 				lastPaymentDate, _ := time.Parse("02-Jan-06", "15-Jul-25")
 				return &models.ElectricityBill{
+					Provider:           models.ProviderLECO,
 					AccountNumber:      "123456789",
 					AccountType:        "Domestic",
 					AccountName:        "Account Name Example",
@@ -102,15 +110,15 @@ Last Amount Paid for Generation: Rs. 50.00`,
 					ExportUnits:        9,
 					NetUnits:           13,
 					NetUnitsType:       "Imp",
-					MonthlyBill:        1234.56,
-					OtherCharges:       78.90,
-					SSCL:               12.34,
-					OpeningBalance:     100.00,
+					MonthlyBill:        money.NewMoney(123456, "LKR"),
+					OtherCharges:       money.NewMoney(7890, "LKR"),
+					SSCL:               money.NewMoney(1234, "LKR"),
+					OpeningBalance:     money.NewMoney(10000, "LKR"),
 					OpeningBalanceDate: openingBalanceDate,
-					TotalPayable:       1425.80,
-					LastPaymentAmount:  1000.00,
+					TotalPayable:       money.NewMoney(142580, "LKR"),
+					LastPaymentAmount:  money.NewMoney(100000, "LKR"),
 					LastPaymentDate:    lastPaymentDate,
-					LastGenPayment:     50.00,
+					LastGenPayment:     money.NewMoney(5000, "LKR"),
 				}
 			}(),
 			wantErr: false,
@@ -123,6 +131,7 @@ Imp: 5000-5100=100`,
 			want: func() *models.ElectricityBill {
 				readOn, _ := time.Parse("02-Jan-06", "15-Dec-24")
 				return &models.ElectricityBill{
+					Provider:       models.ProviderLECO,
 					AccountNumber:  "987654321",
 					ReadOn:         readOn,
 					ImportPrevious: 5000,
@@ -182,6 +191,7 @@ Imp: 5000-5100=100`,
 			want: func() *models.ElectricityBill {
 				readOn, _ := time.Parse("02-Jan-06", "01-Jan-25")
 				return &models.ElectricityBill{
+					Provider:       models.ProviderLECO,
 					AccountNumber:  "111222333",
 					ReadOn:         readOn,
 					ImportPrevious: 1000,
@@ -198,11 +208,12 @@ Read On: 01-JAN-25
 Net Units: -25 (Exp)
 Monthly Bill: Rs. 500.00`,
 			want: &models.ElectricityBill{
+				Provider:      models.ProviderLECO,
 				AccountNumber: "123456789",
 				ReadOn:        func() time.Time { t, _ := time.Parse("02-Jan-06", "01-Jan-25"); return t }(),
 				NetUnits:      -25,
 				NetUnitsType:  "Exp",
-				MonthlyBill:   500.00,
+				MonthlyBill:   money.NewMoney(50000, "LKR"),
 			},
 			wantErr: false,
 		},
@@ -213,10 +224,11 @@ Read On: 01-JAN-25
 Monthly Bill: Rs. 12,345.67
 Total Payable: Rs. 15,000.00`,
 			want: &models.ElectricityBill{
+				Provider:      models.ProviderLECO,
 				AccountNumber: "123456789",
 				ReadOn:        func() time.Time { t, _ := time.Parse("02-Jan-06", "01-Jan-25"); return t }(),
-				MonthlyBill:   12345.67,
-				TotalPayable:  15000.00,
+				MonthlyBill:   money.NewMoney(1234567, "LKR"),
+				TotalPayable:  money.NewMoney(1500000, "LKR"),
 			},
 			wantErr: false,
 		},
@@ -250,6 +262,7 @@ Imp: 1000-1050=50
 			name: "incomplete account name handling",
 			sms:  "A/N: 123456789 (Domestic)",
 			want: &models.ElectricityBill{
+				Provider:      models.ProviderLECO,
 				AccountNumber: "123456789",
 				AccountType:   "Domestic",
 			},
@@ -261,9 +274,10 @@ Imp: 1000-1050=50
 Read On: 01-JAN-25
 Opening Balance: Rs. 500.00`,
 			want: &models.ElectricityBill{
+				Provider:       models.ProviderLECO,
 				AccountNumber:  "123456789",
 				ReadOn:         func() time.Time { t, _ := time.Parse("02-Jan-06", "01-Jan-25"); return t }(),
-				OpeningBalance: 500.00,
+				OpeningBalance: money.NewMoney(50000, "LKR"),
 			},
 			wantErr: false,
 		},
@@ -273,9 +287,10 @@ Opening Balance: Rs. 500.00`,
 Read On: 01-JAN-25
 Last Payment: Rs. 1000.00`,
 			want: &models.ElectricityBill{
+				Provider:          models.ProviderLECO,
 				AccountNumber:     "123456789",
 				ReadOn:            func() time.Time { t, _ := time.Parse("02-Jan-06", "01-Jan-25"); return t }(),
-				LastPaymentAmount: 1000.00,
+				LastPaymentAmount: money.NewMoney(100000, "LKR"),
 			},
 			wantErr: false,
 		},
@@ -341,7 +356,7 @@ Monthly Bill: Rs. 0.00`,
 			check: func(t *testing.T, bill *models.ElectricityBill, err error) {
 				assert.NoError(t, err)
 				assert.Equal(t, "123456789", bill.AccountNumber)
-				assert.Equal(t, 0.0, bill.MonthlyBill)
+				assert.Equal(t, money.NewMoney(0, "LKR"), bill.MonthlyBill)
 			},
 		},
 		{
@@ -352,6 +367,32 @@ Monthly Bill: Rs. 0.00`,
 				assert.Nil(t, bill)  // Parser returns nil when validation fails
 			},
 		},
+		{
+			name: "unknown fields are kept as extras",
+			sms: `A/N: 123456789
+Read On: 01-JAN-25
+Adjustments: Rs.-25.50
+Green Levy: Rs.3.00
+Meter No: 12:34-X`,
+			check: func(t *testing.T, bill *models.ElectricityBill, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, money.NewMoney(-2550, "LKR"), bill.Adjustments)
+				assert.Equal(t, map[string]string{
+					"Green Levy": "Rs.3.00",
+					"Meter No":   "12:34-X",
+				}, bill.Extras)
+			},
+		},
+		{
+			name: "invalid generation units",
+			sms: `A/N: 123456789
+Read On: 01-JAN-25
+This Month Generation Units: many`,
+			check: func(t *testing.T, bill *models.ElectricityBill, err error) {
+				assert.ErrorIs(t, err, leco.ErrInvalidValue)
+				assert.NotNil(t, bill)
+			},
+		},
 		{
 			name: "validation error - negative monthly bill",
 			sms: `A/N: 123456789
@@ -0,0 +1,19 @@
+package leco
+
+import (
+	models "auto-finance/internal/models/ebill"
+	"auto-finance/internal/smsparser"
+)
+
+// Name is the registry and sender route name of the LECO parser.
+const Name = "leco"
+
+// Register adds the LECO parser to r, handing its bills to handle. Bills are
+// validated while parsing.
+func Register(r *smsparser.Registry, handle smsparser.Handler[*models.ElectricityBill]) error {
+	return smsparser.Register(r, smsparser.Registration[*models.ElectricityBill]{
+		Name:   Name,
+		Parser: New(),
+		Handle: handle,
+	})
+}
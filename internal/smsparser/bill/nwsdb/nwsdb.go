@@ -0,0 +1,136 @@
+package nwsdb
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	models "auto-finance/internal/models/waterbill"
+	"auto-finance/internal/smsparser"
+	"auto-finance/internal/smsparser/bill/billparse"
+)
+
+type parser struct{}
+
+var (
+	volumeRegex = regexp.MustCompile(`^\s*([\d,]+)\s*(?:m3|m³|cu\.?\s*m|cubic metres?)?\s*$`)
+
+	// Custom errors, shared with the other bill parsers.
+	ErrInvalidValue   = billparse.ErrInvalidValue
+	ErrInvalidDate    = billparse.ErrInvalidDate
+	ErrInvalidReading = billparse.ErrInvalidReading
+	ErrInvalidAmount  = billparse.ErrInvalidAmount
+	// ErrNotWaterBill is returned for messages without an NWSDB heading.
+	ErrNotWaterBill = errors.New("not an NWSDB water bill")
+)
+
+func New() smsparser.SMSParser[*models.WaterBill] {
+	return &parser{}
+}
+
+// Parse reads an NWSDB bill SMS, a heading line naming NWSDB or the water
+// board followed by "Key: value" lines. The heading is required since the
+// keys are the same as on electricity bills. Lines that are not recognized
+// are kept in Extras.
+func (*parser) Parse(sms string) (*models.WaterBill, error) {
+	bill := &models.WaterBill{Provider: models.ProviderNWSDB}
+
+	var (
+		parseErr   error
+		identified bool
+		readingSet bool
+	)
+
+	for _, line := range strings.Split(sms, "\n") {
+		line = strings.TrimSpace(line)
+		key, normalizedKey, value, ok := billparse.Field(line)
+		if !ok {
+			heading := strings.ToLower(line)
+			if strings.Contains(heading, "nwsdb") || strings.Contains(heading, "water") {
+				identified = true
+			}
+			continue
+		}
+
+		var err error
+		switch normalizedKey {
+		case "acc no", "account no", "account number", "a/c no":
+			bill.AccountNumber = value
+		case "name", "account name":
+			bill.AccountName = value
+		case "category", "tariff":
+			bill.Category = value
+		case "read on", "reading date", "date of reading", "bill date":
+			bill.ReadOn, err = billparse.ParseDate(value)
+		case "meter reading", "reading":
+			err = billparse.ParseReading(value, &bill.PreviousReading, &bill.CurrentReading, &bill.Units)
+			readingSet = err == nil
+		case "units", "consumption", "units consumed":
+			// The reading already carries the units; a separate volume is
+			// only used for bills without one.
+			if !readingSet {
+				bill.Units, err = parseVolume(value)
+			}
+		case "charges", "water charges", "charge for the month", "monthly charge", "current charges":
+			bill.Charges, err = billparse.ParseAmount(value)
+		case "service charge", "fixed charge":
+			bill.ServiceCharge, err = billparse.ParseAmount(value)
+		case "arrears", "balance b/f", "previous balance":
+			bill.Arrears, err = billparse.ParseAmount(value)
+		case "total due", "total amount due", "total payable", "amount due":
+			bill.TotalPayable, err = billparse.ParseAmount(value)
+		case "last payment", "last paid":
+			bill.LastPaymentAmount, bill.LastPaymentDate, err = billparse.ParseAmountOn(value)
+		case "due date", "pay before", "pay on or before":
+			bill.DueDate, err = billparse.ParseDate(value)
+		default:
+			if bill.Extras == nil {
+				bill.Extras = make(map[string]string)
+			}
+			bill.Extras[key] = value
+		}
+
+		if err != nil && parseErr == nil {
+			parseErr = fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	if !identified {
+		return nil, ErrNotWaterBill
+	}
+	if err := validate(bill); err != nil {
+		return nil, fmt.Errorf("failed to validate bill: %w", err)
+	}
+
+	return bill, parseErr
+}
+
+func (p *parser) GetName() string {
+	return "NWSDB SMS Parser"
+}
+
+// parseVolume reads a volume such as "22 m3" or "1,250 cu.m".
+func parseVolume(s string) (int, error) {
+	matches := volumeRegex.FindStringSubmatch(strings.ToLower(s))
+	if matches == nil {
+		return 0, fmt.Errorf("%w: units", ErrInvalidValue)
+	}
+	return billparse.ParseUnits(matches[1])
+}
+
+func validate(bill *models.WaterBill) error {
+	if bill.AccountNumber == "" {
+		return errors.New("account number is required")
+	}
+	if bill.ReadOn.IsZero() {
+		return errors.New("read date is required")
+	}
+	if bill.Units < 0 {
+		return errors.New("units must be non-negative")
+	}
+	if bill.Charges.Minor < 0 {
+		return errors.New("charges must be non-negative")
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package nwsdb_test
+
+import (
+	"testing"
+	"time"
+
+	money "auto-finance/internal/models"
+	models "auto-finance/internal/models/waterbill"
+	"auto-finance/internal/smsparser/bill/nwsdb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParser_Parse(t *testing.T) {
+	tests := []struct {
+		name    string
+		sms     string
+		want    *models.WaterBill
+		wantErr error
+	}{
+		{
+			name: "domestic bill",
+			sms: `NWSDB Water Bill
+Acc No: 10/06/026/1234/09
+Name: MASKED A B
+Category: Domestic
+Bill Date: 05-OCT-25
+Meter Reading: 1256 - 1234 = 22
+Units: 22 m3
+Water Charges: Rs. 1,650.00
+Service Charge: Rs. 300.00
+Arrears: Rs. 500.00
+Total Due: Rs. 2,450.00
+Last Payment: Rs. 1,600.00 on 12-SEP-25
+Due Date: 25-OCT-25`,
+			want: &models.WaterBill{
+				Provider:          models.ProviderNWSDB,
+				AccountNumber:     "10/06/026/1234/09",
+				AccountName:       "MASKED A B",
+				Category:          "Domestic",
+				ReadOn:            date("2025-10-05"),
+				PreviousReading:   1234,
+				CurrentReading:    1256,
+				Units:             22,
+				Charges:           money.NewMoney(165000, "LKR"),
+				ServiceCharge:     money.NewMoney(30000, "LKR"),
+				Arrears:           money.NewMoney(50000, "LKR"),
+				TotalPayable:      money.NewMoney(245000, "LKR"),
+				LastPaymentAmount: money.NewMoney(160000, "LKR"),
+				LastPaymentDate:   date("2025-09-12"),
+				DueDate:           date("2025-10-25"),
+			},
+		},
+		{
+			name: "consumption without a meter reading and unknown lines",
+			sms: `National Water Supply & Drainage Board
+Account No: 10/06/026/5678/01
+Reading Date: 2025-10-06
+Consumption: 1,250 cu.m
+Charges: Rs.95,000.00
+VAT: Rs.17,100.00`,
+			want: &models.WaterBill{
+				Provider:      models.ProviderNWSDB,
+				AccountNumber: "10/06/026/5678/01",
+				ReadOn:        date("2025-10-06"),
+				Units:         1250,
+				Charges:       money.NewMoney(9500000, "LKR"),
+				Extras:        map[string]string{"VAT": "Rs.17,100.00"},
+			},
+		},
+		{
+			name: "electricity bill without an NWSDB heading",
+			sms: `CEB E-Bill
+Acc No: 4512345678
+Read On: 2025-10-05
+Meter Reading: 12345 - 12485 = 140`,
+			wantErr: nwsdb.ErrNotWaterBill,
+		},
+		{
+			name: "missing reading date",
+			sms: `NWSDB Bill
+Acc No: 10/06/026/1234/09
+Charges: Rs. 1,650.00`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := nwsdb.New().Parse(tt.sms)
+			if tt.want == nil {
+				assert.Error(t, err)
+				if tt.wantErr != nil {
+					assert.ErrorIs(t, err, tt.wantErr)
+				}
+				assert.Nil(t, got)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParser_Parse_InvalidField(t *testing.T) {
+	bill, err := nwsdb.New().Parse(`NWSDB Bill
+Acc No: 10/06/026/1234/09
+Bill Date: 2025-10-05
+Units: about twenty`)
+
+	assert.ErrorIs(t, err, nwsdb.ErrInvalidValue)
+	require.NotNil(t, bill, "the bill is returned with the fields that could be read")
+	assert.Equal(t, "10/06/026/1234/09", bill.AccountNumber)
+}
@@ -0,0 +1,19 @@
+package nwsdb
+
+import (
+	models "auto-finance/internal/models/waterbill"
+	"auto-finance/internal/smsparser"
+)
+
+// Name is the registry and sender route name of the NWSDB parser.
+const Name = "nwsdb"
+
+// Register adds the NWSDB parser to r, handing its bills to handle. Bills
+// are validated while parsing.
+func Register(r *smsparser.Registry, handle smsparser.Handler[*models.WaterBill]) error {
+	return smsparser.Register(r, smsparser.Registration[*models.WaterBill]{
+		Name:   Name,
+		Parser: New(),
+		Handle: handle,
+	})
+}
@@ -0,0 +1,37 @@
+package smsparser
+
+import "time"
+
+// Attempt is the outcome of running one parser against an SMS.
+type Attempt struct {
+	Parser string
+	Result interface{}
+	Err    error
+}
+
+// Evaluation collects the outcome of every parser for one SMS.
+type Evaluation struct {
+	Attempts []Attempt
+}
+
+// Evaluate runs message through every parser, without stopping at the first
+// match, so that a dry run can show how each parser reacts.
+func Evaluate(parsers []UniversalParser, message string, receivedAt time.Time) Evaluation {
+	attempts := make([]Attempt, 0, len(parsers))
+	for _, p := range parsers {
+		result, err := p.ParseAt(message, receivedAt)
+		attempts = append(attempts, Attempt{Parser: p.GetName(), Result: result, Err: err})
+	}
+	return Evaluation{Attempts: attempts}
+}
+
+// Matched returns the first successful attempt, which is the one the message
+// service would use when falling back through all parsers.
+func (e Evaluation) Matched() (Attempt, bool) {
+	for _, a := range e.Attempts {
+		if a.Err == nil {
+			return a, true
+		}
+	}
+	return Attempt{}, false
+}
@@ -0,0 +1,37 @@
+package smsparser_test
+
+import (
+	"testing"
+	"time"
+
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/smsparser"
+	"auto-finance/internal/smsparser/banking/hnb"
+	"auto-finance/internal/smsparser/banking/sampath"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+	parsers := []smsparser.UniversalParser{
+		smsparser.NewGenericParserWrapper(sampath.New()),
+		smsparser.NewGenericParserWrapper(hnb.New()),
+	}
+
+	sms := "HNB Credit Card Purchase Alert: LKR 2,450.00 at MASKED SUPER KOTTE on card ending 1234 on 2024-01-15 14:30:00. Avl Limit LKR 97,550.00"
+	eval := smsparser.Evaluate(parsers, sms, time.Date(2024, time.January, 15, 14, 31, 0, 0, time.UTC))
+
+	require.Len(t, eval.Attempts, 2)
+	assert.Equal(t, parsers[0].GetName(), eval.Attempts[0].Parser)
+	assert.Error(t, eval.Attempts[0].Err)
+
+	matched, ok := eval.Matched()
+	require.True(t, ok)
+	assert.Equal(t, parsers[1].GetName(), matched.Parser)
+	require.IsType(t, &finance.Transaction{}, matched.Result)
+	assert.Equal(t, "MASKED SUPER KOTTE", matched.Result.(*finance.Transaction).Counterparty)
+
+	_, ok = smsparser.Evaluate(parsers, "hello", time.Now()).Matched()
+	assert.False(t, ok)
+}
@@ -1,13 +1,23 @@
 package smsparser
 
+import "time"
+
 type SMSParser[T any] interface {
 	GetName() string
 	Parse(message string) (T, error)
 }
 
+// TimedSMSParser is implemented by parsers that use the time the SMS was
+// received to resolve dates that are only partially present in the body.
+type TimedSMSParser[T any] interface {
+	SMSParser[T]
+	ParseAt(message string, receivedAt time.Time) (T, error)
+}
+
 type UniversalParser interface {
 	GetName() string
 	Parse(message string) (interface{}, error) // Note: returns interface{}
+	ParseAt(message string, receivedAt time.Time) (interface{}, error)
 }
 
 type GenericSMSParseWrapper[T any] struct {
@@ -22,6 +32,15 @@ func (g GenericSMSParseWrapper[T]) Parse(message string) (interface{}, error) {
 	return g.parser.Parse(message)
 }
 
+// ParseAt forwards the received time to parsers that support it and falls
+// back to Parse for the rest.
+func (g GenericSMSParseWrapper[T]) ParseAt(message string, receivedAt time.Time) (interface{}, error) {
+	if timed, ok := g.parser.(TimedSMSParser[T]); ok {
+		return timed.ParseAt(message, receivedAt)
+	}
+	return g.parser.Parse(message)
+}
+
 // NewGenericParserWrapper creates a new wrapper for a generic parser
 func NewGenericParserWrapper[T any](p SMSParser[T]) UniversalParser {
 	return GenericSMSParseWrapper[T]{parser: p}
@@ -0,0 +1,125 @@
+package smsparser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"auto-finance/internal/errors"
+)
+
+// Handler stores or otherwise acts on a parsed message.
+type Handler[T any] func(ctx context.Context, v T) error
+
+// Plugin is a parser together with the handler for what it parses, so that
+// the message service can process any institution without knowing its
+// result type.
+type Plugin interface {
+	// Name is the registry key, also used by sender routes.
+	Name() string
+	Parser() UniversalParser
+	// Handle validates and handles a result returned by Parser.
+	Handle(ctx context.Context, result interface{}) error
+}
+
+// Registration describes a typed parser plugin.
+type Registration[T any] struct {
+	Name   string
+	Parser SMSParser[T]
+	// Validate is optional and runs before Handle.
+	Validate func(T) error
+	Handle   Handler[T]
+}
+
+type plugin[T any] struct {
+	reg    Registration[T]
+	parser UniversalParser
+}
+
+// NewPlugin wraps a typed registration as a Plugin.
+func NewPlugin[T any](reg Registration[T]) Plugin {
+	return &plugin[T]{reg: reg, parser: NewGenericParserWrapper(reg.Parser)}
+}
+
+func (p *plugin[T]) Name() string {
+	return p.reg.Name
+}
+
+func (p *plugin[T]) Parser() UniversalParser {
+	return p.parser
+}
+
+func (p *plugin[T]) Handle(ctx context.Context, result interface{}) error {
+	v, ok := result.(T)
+	if !ok {
+		return errors.NewTypedError(fmt.Errorf("plugin %s cannot handle %T", p.reg.Name, result), errors.ErrorTypeInternal)
+	}
+
+	if p.reg.Validate != nil {
+		if err := p.reg.Validate(v); err != nil {
+			return errors.NewTypedError(fmt.Errorf("%s validation failed: %w", p.reg.Name, err), errors.ErrorTypeValidation)
+		}
+	}
+
+	if p.reg.Handle == nil {
+		return errors.NewTypedError(fmt.Errorf("plugin %s has no handler", p.reg.Name), errors.ErrorTypeConfig)
+	}
+
+	return p.reg.Handle(ctx, v)
+}
+
+// Registry holds the enabled plugins in the order they are tried when a
+// sender has no route.
+type Registry struct {
+	plugins []Plugin
+	byName  map[string]Plugin
+}
+
+func NewRegistry() *Registry {
+	return &Registry{byName: make(map[string]Plugin)}
+}
+
+// Register adds a typed plugin to r.
+func Register[T any](r *Registry, reg Registration[T]) error {
+	return r.Add(NewPlugin(reg))
+}
+
+// Add appends p to the registry. Names are case insensitive and must be
+// unique.
+func (r *Registry) Add(p Plugin) error {
+	name := normalizeName(p.Name())
+	if name == "" {
+		return fmt.Errorf("plugin name is required")
+	}
+	if _, ok := r.byName[name]; ok {
+		return fmt.Errorf("plugin %q already registered", p.Name())
+	}
+
+	r.byName[name] = p
+	r.plugins = append(r.plugins, p)
+	return nil
+}
+
+// Get looks a plugin up by name.
+func (r *Registry) Get(name string) (Plugin, bool) {
+	p, ok := r.byName[normalizeName(name)]
+	return p, ok
+}
+
+// Plugins returns the plugins in registration order.
+func (r *Registry) Plugins() []Plugin {
+	return append([]Plugin(nil), r.plugins...)
+}
+
+// Parsers returns the parser of every plugin in registration order.
+func (r *Registry) Parsers() []UniversalParser {
+	parsers := make([]UniversalParser, 0, len(r.plugins))
+	for _, p := range r.plugins {
+		parsers = append(parsers, p.Parser())
+	}
+	return parsers
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,69 @@
+package smsparser_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appErrors "auto-finance/internal/errors"
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/smsparser"
+	"auto-finance/internal/smsparser/banking/hnb"
+	"auto-finance/internal/smsparser/banking/sampath"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	var handled []*finance.Transaction
+	handle := func(_ context.Context, txn *finance.Transaction) error {
+		handled = append(handled, txn)
+		return nil
+	}
+
+	registry := smsparser.NewRegistry()
+	require.NoError(t, sampath.Register(registry, handle))
+	require.NoError(t, hnb.Register(registry, handle))
+	assert.Error(t, hnb.Register(registry, handle), "names must be unique")
+
+	parsers := registry.Parsers()
+	require.Len(t, parsers, 2)
+	assert.Equal(t, sampath.New().GetName(), parsers[0].GetName())
+
+	plugin, ok := registry.Get(" HNB ")
+	require.True(t, ok)
+	assert.Equal(t, hnb.Name, plugin.Name())
+
+	t.Run("parse and handle", func(t *testing.T) {
+		result, err := plugin.Parser().Parse("Transaction Alert: Rs.1,000.00 debited from A/C XXXX1234 on 2024-01-15 14:30:00")
+		require.NoError(t, err)
+		require.NoError(t, plugin.Handle(context.Background(), result))
+		require.Len(t, handled, 1)
+		assert.Equal(t, "1234", handled[0].Account)
+	})
+
+	t.Run("invalid result is not handled", func(t *testing.T) {
+		err := plugin.Handle(context.Background(), &finance.Transaction{Institution: finance.InstitutionHNB})
+		assert.Equal(t, appErrors.ErrorTypeValidation, appErrors.ErrorTypeOf(err))
+		assert.Len(t, handled, 1)
+	})
+
+	t.Run("unexpected result type", func(t *testing.T) {
+		err := plugin.Handle(context.Background(), "not a transaction")
+		assert.Error(t, err)
+	})
+}
+
+func TestPlugin_HandlerError(t *testing.T) {
+	want := errors.New("sheet unavailable")
+	plugin := smsparser.NewPlugin(smsparser.Registration[*finance.Transaction]{
+		Name:   "sampath",
+		Parser: sampath.New(),
+		Handle: func(context.Context, *finance.Transaction) error { return want },
+	})
+
+	result, err := plugin.Parser().Parse("LKR 4,005.00 debited from AC **4060 via ATM at MASKED BANK ATM CITY For Inq Call 0112000000, Sampath Bank")
+	require.NoError(t, err)
+	assert.ErrorIs(t, plugin.Handle(context.Background(), result), want)
+}
@@ -0,0 +1,287 @@
+// Package template parses bank alerts with regular expressions defined in the
+// configuration, so that simple single-line formats can be supported without
+// a new build.
+package template
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/smsparser"
+)
+
+// Transaction fields a template can fill in. Other field names are stored as
+// transaction extensions.
+const (
+	FieldAccount         = "account"
+	FieldDirection       = "direction"
+	FieldChannel         = "channel"
+	FieldAmount          = "amount"
+	FieldCurrency        = "currency"
+	FieldCounterparty    = "counterparty"
+	FieldBalance         = "balance"
+	FieldBalanceCurrency = "balance_currency"
+	FieldStatus          = "status"
+	FieldOccurredAt      = "occurred_at"
+)
+
+var (
+	ErrInvalidDefinition  = errors.New("invalid template definition")
+	ErrUnrecognizedFormat = errors.New("unrecognized SMS format")
+
+	knownFields = map[string]bool{
+		FieldAccount: true, FieldDirection: true, FieldChannel: true, FieldAmount: true,
+		FieldCurrency: true, FieldCounterparty: true, FieldBalance: true,
+		FieldBalanceCurrency: true, FieldStatus: true, FieldOccurredAt: true,
+	}
+	channels = []finance.TransactionType{
+		finance.TransactionTypeCard, finance.TransactionTypeOnline,
+		finance.TransactionTypeATM, finance.TransactionTypeCEFT,
+	}
+	groupRefRegex = regexp.MustCompile(`\$\{?(\w+)\}?`)
+)
+
+// Definition is a [[templates]] entry of the configuration.
+type Definition struct {
+	// Name is the registry and sender route name.
+	Name        string `toml:"name"`
+	Institution string `toml:"institution"`
+	// Sender, when set, routes this sender ID to the template.
+	Sender string `toml:"sender"`
+	// Regex is matched against the SMS with whitespace collapsed to single
+	// spaces. Named groups with a field name (e.g. (?P<amount>...)) fill that
+	// field unless Fields maps it.
+	Regex string `toml:"regex"`
+	// Fields maps a transaction field to a value expanded from the match,
+	// e.g. counterparty = "${merchant} ${city}" or channel = "ATM".
+	Fields map[string]string `toml:"fields"`
+	// Directions maps direction tokens (case insensitive) to "debit" or
+	// "credit". Tokens starting with "debit" or "credit" need no entry.
+	Directions map[string]string `toml:"directions"`
+	// Currency is used when the match has no currency.
+	Currency string `toml:"currency"`
+	// DecimalSeparator is "." (default) or "," for amounts like "1.250,50".
+	DecimalSeparator string `toml:"decimal_separator"`
+	// DateLayouts are Go time layouts tried for occurred_at. Layouts without a
+	// year take it from the received time. Without occurred_at the received
+	// time is used.
+	DateLayouts []string `toml:"date_layouts"`
+}
+
+type parser struct {
+	def   Definition
+	regex *regexp.Regexp
+}
+
+// New compiles a template definition into a parser.
+func New(def Definition) (smsparser.TimedSMSParser[*finance.Transaction], error) {
+	if def.Name == "" || def.Institution == "" || def.Regex == "" {
+		return nil, fmt.Errorf("%w: name, institution and regex are required", ErrInvalidDefinition)
+	}
+
+	re, err := regexp.Compile(def.Regex)
+	if err != nil {
+		return nil, fmt.Errorf("%w: template %s: %v", ErrInvalidDefinition, def.Name, err)
+	}
+
+	groups := make(map[string]bool)
+	for _, name := range re.SubexpNames() {
+		if name != "" {
+			groups[name] = true
+		}
+	}
+	for field, value := range def.Fields {
+		for _, ref := range groupRefRegex.FindAllStringSubmatch(value, -1) {
+			if !groups[ref[1]] {
+				return nil, fmt.Errorf("%w: template %s field %s refers to unknown group %q", ErrInvalidDefinition, def.Name, field, ref[1])
+			}
+		}
+	}
+	for _, field := range []string{FieldAccount, FieldAmount, FieldDirection} {
+		if _, ok := def.Fields[field]; !ok && !groups[field] {
+			return nil, fmt.Errorf("%w: template %s does not set %s", ErrInvalidDefinition, def.Name, field)
+		}
+	}
+	if sep := def.DecimalSeparator; sep != "" && sep != "." && sep != "," {
+		return nil, fmt.Errorf("%w: template %s decimal separator %q", ErrInvalidDefinition, def.Name, sep)
+	}
+	for token, direction := range def.Directions {
+		if _, err := parseDirection(direction, nil); err != nil {
+			return nil, fmt.Errorf("%w: template %s direction %q: %v", ErrInvalidDefinition, def.Name, token, err)
+		}
+	}
+
+	return &parser{def: def, regex: re}, nil
+}
+
+// Register compiles def and adds it to r, handing its transactions to handle.
+func Register(r *smsparser.Registry, def Definition, handle smsparser.Handler[*finance.Transaction]) error {
+	p, err := New(def)
+	if err != nil {
+		return err
+	}
+
+	return smsparser.Register(r, smsparser.Registration[*finance.Transaction]{
+		Name:     def.Name,
+		Parser:   p,
+		Validate: (*finance.Transaction).Validate,
+		Handle:   handle,
+	})
+}
+
+func (p *parser) GetName() string {
+	return p.def.Institution + " Template Parser (" + p.def.Name + ")"
+}
+
+func (p *parser) Parse(sms string) (*finance.Transaction, error) {
+	return p.ParseAt(sms, time.Now())
+}
+
+func (p *parser) ParseAt(sms string, receivedAt time.Time) (*finance.Transaction, error) {
+	cleaned := strings.Join(strings.Fields(sms), " ")
+
+	match := p.regex.FindStringSubmatchIndex(cleaned)
+	if match == nil {
+		return nil, ErrUnrecognizedFormat
+	}
+	field := func(name string) string {
+		if tmpl, ok := p.def.Fields[name]; ok {
+			return strings.TrimSpace(string(p.regex.ExpandString(nil, tmpl, cleaned, match)))
+		}
+		if i := p.regex.SubexpIndex(name); i >= 0 && match[2*i] >= 0 {
+			return strings.TrimSpace(cleaned[match[2*i]:match[2*i+1]])
+		}
+		return ""
+	}
+
+	direction, err := parseDirection(field(FieldDirection), p.def.Directions)
+	if err != nil {
+		return nil, err
+	}
+
+	currency := normalizeCurrency(field(FieldCurrency), p.def.Currency)
+	amount, err := models.ParseMoney(p.normalizeAmount(field(FieldAmount)), currency)
+	if err != nil {
+		return nil, err
+	}
+
+	occurredAt, err := p.parseTime(field(FieldOccurredAt), receivedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	status := field(FieldStatus)
+	if status == "" {
+		status = string(direction)
+	}
+
+	txn := &finance.Transaction{
+		Institution:  p.def.Institution,
+		Account:      field(FieldAccount),
+		Direction:    direction,
+		Channel:      normalizeChannel(field(FieldChannel)),
+		Amount:       amount,
+		Counterparty: field(FieldCounterparty),
+		Status:       strings.ToLower(status),
+		OccurredAt:   occurredAt,
+	}
+
+	if raw := field(FieldBalance); raw != "" {
+		balance, err := models.ParseMoney(p.normalizeAmount(raw), normalizeCurrency(field(FieldBalanceCurrency), currency))
+		if err != nil {
+			return nil, err
+		}
+		txn.BalanceAfter = balance
+	}
+
+	for name := range p.def.Fields {
+		if !knownFields[name] {
+			txn.SetExtension(name, field(name))
+		}
+	}
+
+	return txn, nil
+}
+
+// parseDirection maps a direction token through directions, accepting
+// tokens such as "debited" or "Credit" without a mapping.
+func parseDirection(token string, directions map[string]string) (finance.Direction, error) {
+	token = strings.ToLower(strings.TrimSpace(token))
+	for k, v := range directions {
+		if strings.EqualFold(k, token) {
+			token = strings.ToLower(v)
+			break
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(token, "debit"):
+		return finance.DirectionDebit, nil
+	case strings.HasPrefix(token, "credit"):
+		return finance.DirectionCredit, nil
+	}
+	return "", fmt.Errorf("unknown direction %q", token)
+}
+
+func normalizeCurrency(currency, fallback string) string {
+	currency = strings.ToUpper(strings.TrimSuffix(strings.TrimSpace(currency), "."))
+	switch currency {
+	case "":
+		return strings.ToUpper(fallback)
+	case "RS":
+		return "LKR"
+	}
+	return currency
+}
+
+// normalizeAmount strips grouping separators and converts a decimal comma
+// to a point.
+func (p *parser) normalizeAmount(raw string) string {
+	raw = strings.NewReplacer(" ", "", "'", "").Replace(raw)
+	if p.def.DecimalSeparator == "," {
+		return strings.ReplaceAll(strings.ReplaceAll(raw, ".", ""), ",", ".")
+	}
+	return strings.ReplaceAll(raw, ",", "")
+}
+
+func normalizeChannel(raw string) finance.TransactionType {
+	for _, c := range channels {
+		if strings.EqualFold(raw, string(c)) {
+			return c
+		}
+	}
+	return finance.TransactionType(raw)
+}
+
+// parseTime reads the occurred_at value in the receivedAt location. A layout
+// without a year takes the received year, stepping back one year when that
+// would put the transaction in the future.
+func (p *parser) parseTime(raw string, receivedAt time.Time) (time.Time, error) {
+	if raw == "" {
+		return receivedAt, nil
+	}
+
+	for _, layout := range p.def.DateLayouts {
+		t, err := time.ParseInLocation(layout, raw, receivedAt.Location())
+		if err != nil {
+			continue
+		}
+		if t.Year() == 0 {
+			withYear := func(year int) time.Time {
+				return time.Date(year, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, t.Location())
+			}
+			t = withYear(receivedAt.Year())
+			if t.After(receivedAt.AddDate(0, 0, 1)) {
+				t = withYear(receivedAt.Year() - 1)
+			}
+		}
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized %s %q", FieldOccurredAt, raw)
+}
@@ -0,0 +1,174 @@
+package template_test
+
+import (
+	"testing"
+	"time"
+
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/smsparser/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var colombo = time.FixedZone("+0530", 5*60*60+30*60)
+
+func TestParser_ParseAt(t *testing.T) {
+	t.Parallel()
+
+	receivedAt := time.Date(2025, time.March, 2, 12, 0, 0, 0, colombo)
+
+	tests := []struct {
+		name string
+		def  template.Definition
+		sms  string
+		want *finance.Transaction
+	}{
+		{
+			name: "named groups map to fields",
+			def: template.Definition{
+				Name:        "ntb",
+				Institution: "NTB",
+				Regex:       `(?i)^(?P<currency>[A-Z]{3})\s+(?P<amount>[\d,.]+)\s+(?P<direction>credited|debited)\s+(?:to|from)\s+A/C\s+\*+(?P<account>\d{4})\s+for\s+(?P<counterparty>.+?)\s+Bal\s+(?P<balance>[\d,.]+)`,
+				Fields:      map[string]string{"channel": "Online"},
+			},
+			sms: "LKR 12,500.00 debited from A/C ***6789 for MASKED GROCERY\nBal 87,500.00",
+			want: &finance.Transaction{
+				Institution:  "NTB",
+				Account:      "6789",
+				Direction:    finance.DirectionDebit,
+				Channel:      finance.TransactionTypeOnline,
+				Amount:       models.NewMoney(1250000, "LKR"),
+				Counterparty: "MASKED GROCERY",
+				BalanceAfter: models.NewMoney(8750000, "LKR"),
+				Status:       "debit",
+				OccurredAt:   receivedAt,
+			},
+		},
+		{
+			name: "field templates, direction tokens, default currency and date without year",
+			def: template.Definition{
+				Name:        "boc",
+				Institution: "BOC",
+				Regex:       `(?i)(?P<kind>Withdrawal|Deposit) Rs\.(?P<amt>[\d,.]+) on (?P<date>\d{2}/\d{2} \d{2}:\d{2}) A/C (?P<acct>\d+) at (?P<place>.+?) \((?P<city>\w+)\)`,
+				Fields: map[string]string{
+					"amount":       "$amt",
+					"account":      "$acct",
+					"direction":    "$kind",
+					"occurred_at":  "$date",
+					"counterparty": "${place} ${city}",
+					"channel":      "atm",
+					"city":         "$city",
+				},
+				Directions:  map[string]string{"withdrawal": "debit", "DEPOSIT": "credit"},
+				Currency:    "lkr",
+				DateLayouts: []string{"02/01 15:04"},
+			},
+			sms: "Deposit Rs.5,000.00 on 28/12 09:15 A/C 001122 at MASKED CDM (KANDY)",
+			want: &finance.Transaction{
+				Institution:  "BOC",
+				Account:      "001122",
+				Direction:    finance.DirectionCredit,
+				Channel:      finance.TransactionTypeATM,
+				Amount:       models.NewMoney(500000, "LKR"),
+				Counterparty: "MASKED CDM KANDY",
+				Status:       "credit",
+				OccurredAt:   time.Date(2024, time.December, 28, 9, 15, 0, 0, colombo),
+				Extensions:   map[string]string{"city": "KANDY"},
+			},
+		},
+		{
+			name: "decimal comma",
+			def: template.Definition{
+				Name:             "eur",
+				Institution:      "EUR Bank",
+				Regex:            `(?P<direction>Debit) (?P<amount>[\d.,]+) (?P<currency>EUR) card (?P<account>\d+)`,
+				DecimalSeparator: ",",
+			},
+			sms: "Debit 1.250,50 EUR card 4321",
+			want: &finance.Transaction{
+				Institution: "EUR Bank",
+				Account:     "4321",
+				Direction:   finance.DirectionDebit,
+				Amount:      models.NewMoney(125050, "EUR"),
+				Status:      "debit",
+				OccurredAt:  receivedAt,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := template.New(tt.def)
+			require.NoError(t, err)
+
+			got, err := p.ParseAt(tt.sms, receivedAt)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, got.Validate())
+		})
+	}
+}
+
+func TestParser_ParseAtErrors(t *testing.T) {
+	t.Parallel()
+
+	p, err := template.New(template.Definition{
+		Name:        "ntb",
+		Institution: "NTB",
+		Regex:       `(?P<direction>\w+) (?P<amount>\S+) (?P<currency>[A-Z]{3}) A/C (?P<account>\d+)(?: on (?P<occurred_at>.+))?`,
+		DateLayouts: []string{time.DateOnly},
+	})
+	require.NoError(t, err)
+
+	for _, sms := range []string{
+		"Your OTP is 123456",
+		"Refund 100.00 LKR A/C 1234",
+		"Debited 1.005 LKR A/C 1234",
+		"Debited 100.00 LKR A/C 1234 on yesterday",
+	} {
+		got, err := p.Parse(sms)
+		assert.Error(t, err, sms)
+		assert.Nil(t, got)
+	}
+
+	_, err = p.Parse("Unknown 100.00 LKR A/C 1234")
+	assert.NotErrorIs(t, err, template.ErrUnrecognizedFormat)
+	_, err = p.Parse("hello")
+	assert.ErrorIs(t, err, template.ErrUnrecognizedFormat)
+}
+
+func TestNew_InvalidDefinition(t *testing.T) {
+	t.Parallel()
+
+	valid := template.Definition{
+		Name:        "ntb",
+		Institution: "NTB",
+		Regex:       `(?P<direction>\w+) (?P<amount>\S+) A/C (?P<account>\d+)`,
+	}
+
+	tests := map[string]func(d *template.Definition){
+		"missing name":        func(d *template.Definition) { d.Name = "" },
+		"bad regex":           func(d *template.Definition) { d.Regex = "(" },
+		"unknown group":       func(d *template.Definition) { d.Fields = map[string]string{"counterparty": "$merchant"} },
+		"missing amount":      func(d *template.Definition) { d.Regex = `(?P<direction>\w+) A/C (?P<account>\d+)` },
+		"bad separator":       func(d *template.Definition) { d.DecimalSeparator = "'" },
+		"bad direction value": func(d *template.Definition) { d.Directions = map[string]string{"out": "sideways"} },
+	}
+
+	for name, mutate := range tests {
+		mutate := mutate
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			def := valid
+			mutate(&def)
+			_, err := template.New(def)
+			assert.ErrorIs(t, err, template.ErrInvalidDefinition)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package balance
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/storage"
+	"auto-finance/internal/storage/jsonfile"
+)
+
+// FileStorage persists expected balances to a JSON file so that
+// reconciliation survives restarts without re-reading the sheet.
+type FileStorage struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFile creates a file backed balance storage at path
+func NewFile(path string) storage.BalanceStorage {
+	return &FileStorage{path: path}
+}
+
+// Get returns the balance for key
+func (s *FileStorage) Get(_ context.Context, key string) (finance.Balance, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	balances, err := s.load()
+	if err != nil {
+		return finance.Balance{}, false, err
+	}
+
+	b, ok := balances[key]
+	return b, ok, nil
+}
+
+// Put stores the balance for key
+func (s *FileStorage) Put(_ context.Context, key string, balance finance.Balance) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	balances, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	balances[key] = balance
+	return s.store(balances)
+}
+
+// Delete forgets the balance for key
+func (s *FileStorage) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	balances, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := balances[key]; !ok {
+		return nil
+	}
+
+	delete(balances, key)
+	return s.store(balances)
+}
+
+func (s *FileStorage) load() (map[string]finance.Balance, error) {
+	balances := make(map[string]finance.Balance)
+	if err := jsonfile.Load(s.path, &balances); err != nil {
+		return nil, fmt.Errorf("failed to load balances: %w", err)
+	}
+	return balances, nil
+}
+
+func (s *FileStorage) store(balances map[string]finance.Balance) error {
+	if err := jsonfile.Save(s.path, balances); err != nil {
+		return fmt.Errorf("failed to store balances: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package balance_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/storage/balance"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStorage_PersistsAcrossInstances(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state", "balances.json")
+	at := time.Date(2025, time.November, 7, 18, 0, 0, 0, time.UTC)
+
+	first := balance.NewFile(path)
+	_, ok, err := first.Get(ctx, "Sampath|1234")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	want := finance.Balance{Expected: models.NewMoney(4500000, "LKR"), At: at, CheckpointAt: at}
+	require.NoError(t, first.Put(ctx, "Sampath|1234", want))
+
+	second := balance.NewFile(path)
+	got, ok, err := second.Get(ctx, "Sampath|1234")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, want.Expected, got.Expected)
+	assert.True(t, want.At.Equal(got.At))
+
+	require.NoError(t, second.Delete(ctx, "Sampath|1234"))
+	_, ok, err = first.Get(ctx, "Sampath|1234")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,48 @@
+package balance
+
+import (
+	"context"
+	"sync"
+
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/storage"
+)
+
+// MemoryStorage keeps expected balances in process memory. It is meant for
+// tests and single-process runs; balances are lost on restart.
+type MemoryStorage struct {
+	mu       sync.Mutex
+	balances map[string]finance.Balance
+}
+
+// NewMemory creates an in-memory balance storage
+func NewMemory() storage.BalanceStorage {
+	return &MemoryStorage{balances: make(map[string]finance.Balance)}
+}
+
+// Get returns the balance for key
+func (s *MemoryStorage) Get(_ context.Context, key string) (finance.Balance, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.balances[key]
+	return b, ok, nil
+}
+
+// Put stores the balance for key
+func (s *MemoryStorage) Put(_ context.Context, key string, balance finance.Balance) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.balances[key] = balance
+	return nil
+}
+
+// Delete forgets the balance for key
+func (s *MemoryStorage) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.balances, key)
+	return nil
+}
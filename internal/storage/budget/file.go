@@ -0,0 +1,56 @@
+package budget
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"auto-finance/internal/models"
+	"auto-finance/internal/storage"
+	"auto-finance/internal/storage/jsonfile"
+)
+
+// FileStorage persists budget totals to a JSON file so that running totals
+// survive restarts without re-reading the sheet.
+type FileStorage struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFile creates a file backed budget storage at path
+func NewFile(path string) storage.BudgetStorage {
+	return &FileStorage{path: path}
+}
+
+// Add adds amount to the total for key
+func (s *FileStorage) Add(_ context.Context, key string, amount models.Money) (models.Money, models.Money, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	totals, err := s.load()
+	if err != nil {
+		return models.Money{}, models.Money{}, err
+	}
+
+	before, after, err := add(totals, key, amount)
+	if err != nil {
+		return models.Money{}, models.Money{}, err
+	}
+
+	return before, after, s.store(totals)
+}
+
+func (s *FileStorage) load() (map[string]models.Money, error) {
+	totals := make(map[string]models.Money)
+	if err := jsonfile.Load(s.path, &totals); err != nil {
+		return nil, fmt.Errorf("failed to load budget totals: %w", err)
+	}
+	return totals, nil
+}
+
+func (s *FileStorage) store(totals map[string]models.Money) error {
+	if err := jsonfile.Save(s.path, totals); err != nil {
+		return fmt.Errorf("failed to store budget totals: %w", err)
+	}
+	return nil
+}
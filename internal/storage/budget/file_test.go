@@ -0,0 +1,33 @@
+package budget_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"auto-finance/internal/models"
+	"auto-finance/internal/storage/budget"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStorage_PersistsAcrossInstances(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state", "budget.json")
+
+	first := budget.NewFile(path)
+	before, after, err := first.Add(ctx, "2025-11|dining", models.NewMoney(150000, "LKR"))
+	require.NoError(t, err)
+	assert.True(t, before.IsZero())
+	assert.Equal(t, models.NewMoney(150000, "LKR"), after)
+
+	second := budget.NewFile(path)
+	before, after, err = second.Add(ctx, "2025-11|dining", models.NewMoney(-50000, "LKR"))
+	require.NoError(t, err)
+	assert.Equal(t, models.NewMoney(150000, "LKR"), before)
+	assert.Equal(t, models.NewMoney(100000, "LKR"), after)
+
+	_, _, err = second.Add(ctx, "2025-11|dining", models.NewMoney(100, "USD"))
+	assert.ErrorIs(t, err, models.ErrCurrencyMismatch)
+}
@@ -0,0 +1,40 @@
+package budget
+
+import (
+	"context"
+	"sync"
+
+	"auto-finance/internal/models"
+	"auto-finance/internal/storage"
+)
+
+// MemoryStorage keeps budget totals in process memory. It is meant for tests
+// and single-process runs; totals are lost on restart.
+type MemoryStorage struct {
+	mu     sync.Mutex
+	totals map[string]models.Money
+}
+
+// NewMemory creates an in-memory budget storage
+func NewMemory() storage.BudgetStorage {
+	return &MemoryStorage{totals: make(map[string]models.Money)}
+}
+
+// Add adds amount to the total for key
+func (s *MemoryStorage) Add(_ context.Context, key string, amount models.Money) (models.Money, models.Money, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return add(s.totals, key, amount)
+}
+
+func add(totals map[string]models.Money, key string, amount models.Money) (models.Money, models.Money, error) {
+	before := totals[key]
+	after, err := before.Add(amount)
+	if err != nil {
+		return models.Money{}, models.Money{}, err
+	}
+
+	totals[key] = after
+	return before, after, nil
+}
@@ -0,0 +1,100 @@
+package deadletter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"auto-finance/internal/models"
+	"auto-finance/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// FileStorage keeps one JSON file per dead letter in a directory.
+type FileStorage struct {
+	dir string
+}
+
+// NewFile creates a dead letter storage rooted at dir
+func NewFile(dir string) storage.DeadLetterStorage {
+	return &FileStorage{dir: dir}
+}
+
+// Save writes letter to <dir>/<message id>.json
+func (s *FileStorage) Save(_ context.Context, letter *models.DeadLetter) error {
+	data, err := json.MarshalIndent(letter, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode dead letter: %w", err)
+	}
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create dead letter directory: %w", err)
+	}
+
+	path := s.path(letter.MessageID)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write dead letter %s: %w", tmp, err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace dead letter %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// List reads every dead letter in the directory, oldest failure first
+func (s *FileStorage) List(_ context.Context) ([]*models.DeadLetter, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead letter directory %s: %w", s.dir, err)
+	}
+
+	letters := make([]*models.DeadLetter, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dead letter %s: %w", entry.Name(), err)
+		}
+
+		var letter models.DeadLetter
+		if err := json.Unmarshal(data, &letter); err != nil {
+			return nil, fmt.Errorf("failed to decode dead letter %s: %w", entry.Name(), err)
+		}
+		letters = append(letters, &letter)
+	}
+
+	sort.SliceStable(letters, func(i, j int) bool {
+		return letters[i].FailedAt.Before(letters[j].FailedAt)
+	})
+
+	return letters, nil
+}
+
+// Delete removes the dead letter file for messageID
+func (s *FileStorage) Delete(_ context.Context, messageID uuid.UUID) error {
+	err := os.Remove(s.path(messageID))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete dead letter %s: %w", messageID, err)
+	}
+	return nil
+}
+
+func (s *FileStorage) path(messageID uuid.UUID) string {
+	return filepath.Join(s.dir, messageID.String()+".json")
+}
@@ -0,0 +1,183 @@
+package deadletter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"auto-finance/internal/errors"
+	"auto-finance/internal/models"
+	"auto-finance/internal/storage"
+	"auto-finance/internal/utils/retry"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/google/uuid"
+)
+
+const (
+	sqsMaxMessages = 10
+	// defaultVisibilityTimeout hides received letters from other consumers
+	// while a replay is working through them.
+	defaultVisibilityTimeout = 5 * time.Minute
+)
+
+// SQSClient defines the SQS operations used by this package
+type SQSClient interface {
+	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+	DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+}
+
+// SQSConfig contains configuration for SQS dead letter storage
+type SQSConfig struct {
+	Client            SQSClient
+	QueueURL          string
+	VisibilityTimeout time.Duration
+	RetryConfig       *retry.AWSRetryConfig
+}
+
+// SQSStorage stores dead letters as SQS messages. Entries returned by List
+// remember their receipt handles so that Delete and Save can remove them.
+type SQSStorage struct {
+	client            SQSClient
+	queueURL          string
+	visibilityTimeout time.Duration
+	retryConfig       retry.AWSRetryConfig
+
+	mu       sync.Mutex
+	receipts map[uuid.UUID]string
+}
+
+// NewSQS creates an SQS backed dead letter storage
+func NewSQS(config *SQSConfig) storage.DeadLetterStorage {
+	retryConfig := retry.DefaultAWSRetryConfig()
+	if config.RetryConfig != nil {
+		retryConfig = *config.RetryConfig
+	}
+
+	visibilityTimeout := config.VisibilityTimeout
+	if visibilityTimeout <= 0 {
+		visibilityTimeout = defaultVisibilityTimeout
+	}
+
+	return &SQSStorage{
+		client:            config.Client,
+		queueURL:          config.QueueURL,
+		visibilityTimeout: visibilityTimeout,
+		retryConfig:       retryConfig,
+		receipts:          make(map[uuid.UUID]string),
+	}
+}
+
+// Save sends letter to the queue. SQS messages are immutable, so updating an
+// entry that was previously listed sends a new message and deletes the old one.
+func (s *SQSStorage) Save(ctx context.Context, letter *models.DeadLetter) error {
+	data, err := json.Marshal(letter)
+	if err != nil {
+		return fmt.Errorf("failed to encode dead letter: %w", err)
+	}
+
+	operation := func() error {
+		_, err := s.client.SendMessage(ctx, &sqs.SendMessageInput{
+			QueueUrl:    aws.String(s.queueURL),
+			MessageBody: aws.String(string(data)),
+		})
+		return s.wrap("send dead letter", err)
+	}
+
+	if err := retry.WithAWSRetry(ctx, s.retryConfig, operation); err != nil {
+		return err
+	}
+
+	return s.Delete(ctx, letter.MessageID)
+}
+
+// List receives every visible message from the queue, oldest failure first
+func (s *SQSStorage) List(ctx context.Context) ([]*models.DeadLetter, error) {
+	var letters []*models.DeadLetter
+
+	for {
+		var out *sqs.ReceiveMessageOutput
+		operation := func() error {
+			var err error
+			out, err = s.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+				QueueUrl:            aws.String(s.queueURL),
+				MaxNumberOfMessages: sqsMaxMessages,
+				VisibilityTimeout:   int32(s.visibilityTimeout.Seconds()),
+			})
+			return s.wrap("receive dead letters", err)
+		}
+
+		if err := retry.WithAWSRetry(ctx, s.retryConfig, operation); err != nil {
+			return nil, err
+		}
+
+		if len(out.Messages) == 0 {
+			break
+		}
+
+		for _, msg := range out.Messages {
+			var letter models.DeadLetter
+			if err := json.Unmarshal([]byte(aws.ToString(msg.Body)), &letter); err != nil {
+				return nil, fmt.Errorf("failed to decode dead letter %s: %w", aws.ToString(msg.MessageId), err)
+			}
+
+			s.mu.Lock()
+			s.receipts[letter.MessageID] = aws.ToString(msg.ReceiptHandle)
+			s.mu.Unlock()
+
+			letters = append(letters, &letter)
+		}
+	}
+
+	sort.SliceStable(letters, func(i, j int) bool {
+		return letters[i].FailedAt.Before(letters[j].FailedAt)
+	})
+
+	return letters, nil
+}
+
+// Delete removes a previously listed entry. Entries that were not received
+// through List are left alone.
+func (s *SQSStorage) Delete(ctx context.Context, messageID uuid.UUID) error {
+	s.mu.Lock()
+	receipt, ok := s.receipts[messageID]
+	s.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	operation := func() error {
+		_, err := s.client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+			QueueUrl:      aws.String(s.queueURL),
+			ReceiptHandle: aws.String(receipt),
+		})
+		return s.wrap("delete dead letter", err)
+	}
+
+	if err := retry.WithAWSRetry(ctx, s.retryConfig, operation); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.receipts, messageID)
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *SQSStorage) wrap(action string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	wrapped := fmt.Errorf("failed to %s on %s: %w", action, s.queueURL, err)
+	if retry.IsAWSErrorRetryable(err) {
+		return errors.NewRetryableError(wrapped, errors.ErrorTypeAWS, 2*time.Second, 3)
+	}
+	return wrapped
+}
@@ -0,0 +1,84 @@
+package deadletter_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"auto-finance/internal/models"
+	"auto-finance/internal/storage/deadletter"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeQueue hands out every message once per receive cycle, like a queue
+// whose visibility timeout has not expired yet.
+type fakeQueue struct {
+	messages map[string]string
+	received map[string]bool
+	next     int
+}
+
+func newFakeQueue() *fakeQueue {
+	return &fakeQueue{messages: map[string]string{}, received: map[string]bool{}}
+}
+
+func (q *fakeQueue) SendMessage(_ context.Context, in *sqs.SendMessageInput, _ ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	q.next++
+	handle := strconv.Itoa(q.next)
+	q.messages[handle] = aws.ToString(in.MessageBody)
+	return &sqs.SendMessageOutput{MessageId: aws.String(handle)}, nil
+}
+
+func (q *fakeQueue) ReceiveMessage(_ context.Context, in *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	out := &sqs.ReceiveMessageOutput{}
+	for handle, body := range q.messages {
+		if q.received[handle] || int32(len(out.Messages)) >= in.MaxNumberOfMessages {
+			continue
+		}
+		q.received[handle] = true
+		out.Messages = append(out.Messages, types.Message{
+			MessageId:     aws.String(handle),
+			ReceiptHandle: aws.String(handle),
+			Body:          aws.String(body),
+		})
+	}
+	return out, nil
+}
+
+func (q *fakeQueue) DeleteMessage(_ context.Context, in *sqs.DeleteMessageInput, _ ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
+	delete(q.messages, aws.ToString(in.ReceiptHandle))
+	return &sqs.DeleteMessageOutput{}, nil
+}
+
+func TestSQSStorage_SaveReplacesListedEntry(t *testing.T) {
+	ctx := context.Background()
+	queue := newFakeQueue()
+	store := deadletter.NewSQS(&deadletter.SQSConfig{Client: queue, QueueURL: "queue"})
+
+	failedAt := time.Date(2025, time.November, 7, 18, 42, 10, 0, time.UTC)
+	first := &models.DeadLetter{MessageID: uuid.New(), Body: "first", Attempts: 1, FailedAt: failedAt}
+	second := &models.DeadLetter{MessageID: uuid.New(), Body: "second", Attempts: 1, FailedAt: failedAt.Add(time.Minute)}
+	require.NoError(t, store.Save(ctx, second))
+	require.NoError(t, store.Save(ctx, first))
+
+	letters, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, letters, 2)
+	assert.Equal(t, "first", letters[0].Body)
+
+	letters[0].Attempts = 2
+	require.NoError(t, store.Save(ctx, letters[0]))
+	require.NoError(t, store.Delete(ctx, second.MessageID))
+
+	require.Len(t, queue.messages, 1)
+	for _, body := range queue.messages {
+		assert.Contains(t, body, `"attempts":2`)
+	}
+}
@@ -2,76 +2,142 @@ package ebill
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"auto-finance/internal/errors"
+	"auto-finance/internal/models"
 	"auto-finance/internal/models/ebill"
 	"auto-finance/internal/utils/retry"
 
 	"auto-finance/internal/storage"
+	"auto-finance/internal/storage/sheetcell"
 
 	"google.golang.org/api/sheets/v4"
 )
 
-// LECOStorage provides LECO bill storage with retry capabilities
+// Columns of the bill sheet, in order. Save and parseRow both use them, so
+// new columns are added before columnCount and nowhere else.
+const (
+	colAccountNumber = iota
+	colAccountType
+	colAccountName
+	colReadOn
+	colImportPrevious
+	colImportCurrent
+	colImportUnits
+	colExportPrevious
+	colExportCurrent
+	colExportUnits
+	colNetUnits
+	colNetUnitsType
+	colMonthlyBill
+	colOtherCharges
+	colSSCL
+	colOpeningBalance
+	colOpeningBalanceDate
+	colTotalPayable
+	colLastPaymentAmount
+	colLastPaymentDate
+	colLastGenPayment
+	colDueDate
+	colAdjustments
+	colGenerationUnits
+	colGenerationAmount
+	colExtras
+	colProvider
+	columnCount
+)
+
+// LECOStorage provides electricity bill storage with retry capabilities.
+// Despite the name it stores any provider's bills; CEB bills use their own
+// sheet with the same layout.
 type LECOStorage struct {
 	service           *sheets.Service
 	sheetID           string
 	sheetName         string
+	provider          string
 	googleRetryConfig retry.GoogleRetryConfig
 }
 
 // Config contains configuration for enhanced LECO bill storage
 type Config struct {
-	Service           *sheets.Service
-	SheetID           string
-	SheetName         string
+	Service   *sheets.Service
+	SheetID   string
+	SheetName string
+	// Provider is assumed for rows saved before the provider column was
+	// added, ebill.ProviderLECO when empty.
+	Provider          string
 	GoogleRetryConfig *retry.GoogleRetryConfig
 }
 
 // New creates a new enhanced LECO bill storage with retry capabilities
-func New(config *Config) storage.MessageStorage[*ebill.ElectricityBill] {
+func New(config *Config) storage.BillLedger {
 	retryConfig := retry.DefaultGoogleRetryConfig()
 	if config.GoogleRetryConfig != nil {
 		retryConfig = *config.GoogleRetryConfig
 	}
+	provider := config.Provider
+	if provider == "" {
+		provider = ebill.ProviderLECO
+	}
 
 	return &LECOStorage{
 		service:           config.Service,
 		sheetID:           config.SheetID,
 		sheetName:         config.SheetName,
+		provider:          provider,
 		googleRetryConfig: retryConfig,
 	}
 }
 
 // Save saves an electricity bill to Google Sheets with retry logic
+// Unrecognized bill lines are written as a JSON object, followed by the
+// provider. Amounts the bill did not print are left empty.
 func (s *LECOStorage) Save(ctx context.Context, bill *ebill.ElectricityBill) error {
+	extras := ""
+	if len(bill.Extras) > 0 {
+		data, err := json.Marshal(bill.Extras)
+		if err != nil {
+			return fmt.Errorf("failed to encode bill extras: %w", err)
+		}
+		extras = string(data)
+	}
+
+	row := make([]interface{}, columnCount)
+	row[colAccountNumber] = bill.AccountNumber
+	row[colAccountType] = bill.AccountType
+	row[colAccountName] = bill.AccountName
+	row[colReadOn] = bill.ReadOn
+	row[colImportPrevious] = bill.ImportPrevious
+	row[colImportCurrent] = bill.ImportCurrent
+	row[colImportUnits] = bill.ImportUnits
+	row[colExportPrevious] = bill.ExportPrevious
+	row[colExportCurrent] = bill.ExportCurrent
+	row[colExportUnits] = bill.ExportUnits
+	row[colNetUnits] = bill.NetUnits
+	row[colNetUnitsType] = bill.NetUnitsType
+	row[colMonthlyBill] = sheetcell.OptionalAmount(bill.MonthlyBill)
+	row[colOtherCharges] = sheetcell.OptionalAmount(bill.OtherCharges)
+	row[colSSCL] = sheetcell.OptionalAmount(bill.SSCL)
+	row[colOpeningBalance] = sheetcell.OptionalAmount(bill.OpeningBalance)
+	row[colOpeningBalanceDate] = bill.OpeningBalanceDate
+	row[colTotalPayable] = sheetcell.OptionalAmount(bill.TotalPayable)
+	row[colLastPaymentAmount] = sheetcell.OptionalAmount(bill.LastPaymentAmount)
+	row[colLastPaymentDate] = bill.LastPaymentDate
+	row[colLastGenPayment] = sheetcell.OptionalAmount(bill.LastGenPayment)
+	row[colDueDate] = bill.DueDate
+	row[colAdjustments] = sheetcell.OptionalAmount(bill.Adjustments)
+	row[colGenerationUnits] = bill.GenerationUnits
+	row[colGenerationAmount] = sheetcell.OptionalAmount(bill.GenerationAmount)
+	row[colExtras] = extras
+	row[colProvider] = bill.Provider
+
 	operation := func() error {
-		var vr sheets.ValueRange
-		vr.Values = append(vr.Values, []interface{}{
-			bill.AccountNumber,
-			bill.AccountType,
-			bill.AccountName,
-			bill.ReadOn,
-			bill.ImportPrevious,
-			bill.ImportCurrent,
-			bill.ImportUnits,
-			bill.ExportPrevious,
-			bill.ExportCurrent,
-			bill.ExportUnits,
-			bill.NetUnits,
-			bill.NetUnitsType,
-			bill.MonthlyBill,
-			bill.OtherCharges,
-			bill.SSCL,
-			bill.OpeningBalance,
-			bill.OpeningBalanceDate,
-			bill.TotalPayable,
-			bill.LastPaymentAmount,
-			bill.LastPaymentDate,
-			bill.LastGenPayment,
-		})
+		vr := sheets.ValueRange{Values: [][]interface{}{row}}
 
 		_, err := s.service.Spreadsheets.Values.Append(
 			s.sheetID,
@@ -91,3 +157,100 @@ func (s *LECOStorage) Save(ctx context.Context, bill *ebill.ElectricityBill) err
 
 	return retry.WithGoogleRetry(ctx, s.googleRetryConfig, operation)
 }
+
+// List reads back every bill saved by Save. Rows that cannot be read, such
+// as a header, are skipped.
+func (s *LECOStorage) List(ctx context.Context) ([]*ebill.ElectricityBill, error) {
+	var rows [][]interface{}
+	operation := func() error {
+		resp, err := s.service.Spreadsheets.Values.Get(
+			s.sheetID,
+			sheetcell.QuoteSheetName(s.sheetName)+"!A:"+sheetcell.ColumnLetter(columnCount-1),
+		).Context(ctx).Do()
+		if err != nil {
+			return errors.NewRetryableError(
+				fmt.Errorf("failed to read electricity bills from sheet: %w", err),
+				errors.ErrorTypeGoogle,
+				2*time.Second,
+				3,
+			)
+		}
+		rows = resp.Values
+		return nil
+	}
+
+	if err := retry.WithGoogleRetry(ctx, s.googleRetryConfig, operation); err != nil {
+		return nil, err
+	}
+
+	var bills []*ebill.ElectricityBill
+	for _, row := range rows {
+		if bill, ok := parseRow(row, s.provider); ok {
+			bills = append(bills, bill)
+		}
+	}
+	return bills, nil
+}
+
+// parseRow reads back a row written by Save. Amounts are in LKR, the only
+// currency bills are issued in. Rows without a provider get provider.
+func parseRow(row []interface{}, provider string) (*ebill.ElectricityBill, bool) {
+	cell := func(i int) string { return sheetcell.String(row, i) }
+	number := func(i int) int {
+		n, _ := strconv.Atoi(strings.ReplaceAll(cell(i), ",", ""))
+		return n
+	}
+	// Empty cells are amounts the bill did not print and stay unset.
+	amount := func(i int) models.Money {
+		if cell(i) == "" {
+			return models.Money{}
+		}
+		m, _ := models.ParseMoney(strings.ReplaceAll(cell(i), ",", ""), "LKR")
+		return m
+	}
+	date := func(i int) time.Time {
+		t, _ := sheetcell.ParseTime(cell(i), time.UTC)
+		return t
+	}
+
+	readOn, ok := sheetcell.ParseTime(cell(colReadOn), time.UTC)
+	if cell(colAccountNumber) == "" || !ok || readOn.IsZero() {
+		return nil, false
+	}
+
+	bill := &ebill.ElectricityBill{
+		Provider:           cell(colProvider),
+		AccountNumber:      cell(colAccountNumber),
+		AccountType:        cell(colAccountType),
+		AccountName:        cell(colAccountName),
+		ReadOn:             readOn,
+		ImportPrevious:     number(colImportPrevious),
+		ImportCurrent:      number(colImportCurrent),
+		ImportUnits:        number(colImportUnits),
+		ExportPrevious:     number(colExportPrevious),
+		ExportCurrent:      number(colExportCurrent),
+		ExportUnits:        number(colExportUnits),
+		NetUnits:           number(colNetUnits),
+		NetUnitsType:       cell(colNetUnitsType),
+		MonthlyBill:        amount(colMonthlyBill),
+		OtherCharges:       amount(colOtherCharges),
+		SSCL:               amount(colSSCL),
+		OpeningBalance:     amount(colOpeningBalance),
+		OpeningBalanceDate: date(colOpeningBalanceDate),
+		TotalPayable:       amount(colTotalPayable),
+		LastPaymentAmount:  amount(colLastPaymentAmount),
+		LastPaymentDate:    date(colLastPaymentDate),
+		LastGenPayment:     amount(colLastGenPayment),
+		DueDate:            date(colDueDate),
+		Adjustments:        amount(colAdjustments),
+		GenerationUnits:    number(colGenerationUnits),
+		GenerationAmount:   amount(colGenerationAmount),
+	}
+	if raw := cell(colExtras); raw != "" {
+		_ = json.Unmarshal([]byte(raw), &bill.Extras)
+	}
+	if bill.Provider == "" {
+		bill.Provider = provider
+	}
+	return bill, true
+}
@@ -0,0 +1,133 @@
+package ebill_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/ebill"
+	"auto-finance/internal/storage"
+	ebillStorage "auto-finance/internal/storage/ebill"
+	"auto-finance/internal/utils/retry"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+// fakeSheet is a Sheets API values endpoint that returns the rows appended
+// to it.
+type fakeSheet struct {
+	mu   sync.Mutex
+	rows [][]interface{}
+}
+
+func (f *fakeSheet) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, ":append") {
+		var vr sheets.ValueRange
+		if err := json.NewDecoder(r.Body).Decode(&vr); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.rows = append(f.rows, vr.Values...)
+		_ = json.NewEncoder(w).Encode(sheets.AppendValuesResponse{})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(sheets.ValueRange{Values: f.rows})
+}
+
+func newLECOStorage(t *testing.T) storage.BillLedger {
+	t.Helper()
+	srv := httptest.NewServer(&fakeSheet{})
+	t.Cleanup(srv.Close)
+
+	service, err := sheets.NewService(context.Background(), option.WithEndpoint(srv.URL), option.WithoutAuthentication())
+	require.NoError(t, err)
+
+	return ebillStorage.New(&ebillStorage.Config{
+		Service:           service,
+		SheetID:           "sheet",
+		SheetName:         "LECO Bills",
+		GoogleRetryConfig: &retry.GoogleRetryConfig{MaxAttempts: 1},
+	})
+}
+
+func TestLECOStorage_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := newLECOStorage(t)
+
+	day := func(d int) time.Time { return time.Date(2025, time.October, d, 0, 0, 0, 0, time.UTC) }
+	lkr := func(minor int64) models.Money { return models.NewMoney(minor, "LKR") }
+	// Every field has a distinct value so that a shifted column shows up.
+	bill := &ebill.ElectricityBill{
+		Provider:           ebill.ProviderCEB,
+		AccountNumber:      "0102881677",
+		AccountType:        "DOMESTIC-01",
+		AccountName:        "MASKED A B",
+		ReadOn:             day(5),
+		ImportPrevious:     7980,
+		ImportCurrent:      8120,
+		ImportUnits:        140,
+		ExportPrevious:     29575,
+		ExportCurrent:      30200,
+		ExportUnits:        625,
+		NetUnits:           485,
+		NetUnitsType:       "Exp",
+		MonthlyBill:        lkr(399000),
+		OtherCharges:       lkr(1100),
+		SSCL:               lkr(10231),
+		OpeningBalance:     lkr(-50000),
+		OpeningBalanceDate: day(1),
+		TotalPayable:       lkr(409231),
+		LastPaymentAmount:  lkr(385000),
+		LastPaymentDate:    day(2),
+		LastGenPayment:     lkr(980000),
+		DueDate:            day(25),
+		Adjustments:        lkr(-1200),
+		GenerationUnits:    430,
+		GenerationAmount:   lkr(1163580),
+		Extras:             map[string]string{"Meter No": "12:34-X"},
+	}
+
+	require.NoError(t, store.Save(ctx, bill))
+
+	got, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, bill, got[0])
+}
+
+func TestLECOStorage_RoundTrip_AbsentAmounts(t *testing.T) {
+	ctx := context.Background()
+	store := newLECOStorage(t)
+
+	// An older bill format that prints neither this month's generation
+	// amount nor most charges, only the last generation payment.
+	bill := &ebill.ElectricityBill{
+		Provider:       ebill.ProviderLECO,
+		AccountNumber:  "0102881677",
+		ReadOn:         time.Date(2025, time.October, 5, 0, 0, 0, 0, time.UTC),
+		ExportUnits:    625,
+		MonthlyBill:    models.NewMoney(0, "LKR"),
+		LastGenPayment: models.NewMoney(500000, "LKR"),
+	}
+
+	require.NoError(t, store.Save(ctx, bill))
+
+	got, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, bill, got[0])
+	assert.True(t, got[0].GenerationAmount.IsZero())
+	assert.False(t, got[0].MonthlyBill.IsZero(), "a printed zero stays set")
+}
@@ -0,0 +1,54 @@
+package ebill
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"auto-finance/internal/models/ebill"
+	"auto-finance/internal/storage"
+)
+
+// Router saves each bill to the storage of its provider and lists the bills
+// of every provider.
+type Router struct {
+	ledgers   map[string]storage.BillLedger
+	providers []string
+}
+
+// NewRouter routes by ebill.ElectricityBill.Provider. Bills without a
+// provider are treated as LECO bills.
+func NewRouter(ledgers map[string]storage.BillLedger) *Router {
+	providers := make([]string, 0, len(ledgers))
+	for provider := range ledgers {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+
+	return &Router{ledgers: ledgers, providers: providers}
+}
+
+func (r *Router) Save(ctx context.Context, bill *ebill.ElectricityBill) error {
+	provider := bill.Provider
+	if provider == "" {
+		provider = ebill.ProviderLECO
+	}
+	ledger, ok := r.ledgers[provider]
+	if !ok {
+		return fmt.Errorf("no sheet configured for %s bills", provider)
+	}
+	return ledger.Save(ctx, bill)
+}
+
+// List returns the bills of each provider in turn, providers in name order.
+func (r *Router) List(ctx context.Context) ([]*ebill.ElectricityBill, error) {
+	var bills []*ebill.ElectricityBill
+	for _, provider := range r.providers {
+		list, err := r.ledgers[provider].List(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%s bills: %w", provider, err)
+		}
+		bills = append(bills, list...)
+	}
+	return bills, nil
+}
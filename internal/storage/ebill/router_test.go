@@ -0,0 +1,52 @@
+package ebill_test
+
+import (
+	"context"
+	"testing"
+
+	"auto-finance/internal/models/ebill"
+	"auto-finance/internal/storage"
+	ebillStorage "auto-finance/internal/storage/ebill"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ledger struct {
+	bills []*ebill.ElectricityBill
+}
+
+func (l *ledger) Save(_ context.Context, b *ebill.ElectricityBill) error {
+	l.bills = append(l.bills, b)
+	return nil
+}
+
+func (l *ledger) List(context.Context) ([]*ebill.ElectricityBill, error) {
+	return l.bills, nil
+}
+
+func TestRouter(t *testing.T) {
+	ctx := context.Background()
+	leco, ceb := &ledger{}, &ledger{}
+	router := ebillStorage.NewRouter(map[string]storage.BillLedger{
+		ebill.ProviderLECO: leco,
+		ebill.ProviderCEB:  ceb,
+	})
+
+	cebBill := &ebill.ElectricityBill{Provider: ebill.ProviderCEB, AccountNumber: "4512345678"}
+	lecoBill := &ebill.ElectricityBill{Provider: ebill.ProviderLECO, AccountNumber: "0102881677"}
+	legacyBill := &ebill.ElectricityBill{AccountNumber: "0102881678"}
+
+	for _, b := range []*ebill.ElectricityBill{cebBill, lecoBill, legacyBill} {
+		require.NoError(t, router.Save(ctx, b))
+	}
+	assert.Equal(t, []*ebill.ElectricityBill{cebBill}, ceb.bills)
+	assert.Equal(t, []*ebill.ElectricityBill{lecoBill, legacyBill}, leco.bills, "bills without a provider go to LECO")
+
+	all, err := router.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*ebill.ElectricityBill{cebBill, lecoBill, legacyBill}, all)
+
+	onlyLECO := ebillStorage.NewRouter(map[string]storage.BillLedger{ebill.ProviderLECO: &ledger{}})
+	assert.ErrorContains(t, onlyLECO.Save(ctx, cebBill), "no sheet configured for CEB bills")
+}
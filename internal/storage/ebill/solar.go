@@ -0,0 +1,125 @@
+package ebill
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"auto-finance/internal/errors"
+	"auto-finance/internal/models/ebill"
+	"auto-finance/internal/storage"
+	"auto-finance/internal/storage/sheetcell"
+	"auto-finance/internal/utils/retry"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// solarHeader names the summary columns written by SolarStorage.Replace.
+var solarHeader = []interface{}{
+	"Account", "Read On", "Days", "Import Units", "Export Units",
+	"Estimated Generation", "Self Consumption", "Self Consumption %", "Export %",
+	"Generation Income", "Import Cost", "Net", "YTD Income", "YTD Import Cost",
+	"Cumulative Income", "Payback %", "Export Drop",
+}
+
+// SolarStorage rewrites a sheet tab with the solar summary
+type SolarStorage struct {
+	service           *sheets.Service
+	sheetID           string
+	sheetName         string
+	googleRetryConfig retry.GoogleRetryConfig
+}
+
+// SolarConfig contains configuration for solar summary storage
+type SolarConfig struct {
+	Service           *sheets.Service
+	SheetID           string
+	SheetName         string
+	GoogleRetryConfig *retry.GoogleRetryConfig
+}
+
+// NewSolarStorage creates a new solar summary storage with retry capabilities
+func NewSolarStorage(config *SolarConfig) storage.SolarSummaryStorage {
+	retryConfig := retry.DefaultGoogleRetryConfig()
+	if config.GoogleRetryConfig != nil {
+		retryConfig = *config.GoogleRetryConfig
+	}
+
+	return &SolarStorage{
+		service:           config.Service,
+		sheetID:           config.SheetID,
+		sheetName:         config.SheetName,
+		googleRetryConfig: retryConfig,
+	}
+}
+
+// Replace clears the tab and writes a header row followed by one row per
+// month. Ratios are written as percentages.
+func (s *SolarStorage) Replace(ctx context.Context, months []*ebill.SolarMonth) error {
+	values := [][]interface{}{solarHeader}
+	for _, m := range months {
+		values = append(values, []interface{}{
+			m.AccountNumber,
+			m.ReadOn.Format(time.DateOnly),
+			m.Days,
+			m.ImportUnits,
+			m.ExportUnits,
+			m.EstimatedGeneration,
+			m.SelfConsumption,
+			percentCell(m.SelfConsumptionRatio, m.EstimatedGeneration > 0),
+			percentCell(m.ExportRatio, m.EstimatedGeneration > 0),
+			m.GenerationIncome.Decimal(),
+			m.ImportCost.Decimal(),
+			m.Net.Decimal(),
+			m.YearToDateIncome.Decimal(),
+			m.YearToDateImportCost.Decimal(),
+			m.CumulativeIncome.Decimal(),
+			percentCell(m.PaybackProgress, true),
+			m.ExportDrop,
+		})
+	}
+
+	sheetRange := sheetcell.QuoteSheetName(s.sheetName)
+	operation := func() error {
+		_, err := s.service.Spreadsheets.Values.Clear(
+			s.sheetID,
+			sheetRange,
+			&sheets.ClearValuesRequest{},
+		).Context(ctx).Do()
+		if err != nil {
+			return errors.NewRetryableError(
+				fmt.Errorf("failed to clear solar summary sheet: %w", err),
+				errors.ErrorTypeGoogle,
+				2*time.Second,
+				3,
+			)
+		}
+
+		_, err = s.service.Spreadsheets.Values.Update(
+			s.sheetID,
+			sheetRange+"!A1",
+			&sheets.ValueRange{Values: values},
+		).ValueInputOption("USER_ENTERED").Context(ctx).Do()
+		if err != nil {
+			return errors.NewRetryableError(
+				fmt.Errorf("failed to write solar summary to sheet: %w", err),
+				errors.ErrorTypeGoogle,
+				2*time.Second,
+				3,
+			)
+		}
+		return nil
+	}
+
+	return retry.WithGoogleRetry(ctx, s.googleRetryConfig, operation)
+}
+
+// percentCell leaves the cell empty for a ratio that could not be worked
+// out.
+func percentCell(ratio float64, known bool) string {
+	if !known {
+		return ""
+	}
+	return strconv.FormatFloat(ratio*100, 'f', 1, 64)
+}
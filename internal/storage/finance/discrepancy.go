@@ -0,0 +1,164 @@
+package finance
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"auto-finance/internal/errors"
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/storage"
+	"auto-finance/internal/storage/sheetcell"
+	"auto-finance/internal/utils/retry"
+
+	"github.com/google/uuid"
+	"google.golang.org/api/sheets/v4"
+)
+
+// DiscrepancyStorage appends balance discrepancies to their own sheet tab
+type DiscrepancyStorage struct {
+	service           *sheets.Service
+	sheet             Sheet
+	location          *time.Location
+	googleRetryConfig retry.GoogleRetryConfig
+}
+
+// DiscrepancyConfig contains configuration for discrepancy storage
+type DiscrepancyConfig struct {
+	Service *sheets.Service
+	Sheet   Sheet
+	// Location is the zone of the stored times, UTC when nil.
+	Location          *time.Location
+	GoogleRetryConfig *retry.GoogleRetryConfig
+}
+
+// NewDiscrepancyStorage creates a new discrepancy storage with retry capabilities
+func NewDiscrepancyStorage(config *DiscrepancyConfig) storage.DiscrepancyStorage {
+	retryConfig := retry.DefaultGoogleRetryConfig()
+	if config.GoogleRetryConfig != nil {
+		retryConfig = *config.GoogleRetryConfig
+	}
+	location := config.Location
+	if location == nil {
+		location = time.UTC
+	}
+
+	return &DiscrepancyStorage{
+		service:           config.Service,
+		sheet:             config.Sheet,
+		location:          location,
+		googleRetryConfig: retryConfig,
+	}
+}
+
+// Save appends the discrepancy with retry logic. Columns: time, institution,
+// account, expected, reported, difference, currency, checkpoint time and
+// source message ID.
+func (s *DiscrepancyStorage) Save(ctx context.Context, d *finance.Discrepancy) error {
+	sourceID := ""
+	if d.SourceMessageID != uuid.Nil {
+		sourceID = d.SourceMessageID.String()
+	}
+
+	operation := func() error {
+		var vr sheets.ValueRange
+		vr.Values = append(vr.Values, []interface{}{
+			d.OccurredAt.In(s.location).Format(time.DateTime),
+			d.Institution,
+			d.Account,
+			d.Expected.Decimal(),
+			d.Reported.Decimal(),
+			d.Difference.Decimal(),
+			d.Reported.Currency,
+			d.CheckpointAt.In(s.location).Format(time.DateTime),
+			sourceID,
+		})
+
+		_, err := s.service.Spreadsheets.Values.Append(
+			s.sheet.SheetID,
+			s.sheet.SheetName,
+			&vr,
+		).ValueInputOption("USER_ENTERED").InsertDataOption("INSERT_ROWS").Context(ctx).Do()
+		if err != nil {
+			return errors.NewRetryableError(
+				fmt.Errorf("failed to append discrepancy to sheet: %w", err),
+				errors.ErrorTypeGoogle,
+				2*time.Second,
+				3,
+			)
+		}
+		return nil
+	}
+
+	return retry.WithGoogleRetry(ctx, s.googleRetryConfig, operation)
+}
+
+// List reads back every discrepancy in the sheet, skipping rows that cannot
+// be read such as a header.
+func (s *DiscrepancyStorage) List(ctx context.Context) ([]*finance.Discrepancy, error) {
+	var rows [][]interface{}
+	operation := func() error {
+		resp, err := s.service.Spreadsheets.Values.Get(
+			s.sheet.SheetID,
+			sheetcell.QuoteSheetName(s.sheet.SheetName)+"!A:I",
+		).Context(ctx).Do()
+		if err != nil {
+			return errors.NewRetryableError(
+				fmt.Errorf("failed to read discrepancies from sheet: %w", err),
+				errors.ErrorTypeGoogle,
+				2*time.Second,
+				3,
+			)
+		}
+		rows = resp.Values
+		return nil
+	}
+
+	if err := retry.WithGoogleRetry(ctx, s.googleRetryConfig, operation); err != nil {
+		return nil, err
+	}
+
+	var discrepancies []*finance.Discrepancy
+	for _, row := range rows {
+		if d, ok := s.parseRow(row); ok {
+			discrepancies = append(discrepancies, d)
+		}
+	}
+	return discrepancies, nil
+}
+
+func (s *DiscrepancyStorage) parseRow(row []interface{}) (*finance.Discrepancy, bool) {
+	cell := func(i int) string { return sheetcell.String(row, i) }
+
+	occurredAt, ok := sheetcell.ParseTime(cell(0), s.location)
+	if !ok {
+		return nil, false
+	}
+
+	currency := cell(6)
+	amounts := make([]models.Money, 3)
+	for i := range amounts {
+		m, err := models.ParseMoney(cell(3+i), currency)
+		if err != nil {
+			return nil, false
+		}
+		amounts[i] = m
+	}
+
+	d := &finance.Discrepancy{
+		Institution: cell(1),
+		Account:     cell(2),
+		Expected:    amounts[0],
+		Reported:    amounts[1],
+		Difference:  amounts[2],
+		OccurredAt:  occurredAt,
+	}
+	if checkpointAt, ok := sheetcell.ParseTime(cell(7), s.location); ok {
+		d.CheckpointAt = checkpointAt
+	}
+	if id, err := uuid.Parse(cell(8)); err == nil {
+		d.SourceMessageID = id
+	}
+	return d, true
+}
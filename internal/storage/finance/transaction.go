@@ -0,0 +1,312 @@
+package finance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"auto-finance/internal/errors"
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/utils/retry"
+
+	"auto-finance/internal/storage"
+	"auto-finance/internal/storage/sheetcell"
+
+	"github.com/google/uuid"
+	"google.golang.org/api/sheets/v4"
+)
+
+// Columns of the transaction sheets, in order. The first ten keep the layout
+// of the former per-bank sheets. Save, parseRow and UpdateStatus all use
+// them, so new columns are added before columnCount and nowhere else.
+const (
+	colOccurredAt = iota
+	colAmount
+	colCurrency
+	colStatus
+	colChannel
+	colAccount
+	colCounterparty
+	colBalanceAfter
+	colBalanceCurrency
+	colReference
+	colDirection
+	colInstitution
+	colSourceMessageID
+	colExtensions
+	colMerchant
+	colCategory
+	colConverted
+	colConvertedCurrency
+	colFXRate
+	columnCount
+)
+
+// Sheet identifies the spreadsheet tab an institution's transactions go to
+type Sheet struct {
+	SheetID   string
+	SheetName string
+}
+
+// TransactionStorage stores transactions from every bank, one sheet per
+// institution, with retry capabilities
+type TransactionStorage struct {
+	service           *sheets.Service
+	sheets            map[string]Sheet
+	declinedSheet     *Sheet
+	location          *time.Location
+	googleRetryConfig retry.GoogleRetryConfig
+}
+
+// TransactionConfig contains configuration for transaction storage
+type TransactionConfig struct {
+	Service *sheets.Service
+	// Sheets maps an institution (finance.InstitutionSampath, ...) to its sheet
+	Sheets map[string]Sheet
+	// DeclinedSheet, when set, receives declined transactions from every
+	// institution so that they stay out of the spending sheets.
+	DeclinedSheet *Sheet
+	// Location is the zone of the stored times, UTC when nil.
+	Location          *time.Location
+	GoogleRetryConfig *retry.GoogleRetryConfig
+}
+
+// NewTransactionStorage creates a new transaction storage with retry capabilities
+func NewTransactionStorage(config *TransactionConfig) storage.TransactionLedger {
+	retryConfig := retry.DefaultGoogleRetryConfig()
+	if config.GoogleRetryConfig != nil {
+		retryConfig = *config.GoogleRetryConfig
+	}
+	location := config.Location
+	if location == nil {
+		location = time.UTC
+	}
+
+	return &TransactionStorage{
+		service:           config.Service,
+		sheets:            config.Sheets,
+		declinedSheet:     config.DeclinedSheet,
+		location:          location,
+		googleRetryConfig: retryConfig,
+	}
+}
+
+// Save appends the transaction to its institution's sheet with retry logic.
+func (s *TransactionStorage) Save(ctx context.Context, txn *finance.Transaction) error {
+	sheet, ok := s.sheets[txn.Institution]
+	if !ok {
+		return fmt.Errorf("no sheet configured for institution %q", txn.Institution)
+	}
+	if txn.Status == finance.StatusDeclined && s.declinedSheet != nil {
+		sheet = *s.declinedSheet
+	}
+
+	extensions := ""
+	if len(txn.Extensions) > 0 {
+		data, err := json.Marshal(txn.Extensions)
+		if err != nil {
+			return fmt.Errorf("failed to encode transaction extensions: %w", err)
+		}
+		extensions = string(data)
+	}
+
+	sourceID := ""
+	if txn.SourceMessageID != uuid.Nil {
+		sourceID = txn.SourceMessageID.String()
+	}
+
+	row := make([]interface{}, columnCount)
+	row[colOccurredAt] = txn.OccurredAt.In(s.location).Format(time.DateTime)
+	row[colAmount] = txn.Amount.Decimal()
+	row[colCurrency] = txn.Amount.Currency
+	row[colStatus] = txn.Status
+	row[colChannel] = txn.Channel
+	row[colAccount] = txn.Account
+	row[colCounterparty] = txn.Counterparty
+	row[colBalanceAfter] = sheetcell.OptionalAmount(txn.BalanceAfter)
+	row[colBalanceCurrency] = txn.BalanceAfter.Currency
+	row[colReference] = txn.Extensions[finance.ExtensionReference]
+	row[colDirection] = txn.Direction
+	row[colInstitution] = txn.Institution
+	row[colSourceMessageID] = sourceID
+	row[colExtensions] = extensions
+	row[colMerchant] = txn.Merchant
+	row[colCategory] = txn.Category
+	row[colConverted] = sheetcell.OptionalAmount(txn.Converted)
+	row[colConvertedCurrency] = txn.Converted.Currency
+	row[colFXRate] = txn.FXRate
+
+	operation := func() error {
+		vr := sheets.ValueRange{Values: [][]interface{}{row}}
+
+		_, err := s.service.Spreadsheets.Values.Append(
+			sheet.SheetID,
+			sheet.SheetName,
+			&vr,
+		).ValueInputOption("USER_ENTERED").InsertDataOption("INSERT_ROWS").Context(ctx).Do()
+		if err != nil {
+			return errors.NewRetryableError(
+				fmt.Errorf("failed to append %s transaction to sheet: %w", txn.Institution, err),
+				errors.ErrorTypeGoogle,
+				2*time.Second,
+				3,
+			)
+		}
+		return nil
+	}
+
+	return retry.WithGoogleRetry(ctx, s.googleRetryConfig, operation)
+}
+
+// Since reads the institution's sheet and returns the transactions that
+// occurred at or after since. Rows that cannot be read back, such as a
+// header, are skipped.
+func (s *TransactionStorage) Since(ctx context.Context, institution string, since time.Time) ([]*finance.Transaction, error) {
+	rows, err := s.readRows(ctx, institution)
+	if err != nil {
+		return nil, err
+	}
+
+	var txns []*finance.Transaction
+	for _, row := range rows {
+		txn, ok := s.parseRow(row)
+		if ok && !txn.OccurredAt.Before(since) {
+			txns = append(txns, txn)
+		}
+	}
+	return txns, nil
+}
+
+// UpdateStatus rewrites the status column of the row txn was read from. The
+// row is found by source message ID, or for rows without one by time,
+// account, amount and status.
+func (s *TransactionStorage) UpdateStatus(ctx context.Context, txn *finance.Transaction, status string) error {
+	sheet, ok := s.sheets[txn.Institution]
+	if !ok {
+		return fmt.Errorf("no sheet configured for institution %q", txn.Institution)
+	}
+
+	rows, err := s.readRows(ctx, txn.Institution)
+	if err != nil {
+		return err
+	}
+
+	rowNumber := 0
+	for i, row := range rows {
+		stored, ok := s.parseRow(row)
+		if ok && sameRow(stored, txn) {
+			rowNumber = i + 1
+			break
+		}
+	}
+	if rowNumber == 0 {
+		return fmt.Errorf("%s transaction at %s not found in sheet", txn.Institution, txn.OccurredAt.Format(time.DateTime))
+	}
+
+	operation := func() error {
+		vr := sheets.ValueRange{Values: [][]interface{}{{status}}}
+		_, err := s.service.Spreadsheets.Values.Update(
+			sheet.SheetID,
+			fmt.Sprintf("%s!%s%d", sheetcell.QuoteSheetName(sheet.SheetName), sheetcell.ColumnLetter(colStatus), rowNumber),
+			&vr,
+		).ValueInputOption("RAW").Context(ctx).Do()
+		if err != nil {
+			return errors.NewRetryableError(
+				fmt.Errorf("failed to update %s transaction status: %w", txn.Institution, err),
+				errors.ErrorTypeGoogle,
+				2*time.Second,
+				3,
+			)
+		}
+		return nil
+	}
+
+	return retry.WithGoogleRetry(ctx, s.googleRetryConfig, operation)
+}
+
+func (s *TransactionStorage) readRows(ctx context.Context, institution string) ([][]interface{}, error) {
+	sheet, ok := s.sheets[institution]
+	if !ok {
+		return nil, fmt.Errorf("no sheet configured for institution %q", institution)
+	}
+
+	var rows [][]interface{}
+	operation := func() error {
+		resp, err := s.service.Spreadsheets.Values.Get(
+			sheet.SheetID,
+			sheetcell.QuoteSheetName(sheet.SheetName)+"!A:"+sheetcell.ColumnLetter(columnCount-1),
+		).Context(ctx).Do()
+		if err != nil {
+			return errors.NewRetryableError(
+				fmt.Errorf("failed to read %s transactions from sheet: %w", institution, err),
+				errors.ErrorTypeGoogle,
+				2*time.Second,
+				3,
+			)
+		}
+		rows = resp.Values
+		return nil
+	}
+
+	if err := retry.WithGoogleRetry(ctx, s.googleRetryConfig, operation); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// parseRow reads back a row written by Save.
+func (s *TransactionStorage) parseRow(row []interface{}) (*finance.Transaction, bool) {
+	cell := func(i int) string { return sheetcell.String(row, i) }
+
+	occurredAt, ok := sheetcell.ParseTime(cell(colOccurredAt), s.location)
+	if !ok {
+		return nil, false
+	}
+
+	amount, err := models.ParseMoney(cell(colAmount), cell(colCurrency))
+	if err != nil {
+		return nil, false
+	}
+
+	txn := &finance.Transaction{
+		OccurredAt:   occurredAt,
+		Amount:       amount,
+		Status:       cell(colStatus),
+		Channel:      finance.TransactionType(cell(colChannel)),
+		Account:      cell(colAccount),
+		Counterparty: cell(colCounterparty),
+		Direction:    finance.Direction(cell(colDirection)),
+		Institution:  cell(colInstitution),
+		Merchant:     cell(colMerchant),
+		Category:     cell(colCategory),
+	}
+	if balance, err := models.ParseMoney(cell(colBalanceAfter), cell(colBalanceCurrency)); err == nil {
+		txn.BalanceAfter = balance
+	}
+	if converted, err := models.ParseMoney(cell(colConverted), cell(colConvertedCurrency)); err == nil {
+		txn.Converted = converted
+		txn.FXRate = cell(colFXRate)
+	}
+	if id, err := uuid.Parse(cell(colSourceMessageID)); err == nil {
+		txn.SourceMessageID = id
+	}
+	if raw := cell(colExtensions); raw != "" {
+		_ = json.Unmarshal([]byte(raw), &txn.Extensions)
+	}
+	txn.SetExtension(finance.ExtensionReference, cell(colReference))
+
+	return txn, true
+}
+
+func sameRow(stored, txn *finance.Transaction) bool {
+	if txn.SourceMessageID != uuid.Nil {
+		return stored.SourceMessageID == txn.SourceMessageID
+	}
+	return stored.SourceMessageID == uuid.Nil &&
+		stored.OccurredAt.Equal(txn.OccurredAt) &&
+		stored.Account == txn.Account &&
+		stored.Amount == txn.Amount &&
+		stored.Status == txn.Status
+}
@@ -0,0 +1,150 @@
+package finance_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/storage"
+	financeStorage "auto-finance/internal/storage/finance"
+	"auto-finance/internal/utils/retry"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+// fakeSheet is a Sheets API values endpoint backed by one sheet. It returns
+// the rows appended to it and applies single cell updates.
+type fakeSheet struct {
+	mu   sync.Mutex
+	rows [][]interface{}
+}
+
+func (f *fakeSheet) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, ":append"):
+		var vr sheets.ValueRange
+		if err := json.NewDecoder(r.Body).Decode(&vr); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.rows = append(f.rows, vr.Values...)
+		_ = json.NewEncoder(w).Encode(sheets.AppendValuesResponse{})
+	case r.Method == http.MethodPut:
+		var vr sheets.ValueRange
+		if err := json.NewDecoder(r.Body).Decode(&vr); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// The range is a single cell such as 'Sampath'!D1.
+		_, cell, _ := strings.Cut(r.URL.Path, "!")
+		digits := strings.IndexAny(cell, "0123456789")
+		column := int(cell[0] - 'A')
+		row, err := strconv.Atoi(cell[digits:])
+		if digits != 1 || err != nil || row > len(f.rows) || column >= len(f.rows[row-1]) {
+			http.Error(w, "bad range "+cell, http.StatusBadRequest)
+			return
+		}
+		f.rows[row-1][column] = vr.Values[0][0]
+		_ = json.NewEncoder(w).Encode(sheets.UpdateValuesResponse{})
+	default:
+		_ = json.NewEncoder(w).Encode(sheets.ValueRange{Values: f.rows})
+	}
+}
+
+func newTransactionStorage(t *testing.T, location *time.Location) storage.TransactionLedger {
+	t.Helper()
+	srv := httptest.NewServer(&fakeSheet{})
+	t.Cleanup(srv.Close)
+
+	service, err := sheets.NewService(context.Background(), option.WithEndpoint(srv.URL), option.WithoutAuthentication())
+	require.NoError(t, err)
+
+	return financeStorage.NewTransactionStorage(&financeStorage.TransactionConfig{
+		Service:           service,
+		Sheets:            map[string]financeStorage.Sheet{finance.InstitutionSampath: {SheetID: "sheet", SheetName: "Sampath"}},
+		Location:          location,
+		GoogleRetryConfig: &retry.GoogleRetryConfig{MaxAttempts: 1},
+	})
+}
+
+func TestTransactionStorage_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := newTransactionStorage(t, nil)
+
+	// Every field has a distinct value so that a shifted column shows up.
+	txn := &finance.Transaction{
+		Institution:     finance.InstitutionSampath,
+		Account:         "1234",
+		Direction:       finance.DirectionDebit,
+		Channel:         finance.TransactionTypeCard,
+		Amount:          models.NewMoney(2599, "USD"),
+		Counterparty:    "NETFLIX.COM",
+		BalanceAfter:    models.NewMoney(45012345, "LKR"),
+		Status:          finance.StatusAuthorized,
+		SourceMessageID: uuid.MustParse("0d9f6a47-3c52-4b8e-9a8e-2f4f1d6c7b21"),
+		OccurredAt:      time.Date(2025, time.October, 5, 14, 30, 0, 0, time.UTC),
+		Merchant:        "Netflix",
+		Category:        "Entertainment",
+		Converted:       models.NewMoney(783245, "LKR"),
+		FXRate:          "301.3640",
+		Extensions: map[string]string{
+			finance.ExtensionReference: "REF42",
+			"status_code":              "Auth",
+		},
+	}
+
+	require.NoError(t, store.Save(ctx, txn))
+
+	got, err := store.Since(ctx, finance.InstitutionSampath, txn.OccurredAt)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, txn, got[0])
+
+	require.NoError(t, store.UpdateStatus(ctx, got[0], finance.StatusReversed))
+
+	got, err = store.Since(ctx, finance.InstitutionSampath, txn.OccurredAt)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, finance.StatusReversed, got[0].Status)
+}
+
+func TestTransactionStorage_StoresTimesInLocation(t *testing.T) {
+	ctx := context.Background()
+	colombo := time.FixedZone("+0530", 5*60*60+30*60)
+	store := newTransactionStorage(t, colombo)
+
+	// Lambda hands over times in UTC; the row must still be written and
+	// matched in the storage location.
+	txn := &finance.Transaction{
+		Institution: finance.InstitutionSampath,
+		Account:     "1234",
+		Direction:   finance.DirectionDebit,
+		Channel:     finance.TransactionTypeCard,
+		Amount:      models.NewMoney(150000, "LKR"),
+		Status:      finance.StatusAuthorized,
+		OccurredAt:  time.Date(2025, time.October, 5, 20, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, store.Save(ctx, txn))
+
+	got, err := store.Since(ctx, finance.InstitutionSampath, txn.OccurredAt)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.True(t, txn.OccurredAt.Equal(got[0].OccurredAt), "got %s", got[0].OccurredAt)
+
+	require.NoError(t, store.UpdateStatus(ctx, txn, finance.StatusReversed))
+}
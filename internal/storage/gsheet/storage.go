@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"auto-finance/internal/models"
+	"auto-finance/internal/storage/sheetcell"
 
 	"github.com/google/uuid"
 	"google.golang.org/api/sheets/v4"
@@ -31,10 +32,12 @@ func New(config *Config) *gsheetStorage {
 	}
 }
 
+// Save appends the message. Cells are written RAW so a body starting with
+// "=" is not evaluated as a formula.
 func (s *gsheetStorage) Save(ctx context.Context, message *models.Message) error {
 	var vr sheets.ValueRange
-	vr.Values = append(vr.Values, []interface{}{message.ID.String(), message.From, message.Message, message.Time.Format("2006-01-02 15:04:05")})
-	_, err := s.service.Spreadsheets.Values.Append(s.sheetID, s.sheetName, &vr).ValueInputOption("USER_ENTERED").InsertDataOption("INSERT_ROWS").Context(ctx).Do()
+	vr.Values = append(vr.Values, []interface{}{message.ID.String(), message.From, message.Message, message.Time.Format("2006-01-02 15:04:05"), message.Outcome, message.Parser, message.Error})
+	_, err := s.service.Spreadsheets.Values.Append(s.sheetID, s.sheetName, &vr).ValueInputOption("RAW").InsertDataOption("INSERT_ROWS").Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("failed to append message to sheet: %w", err)
 	}
@@ -50,7 +53,7 @@ func (s *gsheetStorage) Read(ctx context.Context, id uuid.UUID) (*models.Message
 		return nil, nil // Not found
 	}
 
-	readRange := fmt.Sprintf("%s!A%d:D%d", s.sheetName, rowIndex, rowIndex)
+	readRange := fmt.Sprintf("%s!A%d:G%d", s.sheetName, rowIndex, rowIndex)
 	resp, err := s.service.Spreadsheets.Values.Get(s.sheetID, readRange).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to read message from sheet: %w", err)
@@ -75,11 +78,14 @@ func (s *gsheetStorage) Read(ctx context.Context, id uuid.UUID) (*models.Message
 		From:    row[1].(string),
 		Message: row[2].(string),
 		Time:    t,
+		Outcome: sheetcell.String(row, 4),
+		Parser:  sheetcell.String(row, 5),
+		Error:   sheetcell.String(row, 6),
 	}, nil
 }
 
 func (s *gsheetStorage) ReadAll(ctx context.Context, pageSize, pageNumber int) ([]*models.Message, error) {
-	readRange := fmt.Sprintf("%s!A%d:D%d", s.sheetName, pageNumber*pageSize+1, (pageNumber+1)*pageSize)
+	readRange := fmt.Sprintf("%s!A%d:G%d", s.sheetName, pageNumber*pageSize+1, (pageNumber+1)*pageSize)
 	resp, err := s.service.Spreadsheets.Values.Get(s.sheetID, readRange).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to read messages from sheet: %w", err)
@@ -103,6 +109,9 @@ func (s *gsheetStorage) ReadAll(ctx context.Context, pageSize, pageNumber int) (
 			From:    row[1].(string),
 			Message: row[2].(string),
 			Time:    t,
+			Outcome: sheetcell.String(row, 4),
+			Parser:  sheetcell.String(row, 5),
+			Error:   sheetcell.String(row, 6),
 		})
 	}
 	return messages, nil
@@ -7,6 +7,7 @@ import (
 
 	"auto-finance/internal/errors"
 	"auto-finance/internal/models"
+	"auto-finance/internal/storage/sheetcell"
 	"auto-finance/internal/utils/retry"
 
 	"github.com/google/uuid"
@@ -44,7 +45,9 @@ func NewEnhanced(config *EnhancedConfig) *EnhancedGSheetStorage {
 	}
 }
 
-// Save saves a message to Google Sheets with retry logic
+// Save saves a message to Google Sheets with retry logic. Cells are written
+// RAW since message bodies are untrusted text; a body starting with "="
+// must not be evaluated as a formula.
 func (s *EnhancedGSheetStorage) Save(ctx context.Context, message *models.Message) error {
 	operation := func() error {
 		var vr sheets.ValueRange
@@ -53,13 +56,16 @@ func (s *EnhancedGSheetStorage) Save(ctx context.Context, message *models.Messag
 			message.From,
 			message.Message,
 			message.Time.Format("2006-01-02 15:04:05"),
+			message.Outcome,
+			message.Parser,
+			message.Error,
 		})
 
 		_, err := s.service.Spreadsheets.Values.Append(
 			s.sheetID,
 			s.sheetName,
 			&vr,
-		).ValueInputOption("USER_ENTERED").InsertDataOption("INSERT_ROWS").Context(ctx).Do()
+		).ValueInputOption("RAW").InsertDataOption("INSERT_ROWS").Context(ctx).Do()
 		if err != nil {
 			return errors.NewRetryableError(
 				fmt.Errorf("failed to append message to sheet: %w", err),
@@ -88,7 +94,7 @@ func (s *EnhancedGSheetStorage) Read(ctx context.Context, id uuid.UUID) (*models
 			return nil // Not found, not an error
 		}
 
-		readRange := fmt.Sprintf("%s!A%d:D%d", s.sheetName, rowIndex, rowIndex)
+		readRange := fmt.Sprintf("%s!A%d:G%d", s.sheetName, rowIndex, rowIndex)
 		resp, getErr := s.service.Spreadsheets.Values.Get(s.sheetID, readRange).Do()
 		if getErr != nil {
 			return errors.NewRetryableError(
@@ -119,6 +125,9 @@ func (s *EnhancedGSheetStorage) Read(ctx context.Context, id uuid.UUID) (*models
 			From:    row[1].(string),
 			Message: row[2].(string),
 			Time:    t,
+			Outcome: sheetcell.String(row, 4),
+			Parser:  sheetcell.String(row, 5),
+			Error:   sheetcell.String(row, 6),
 		}
 		return nil
 	}
@@ -136,7 +145,7 @@ func (s *EnhancedGSheetStorage) ReadAll(ctx context.Context, pageSize, pageNumbe
 	var messages []*models.Message
 
 	operation := func() error {
-		readRange := fmt.Sprintf("%s!A%d:D%d", s.sheetName, pageNumber*pageSize+1, (pageNumber+1)*pageSize)
+		readRange := fmt.Sprintf("%s!A%d:G%d", s.sheetName, pageNumber*pageSize+1, (pageNumber+1)*pageSize)
 		resp, err := s.service.Spreadsheets.Values.Get(s.sheetID, readRange).Do()
 		if err != nil {
 			return errors.NewRetryableError(
@@ -168,6 +177,9 @@ func (s *EnhancedGSheetStorage) ReadAll(ctx context.Context, pageSize, pageNumbe
 				From:    row[1].(string),
 				Message: row[2].(string),
 				Time:    t,
+				Outcome: sheetcell.String(row, 4),
+				Parser:  sheetcell.String(row, 5),
+				Error:   sheetcell.String(row, 6),
 			})
 		}
 		return nil
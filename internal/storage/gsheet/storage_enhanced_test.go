@@ -0,0 +1,55 @@
+package gsheet_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auto-finance/internal/models"
+	"auto-finance/internal/storage/gsheet"
+	"auto-finance/internal/utils/retry"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+func TestEnhancedGSheetStorage_SaveWritesRawValues(t *testing.T) {
+	var inputOption string
+	var values [][]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inputOption = r.URL.Query().Get("valueInputOption")
+		var vr sheets.ValueRange
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&vr))
+		values = vr.Values
+		_ = json.NewEncoder(w).Encode(sheets.AppendValuesResponse{})
+	}))
+	defer srv.Close()
+
+	service, err := sheets.NewService(context.Background(), option.WithEndpoint(srv.URL), option.WithoutAuthentication())
+	require.NoError(t, err)
+	store := gsheet.NewEnhanced(&gsheet.EnhancedConfig{
+		Service:           service,
+		SheetID:           "sheet",
+		SheetName:         "Raw Messages",
+		GoogleRetryConfig: retry.GoogleRetryConfig{MaxAttempts: 1},
+	})
+
+	body := `=HYPERLINK("https://example.com","click")`
+	require.NoError(t, store.Save(context.Background(), &models.Message{
+		ID:      uuid.New(),
+		From:    "UNKNOWN",
+		Message: body,
+		Time:    time.Date(2025, time.November, 7, 18, 42, 10, 0, time.UTC),
+		Outcome: models.OutcomeRejected,
+	}))
+
+	assert.Equal(t, "RAW", inputOption)
+	require.Len(t, values, 1)
+	assert.Equal(t, body, values[0][2])
+}
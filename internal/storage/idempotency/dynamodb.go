@@ -0,0 +1,136 @@
+package idempotency
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"auto-finance/internal/errors"
+	"auto-finance/internal/storage"
+	"auto-finance/internal/utils/retry"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	keyAttribute       = "pk"
+	expiresAtAttribute = "expires_at"
+)
+
+// DynamoDBClient defines the DynamoDB operations used by this package
+type DynamoDBClient interface {
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+}
+
+// DynamoDBConfig contains configuration for DynamoDB idempotency storage
+type DynamoDBConfig struct {
+	Client      DynamoDBClient
+	Table       string
+	RetryConfig *retry.AWSRetryConfig
+}
+
+// DynamoDBStorage stores idempotency keys in a DynamoDB table keyed by "pk".
+// The "expires_at" attribute holds a Unix timestamp and is intended to be the
+// table's TTL attribute.
+type DynamoDBStorage struct {
+	client      DynamoDBClient
+	table       string
+	retryConfig retry.AWSRetryConfig
+	now         func() time.Time
+}
+
+// NewDynamoDB creates a DynamoDB backed idempotency storage
+func NewDynamoDB(config *DynamoDBConfig) storage.IdempotencyStorage {
+	retryConfig := retry.DefaultAWSRetryConfig()
+	if config.RetryConfig != nil {
+		retryConfig = *config.RetryConfig
+	}
+
+	return &DynamoDBStorage{
+		client:      config.Client,
+		table:       config.Table,
+		retryConfig: retryConfig,
+		now:         time.Now,
+	}
+}
+
+// Reserve conditionally writes key, succeeding only when it is absent or its
+// previous reservation has expired. DynamoDB TTL deletion is lazy, so the
+// expiry is checked in the condition as well.
+func (s *DynamoDBStorage) Reserve(ctx context.Context, key string, expiresAt time.Time) (bool, error) {
+	reserved := false
+
+	operation := func() error {
+		_, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName: aws.String(s.table),
+			Item: map[string]types.AttributeValue{
+				keyAttribute:       &types.AttributeValueMemberS{Value: key},
+				expiresAtAttribute: &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt.Unix(), 10)},
+			},
+			ConditionExpression: aws.String("attribute_not_exists(#pk) OR #exp < :now"),
+			ExpressionAttributeNames: map[string]string{
+				"#pk":  keyAttribute,
+				"#exp": expiresAtAttribute,
+			},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":now": &types.AttributeValueMemberN{Value: strconv.FormatInt(s.now().Unix(), 10)},
+			},
+		})
+		if err != nil {
+			var conditionErr *types.ConditionalCheckFailedException
+			if stdErrors.As(err, &conditionErr) {
+				reserved = false
+				return nil
+			}
+			if retry.IsAWSErrorRetryable(err) {
+				return errors.NewRetryableError(
+					fmt.Errorf("failed to reserve idempotency key in %s: %w", s.table, err),
+					errors.ErrorTypeAWS,
+					2*time.Second,
+					3,
+				)
+			}
+			return fmt.Errorf("failed to reserve idempotency key in %s: %w", s.table, err)
+		}
+
+		reserved = true
+		return nil
+	}
+
+	if err := retry.WithAWSRetry(ctx, s.retryConfig, operation); err != nil {
+		return false, err
+	}
+
+	return reserved, nil
+}
+
+// Release deletes key
+func (s *DynamoDBStorage) Release(ctx context.Context, key string) error {
+	operation := func() error {
+		_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+			TableName: aws.String(s.table),
+			Key: map[string]types.AttributeValue{
+				keyAttribute: &types.AttributeValueMemberS{Value: key},
+			},
+		})
+		if err != nil {
+			if retry.IsAWSErrorRetryable(err) {
+				return errors.NewRetryableError(
+					fmt.Errorf("failed to release idempotency key in %s: %w", s.table, err),
+					errors.ErrorTypeAWS,
+					2*time.Second,
+					3,
+				)
+			}
+			return fmt.Errorf("failed to release idempotency key in %s: %w", s.table, err)
+		}
+		return nil
+	}
+
+	return retry.WithAWSRetry(ctx, s.retryConfig, operation)
+}
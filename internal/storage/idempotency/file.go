@@ -0,0 +1,234 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"auto-finance/internal/storage"
+	"auto-finance/internal/storage/jsonfile"
+)
+
+// FileStorage persists idempotency keys to a file so that local runs and
+// repeated imports keep their history between processes.
+//
+// The file is an append-only log with one JSON entry per line. Keys are
+// kept in memory and only lines appended since the last call are read, so
+// an import costs one line of I/O per message. The log is compacted, dropping
+// expired and released keys, the first time it is read.
+type FileStorage struct {
+	mu   sync.Mutex
+	path string
+	now  func() time.Time
+
+	keys map[string]time.Time
+	// file and offset identify the log keys was read from and how much of
+	// it has been applied. Compaction replaces the file, which forces a full
+	// reload in other instances.
+	file   os.FileInfo
+	offset int64
+}
+
+// entry is one line of the log. A zero ExpiresAt releases the key.
+type entry struct {
+	Key       string    `json:"key"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// NewFile creates a file backed idempotency storage at path
+func NewFile(path string) storage.IdempotencyStorage {
+	return &FileStorage{
+		path: path,
+		now:  time.Now,
+	}
+}
+
+// Reserve records key unless an unexpired reservation already exists
+func (s *FileStorage) Reserve(_ context.Context, key string, expiresAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.sync(); err != nil {
+		return false, err
+	}
+
+	if existing, ok := s.keys[key]; ok && existing.After(s.now()) {
+		return false, nil
+	}
+
+	if err := s.append(entry{Key: key, ExpiresAt: expiresAt}); err != nil {
+		return false, err
+	}
+	s.keys[key] = expiresAt
+	return true, nil
+}
+
+// Release removes key
+func (s *FileStorage) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.sync(); err != nil {
+		return err
+	}
+	if _, ok := s.keys[key]; !ok {
+		return nil
+	}
+
+	if err := s.append(entry{Key: key}); err != nil {
+		return err
+	}
+	delete(s.keys, key)
+	return nil
+}
+
+// sync applies the lines written since the last call, by this or another
+// instance. The whole file is read the first time and whenever it has been
+// replaced.
+func (s *FileStorage) sync() error {
+	f, err := os.Open(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		s.keys, s.file, s.offset = make(map[string]time.Time), nil, 0
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open idempotency file %s: %w", s.path, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat idempotency file %s: %w", s.path, err)
+	}
+
+	if s.keys == nil || s.file == nil || !os.SameFile(s.file, info) || info.Size() < s.offset {
+		return s.reload(f, info)
+	}
+	if info.Size() == s.offset {
+		return nil
+	}
+
+	if _, err := f.Seek(s.offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read idempotency file %s: %w", s.path, err)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return fmt.Errorf("failed to read idempotency file %s: %w", s.path, err)
+	}
+
+	n, _, err := apply(s.keys, data)
+	if err != nil {
+		return fmt.Errorf("failed to decode idempotency file %s: %w", s.path, err)
+	}
+	s.offset += int64(n)
+	return nil
+}
+
+// reload reads the whole file and compacts it when it holds more lines than
+// live keys. Files written by earlier versions as a single JSON object are
+// converted to the log format.
+func (s *FileStorage) reload(f *os.File, info os.FileInfo) error {
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return fmt.Errorf("failed to read idempotency file %s: %w", s.path, err)
+	}
+
+	keys := make(map[string]time.Time)
+	var n, lines int
+	if legacy := (map[string]time.Time{}); json.Unmarshal(data, &legacy) == nil {
+		keys, lines = legacy, -1
+	} else if n, lines, err = apply(keys, data); err != nil {
+		return fmt.Errorf("failed to decode idempotency file %s: %w", s.path, err)
+	}
+
+	now := s.now()
+	for key, expiresAt := range keys {
+		if !expiresAt.After(now) {
+			delete(keys, key)
+		}
+	}
+
+	s.keys, s.file, s.offset = keys, info, int64(n)
+	if lines < 0 || lines > len(keys) {
+		return s.compact()
+	}
+	return nil
+}
+
+// compact replaces the log with one line per live key.
+func (s *FileStorage) compact() error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for key, expiresAt := range s.keys {
+		if err := enc.Encode(entry{Key: key, ExpiresAt: expiresAt}); err != nil {
+			return fmt.Errorf("failed to encode idempotency keys: %w", err)
+		}
+	}
+
+	if err := jsonfile.WriteAtomic(s.path, buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to compact idempotency file: %w", err)
+	}
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat idempotency file %s: %w", s.path, err)
+	}
+	s.file, s.offset = info, info.Size()
+	return nil
+}
+
+// append writes e to the end of the log. The line is read back by the next
+// sync like any other instance's, which is harmless since entries can be
+// applied again.
+func (s *FileStorage) append(e entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode idempotency key: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create idempotency directory: %w", err)
+	}
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open idempotency file %s: %w", s.path, err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write idempotency file %s: %w", s.path, err)
+	}
+	return f.Close()
+}
+
+// apply applies the complete lines of data to keys. It returns the bytes
+// consumed, leaving a partly written last line for the next call, and the
+// number of lines applied.
+func apply(keys map[string]time.Time, data []byte) (int, int, error) {
+	n := bytes.LastIndexByte(data, '\n') + 1
+	lines := 0
+	for _, line := range bytes.Split(data[:n], []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var e entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return 0, 0, err
+		}
+		if e.ExpiresAt.IsZero() {
+			delete(keys, e.Key)
+		} else {
+			keys[e.Key] = e.ExpiresAt
+		}
+		lines++
+	}
+	return n, lines, nil
+}
@@ -0,0 +1,88 @@
+package idempotency_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"auto-finance/internal/storage/idempotency"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStorage_PersistsAcrossInstances(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state", "keys.json")
+
+	first := idempotency.NewFile(path)
+	ok, err := first.Reserve(ctx, "k1", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	expired, err := first.Reserve(ctx, "k2", time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.True(t, expired)
+
+	second := idempotency.NewFile(path)
+	ok, err = second.Reserve(ctx, "k1", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, ok, "k1 is still held")
+
+	ok, err = second.Reserve(ctx, "k2", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, ok, "expired keys can be reserved again")
+
+	require.NoError(t, second.Release(ctx, "k1"))
+	ok, err = first.Reserve(ctx, "k1", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestFileStorage_AppendsAndCompacts(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "keys.json")
+	lines := func() int {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return strings.Count(string(data), "\n")
+	}
+
+	s := idempotency.NewFile(path)
+	for _, key := range []string{"k1", "k2", "k3"} {
+		ok, err := s.Reserve(ctx, key, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+	require.NoError(t, s.Release(ctx, "k2"))
+	assert.Equal(t, 4, lines(), "every change appends one line")
+
+	reopened := idempotency.NewFile(path)
+	ok, err := reopened.Reserve(ctx, "k3", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 2, lines(), "released keys are dropped when the file is first read")
+}
+
+func TestFileStorage_ReadsLegacyFormat(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "keys.json")
+	expiresAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339Nano)
+	require.NoError(t, os.WriteFile(path, []byte(`{"k1":"`+expiresAt+`"}`), 0o600))
+
+	s := idempotency.NewFile(path)
+	ok, err := s.Reserve(ctx, "k1", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = s.Reserve(ctx, "k2", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"key":"k1"`, "the file is converted to the log format")
+}
@@ -0,0 +1,47 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"auto-finance/internal/storage"
+)
+
+// MemoryStorage keeps idempotency keys in process memory. It is meant for
+// tests and single-process runs; keys are lost on restart.
+type MemoryStorage struct {
+	mu   sync.Mutex
+	keys map[string]time.Time
+	now  func() time.Time
+}
+
+// NewMemory creates an in-memory idempotency storage
+func NewMemory() storage.IdempotencyStorage {
+	return &MemoryStorage{
+		keys: make(map[string]time.Time),
+		now:  time.Now,
+	}
+}
+
+// Reserve records key unless an unexpired reservation already exists
+func (s *MemoryStorage) Reserve(_ context.Context, key string, expiresAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.keys[key]; ok && existing.After(s.now()) {
+		return false, nil
+	}
+
+	s.keys[key] = expiresAt
+	return true, nil
+}
+
+// Release removes key
+func (s *MemoryStorage) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.keys, key)
+	return nil
+}
@@ -0,0 +1,36 @@
+package jsonfile_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"auto-finance/internal/storage/jsonfile"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "totals.json")
+
+	totals := map[string]int{"kept": 1}
+	require.NoError(t, jsonfile.Load(path, &totals), "a missing file is not an error")
+	assert.Equal(t, map[string]int{"kept": 1}, totals)
+
+	require.NoError(t, jsonfile.Save(path, map[string]int{"a": 2}))
+	_, err := os.Stat(path + ".tmp")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	var got map[string]int
+	require.NoError(t, jsonfile.Load(path, &got))
+	assert.Equal(t, map[string]int{"a": 2}, got)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "totals.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+
+	var got map[string]int
+	assert.Error(t, jsonfile.Load(path, &got))
+}
@@ -0,0 +1,52 @@
+package rawmessage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"auto-finance/internal/models"
+	"auto-finance/internal/storage"
+)
+
+// FileStorage appends raw messages to a JSON Lines file. It is the local
+// alternative to the Google Sheets audit log.
+type FileStorage struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFile creates a raw message storage that appends to path
+func NewFile(path string) storage.MessageStorage[*models.Message] {
+	return &FileStorage{path: path}
+}
+
+// Save appends message as a single JSON line
+func (s *FileStorage) Save(_ context.Context, message *models.Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode raw message: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create raw message directory: %w", err)
+	}
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open raw message file %s: %w", s.path, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to append raw message to %s: %w", s.path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+// Package sheetcell reads and addresses the cells of Google Sheets rows
+// shared by the sheet backed storages.
+package sheetcell
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"auto-finance/internal/models"
+)
+
+// String returns cell i of a row read back from a sheet, empty when the row
+// is shorter. Older rows often lack the columns added later.
+func String(row []interface{}, i int) string {
+	if i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprint(row[i]))
+}
+
+// ParseTime reads back a time written as time.DateTime or RFC 3339 text.
+// Sheets may show entered dates reformatted, so a few other layouts are
+// accepted. Times without a zone are read in location, and the zero time
+// reads back as the zero time.
+func ParseTime(s string, location *time.Location) (time.Time, bool) {
+	for _, layout := range []string{time.DateTime, time.RFC3339, "2006-01-02 15:04", time.DateOnly, "1/2/2006 15:04:05", "1/2/2006"} {
+		if t, err := time.ParseInLocation(layout, s, location); err == nil {
+			if t.Year() <= 1 {
+				return time.Time{}, true
+			}
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ColumnLetter returns the A1 notation letters of the zero-based column i.
+func ColumnLetter(i int) string {
+	letters := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		letters = string(rune('A'+(i-1)%26)) + letters
+	}
+	return letters
+}
+
+// QuoteSheetName quotes a sheet name for use in an A1 range.
+func QuoteSheetName(name string) string {
+	return "'" + strings.ReplaceAll(name, "'", "''") + "'"
+}
+
+// OptionalAmount leaves the cell empty for amounts the SMS did not carry, so
+// a missing amount is not read back as a zero one.
+func OptionalAmount(m models.Money) string {
+	if m.IsZero() {
+		return ""
+	}
+	return m.Decimal()
+}
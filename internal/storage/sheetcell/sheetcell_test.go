@@ -0,0 +1,50 @@
+package sheetcell_test
+
+import (
+	"testing"
+	"time"
+
+	"auto-finance/internal/storage/sheetcell"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestString(t *testing.T) {
+	row := []interface{}{" a ", 12}
+	assert.Equal(t, "a", sheetcell.String(row, 0))
+	assert.Equal(t, "12", sheetcell.String(row, 1))
+	assert.Equal(t, "", sheetcell.String(row, 2))
+}
+
+func TestParseTime(t *testing.T) {
+	colombo := time.FixedZone("+0530", 5*60*60+30*60)
+	tests := []struct {
+		in     string
+		want   time.Time
+		wantOK bool
+	}{
+		{in: "2025-10-05 14:30:00", want: time.Date(2025, 10, 5, 14, 30, 0, 0, colombo), wantOK: true},
+		{in: "2025-10-05T09:00:00Z", want: time.Date(2025, 10, 5, 9, 0, 0, 0, time.UTC), wantOK: true},
+		{in: "10/5/2025", want: time.Date(2025, 10, 5, 0, 0, 0, 0, colombo), wantOK: true},
+		{in: "0001-01-01T00:00:00Z", want: time.Time{}, wantOK: true},
+		{in: "Date", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, ok := sheetcell.ParseTime(tt.in, colombo)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.True(t, tt.want.Equal(got), "got %s", got)
+		})
+	}
+}
+
+func TestColumnLetter(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 18: "S", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		assert.Equal(t, want, sheetcell.ColumnLetter(i))
+	}
+}
+
+func TestQuoteSheetName(t *testing.T) {
+	assert.Equal(t, "'Tom''s Bills'", sheetcell.QuoteSheetName("Tom's Bills"))
+}
@@ -2,6 +2,13 @@ package storage
 
 import (
 	"context"
+	"time"
+
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/ebill"
+	"auto-finance/internal/models/finance"
+
+	"github.com/google/uuid"
 )
 
 type MessageStorage[T any] interface {
@@ -11,6 +18,83 @@ type MessageStorage[T any] interface {
 	// Delete(ctx context.Context, id uuid.UUID) error
 }
 
+// TransactionLedger is a transaction storage that can also look up recent
+// transactions and amend their status, so that card reversals can be linked
+// to the authorizations they cancel.
+type TransactionLedger interface {
+	MessageStorage[*finance.Transaction]
+	// Since returns the institution's transactions that occurred at or after
+	// since.
+	Since(ctx context.Context, institution string, since time.Time) ([]*finance.Transaction, error)
+	// UpdateStatus sets the stored status of txn, a transaction returned by
+	// Since.
+	UpdateStatus(ctx context.Context, txn *finance.Transaction, status string) error
+}
+
+// BillLedger is an electricity bill storage that can also read back the
+// saved bills for analysis.
+type BillLedger interface {
+	MessageStorage[*ebill.ElectricityBill]
+	// List returns the saved bills in the order they were saved.
+	List(ctx context.Context) ([]*ebill.ElectricityBill, error)
+}
+
+// SolarSummaryStorage keeps the latest solar analysis.
+type SolarSummaryStorage interface {
+	// Replace stores months as the whole summary.
+	Replace(ctx context.Context, months []*ebill.SolarMonth) error
+}
+
+// BalanceStorage keeps the expected balance of each account between
+// transactions.
+type BalanceStorage interface {
+	// Get returns the balance for key and whether there is one.
+	Get(ctx context.Context, key string) (finance.Balance, bool, error)
+	Put(ctx context.Context, key string, balance finance.Balance) error
+	Delete(ctx context.Context, key string) error
+}
+
+// DiscrepancyStorage records balance discrepancies for review.
+type DiscrepancyStorage interface {
+	MessageStorage[*finance.Discrepancy]
+	// List returns the recorded discrepancies, oldest first.
+	List(ctx context.Context) ([]*finance.Discrepancy, error)
+}
+
+// SubscriptionStorage keeps the list of detected subscriptions.
+type SubscriptionStorage interface {
+	List(ctx context.Context) ([]*finance.Subscription, error)
+	// Replace stores subscriptions as the whole list.
+	Replace(ctx context.Context, subscriptions []*finance.Subscription) error
+}
+
 type ConfigStorage interface {
 	GetConfig(ctx context.Context, key string) ([]byte, error)
 }
+
+// IdempotencyStorage records keys of messages that have already been accepted.
+type IdempotencyStorage interface {
+	// Reserve records key until expiresAt and reports whether it was newly
+	// recorded. false means the key is already held by an earlier message.
+	Reserve(ctx context.Context, key string, expiresAt time.Time) (bool, error)
+	// Release forgets key so that a failed message can be retried.
+	Release(ctx context.Context, key string) error
+}
+
+// DeadLetterStorage holds messages that failed processing until they are
+// replayed.
+type DeadLetterStorage interface {
+	// Save stores letter, replacing any entry with the same MessageID.
+	Save(ctx context.Context, letter *models.DeadLetter) error
+	// List returns the stored entries, oldest first.
+	List(ctx context.Context) ([]*models.DeadLetter, error)
+	// Delete removes the entry for messageID.
+	Delete(ctx context.Context, messageID uuid.UUID) error
+}
+
+// BudgetStorage keeps running spending totals for budget tracking.
+type BudgetStorage interface {
+	// Add adds amount to the total for key and returns the totals before and
+	// after the addition. A missing key starts from zero.
+	Add(ctx context.Context, key string, amount models.Money) (before, after models.Money, err error)
+}
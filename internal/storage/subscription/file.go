@@ -0,0 +1,45 @@
+package subscription
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/storage"
+	"auto-finance/internal/storage/jsonfile"
+)
+
+// FileStorage persists the subscription list to a JSON file.
+type FileStorage struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFile creates a file backed subscription storage at path
+func NewFile(path string) storage.SubscriptionStorage {
+	return &FileStorage{path: path}
+}
+
+// List returns the stored subscriptions
+func (s *FileStorage) List(_ context.Context) ([]*finance.Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var subscriptions []*finance.Subscription
+	if err := jsonfile.Load(s.path, &subscriptions); err != nil {
+		return nil, fmt.Errorf("failed to load subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+// Replace stores the list in place of the previous one
+func (s *FileStorage) Replace(_ context.Context, subscriptions []*finance.Subscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := jsonfile.Save(s.path, subscriptions); err != nil {
+		return fmt.Errorf("failed to store subscriptions: %w", err)
+	}
+	return nil
+}
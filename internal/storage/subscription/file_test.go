@@ -0,0 +1,42 @@
+package subscription_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"auto-finance/internal/models"
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/storage/subscription"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStorage_PersistsAcrossInstances(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state", "subscriptions.json")
+
+	first := subscription.NewFile(path)
+	subs, err := first.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, subs)
+
+	want := &finance.Subscription{
+		Key:           "Sampath|1234|MASKED STREAM|USD",
+		Merchant:      "MASKED STREAM",
+		Cadence:       finance.CadenceMonthly,
+		Amount:        models.NewMoney(1099, "USD"),
+		Charges:       3,
+		LastChargedAt: time.Date(2025, time.November, 7, 0, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, first.Replace(ctx, []*finance.Subscription{want}))
+
+	subs, err = subscription.NewFile(path).List(ctx)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Equal(t, want.Key, subs[0].Key)
+	assert.Equal(t, want.Amount, subs[0].Amount)
+	assert.True(t, want.LastChargedAt.Equal(subs[0].LastChargedAt))
+}
@@ -0,0 +1,49 @@
+package subscription
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"auto-finance/internal/models/finance"
+	"auto-finance/internal/storage"
+)
+
+// MemoryStorage keeps the subscription list in process memory. It is meant
+// for tests and single-process runs; the list is lost on restart.
+type MemoryStorage struct {
+	mu            sync.Mutex
+	subscriptions []*finance.Subscription
+}
+
+// NewMemory creates an in-memory subscription storage
+func NewMemory() storage.SubscriptionStorage {
+	return &MemoryStorage{}
+}
+
+// List returns the stored subscriptions
+func (s *MemoryStorage) List(_ context.Context) ([]*finance.Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return clone(s.subscriptions), nil
+}
+
+// Replace stores subscriptions as the whole list
+func (s *MemoryStorage) Replace(_ context.Context, subscriptions []*finance.Subscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.subscriptions = clone(subscriptions)
+	return nil
+}
+
+// clone copies the entries so callers cannot change the stored list.
+func clone(subscriptions []*finance.Subscription) []*finance.Subscription {
+	out := slices.Clone(subscriptions)
+	for i, sub := range out {
+		c := *sub
+		out[i] = &c
+	}
+	return out
+}
@@ -0,0 +1,99 @@
+package waterbill
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"auto-finance/internal/errors"
+	"auto-finance/internal/models/waterbill"
+	"auto-finance/internal/storage"
+	"auto-finance/internal/utils/retry"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// NWSDBStorage provides water bill storage with retry capabilities.
+type NWSDBStorage struct {
+	service           *sheets.Service
+	sheetID           string
+	sheetName         string
+	googleRetryConfig retry.GoogleRetryConfig
+}
+
+// Config contains configuration for NWSDB bill storage
+type Config struct {
+	Service           *sheets.Service
+	SheetID           string
+	SheetName         string
+	GoogleRetryConfig *retry.GoogleRetryConfig
+}
+
+// New creates a new NWSDB bill storage with retry capabilities
+func New(config *Config) storage.MessageStorage[*waterbill.WaterBill] {
+	retryConfig := retry.DefaultGoogleRetryConfig()
+	if config.GoogleRetryConfig != nil {
+		retryConfig = *config.GoogleRetryConfig
+	}
+
+	return &NWSDBStorage{
+		service:           config.Service,
+		sheetID:           config.SheetID,
+		sheetName:         config.SheetName,
+		googleRetryConfig: retryConfig,
+	}
+}
+
+// Save appends a water bill to Google Sheets with retry logic.
+// Unrecognized bill lines are written as a JSON object, followed by the
+// provider.
+func (s *NWSDBStorage) Save(ctx context.Context, bill *waterbill.WaterBill) error {
+	extras := ""
+	if len(bill.Extras) > 0 {
+		data, err := json.Marshal(bill.Extras)
+		if err != nil {
+			return fmt.Errorf("failed to encode bill extras: %w", err)
+		}
+		extras = string(data)
+	}
+
+	operation := func() error {
+		var vr sheets.ValueRange
+		vr.Values = append(vr.Values, []interface{}{
+			bill.AccountNumber,
+			bill.Category,
+			bill.AccountName,
+			bill.ReadOn,
+			bill.PreviousReading,
+			bill.CurrentReading,
+			bill.Units,
+			bill.Charges.Decimal(),
+			bill.ServiceCharge.Decimal(),
+			bill.Arrears.Decimal(),
+			bill.TotalPayable.Decimal(),
+			bill.LastPaymentAmount.Decimal(),
+			bill.LastPaymentDate,
+			bill.DueDate,
+			extras,
+			bill.Provider,
+		})
+
+		_, err := s.service.Spreadsheets.Values.Append(
+			s.sheetID,
+			s.sheetName,
+			&vr,
+		).ValueInputOption("USER_ENTERED").InsertDataOption("INSERT_ROWS").Context(ctx).Do()
+		if err != nil {
+			return errors.NewRetryableError(
+				fmt.Errorf("failed to append water bill to sheet: %w", err),
+				errors.ErrorTypeGoogle,
+				2*time.Second,
+				3,
+			)
+		}
+		return nil
+	}
+
+	return retry.WithGoogleRetry(ctx, s.googleRetryConfig, operation)
+}
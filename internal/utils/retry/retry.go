@@ -100,9 +100,10 @@ func IsGoogleErrorRetryable(err error) bool {
 // WithAWSRetry executes an AWS operation with retry logic
 func WithAWSRetry(ctx context.Context, config AWSRetryConfig, operation func() error) error {
 	backoff := config.InitialBackoff
+	var err error
 
 	for attempt := 1; attempt <= config.MaxAttempts; attempt++ {
-		err := operation()
+		err = operation()
 		if err == nil {
 			return nil
 		}
@@ -121,15 +122,16 @@ func WithAWSRetry(ctx context.Context, config AWSRetryConfig, operation func() e
 		}
 	}
 
-	return fmt.Errorf("aws operation failed after %d attempts", config.MaxAttempts)
+	return fmt.Errorf("aws operation failed after %d attempts: %w", config.MaxAttempts, err)
 }
 
 // WithGoogleRetry executes a Google API operation with retry logic
 func WithGoogleRetry(ctx context.Context, config GoogleRetryConfig, operation func() error) error {
 	backoff := config.InitialBackoff
+	var err error
 
 	for attempt := 1; attempt <= config.MaxAttempts; attempt++ {
-		err := operation()
+		err = operation()
 		if err == nil {
 			return nil
 		}
@@ -148,7 +150,7 @@ func WithGoogleRetry(ctx context.Context, config GoogleRetryConfig, operation fu
 		}
 	}
 
-	return fmt.Errorf("google api operation failed after %d attempts", config.MaxAttempts)
+	return fmt.Errorf("google api operation failed after %d attempts: %w", config.MaxAttempts, err)
 }
 
 // S3GetObjectWithRetry wraps S3 GetObject with retry logic